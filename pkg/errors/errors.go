@@ -4,6 +4,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+
+	"github.com/rs/zerolog"
 )
 
 // Error codes matching gRPC/Flight SQL conventions
@@ -165,3 +167,60 @@ func GetMessage(err error) string {
 	}
 	return err.Error()
 }
+
+// contextError attaches key/value context to an error without changing its
+// message, code or chain.
+type contextError struct {
+	err     error
+	context map[string]string
+}
+
+// Error implements the error interface.
+func (e *contextError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// WithContext attaches key/value pairs such as the query, column or row
+// index to err. They can be retrieved with Context, also after further
+// wrapping. It returns nil if err is nil.
+func WithContext(err error, context map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(context))
+	for k, v := range context {
+		copied[k] = v
+	}
+	return &contextError{err: err, context: copied}
+}
+
+// Context returns the context attached to err and any error it wraps. When
+// a key is set at several levels the outermost value wins.
+func Context(err error) map[string]string {
+	context := make(map[string]string)
+	for ; err != nil; err = errors.Unwrap(err) {
+		if ce, ok := err.(*contextError); ok {
+			for k, v := range ce.context {
+				if _, exists := context[k]; !exists {
+					context[k] = v
+				}
+			}
+		}
+	}
+	return context
+}
+
+// LogFields adds err and the context attached to it to ev, so that the log
+// line carries the same query, column and row details as the error.
+func LogFields(ev *zerolog.Event, err error) *zerolog.Event {
+	ev = ev.Err(err)
+	for k, v := range Context(err) {
+		ev = ev.Str(k, v)
+	}
+	return ev
+}
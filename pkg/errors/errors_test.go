@@ -1,11 +1,15 @@
 package errors
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFlightError_Error(t *testing.T) {
@@ -276,3 +280,44 @@ func TestCommonErrors(t *testing.T) {
 	assert.Equal(t, CodeResourceExhausted, ErrResourceExhausted.Code)
 	assert.Equal(t, CodeUnimplemented, ErrNotImplemented.Code)
 }
+
+func TestWithContext(t *testing.T) {
+	assert.Nil(t, WithContext(nil, map[string]string{"query": "SELECT 1"}))
+
+	base := New(CodeQueryFailed, "failed to scan row")
+	err := WithContext(base, map[string]string{"column": "amount", "row": "7"})
+	wrapped := Wrap(err, CodeInternal, "read batch")
+	err = WithContext(wrapped, map[string]string{"query": "SELECT amount FROM t", "row": "1031"})
+	err = fmt.Errorf("do get: %w", err)
+
+	assert.Equal(t, map[string]string{
+		"column": "amount",
+		"query":  "SELECT amount FROM t",
+		"row":    "1031",
+	}, Context(err))
+
+	// The code and message chain are unaffected.
+	assert.Equal(t, CodeInternal, GetCode(err))
+	assert.True(t, errors.Is(err, base))
+	assert.Equal(t, "do get: "+wrapped.Error(), err.Error())
+
+	assert.Empty(t, Context(fmt.Errorf("plain")))
+}
+
+func TestLogFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	err := WithContext(New(CodeQueryFailed, "failed to scan row"), map[string]string{"column": "amount", "row": "7"})
+	LogFields(logger.Error(), err).Msg("batch reader")
+
+	var line map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, map[string]string{
+		"level":   "error",
+		"error":   err.Error(),
+		"column":  "amount",
+		"row":     "7",
+		"message": "batch reader",
+	}, line)
+}
@@ -0,0 +1,152 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Adapt returns a reader emitting the records of reader with the target
+// schema, for sinks expecting a fixed schema. Each target field takes the
+// column of reader with the same name, cast to the target type when it
+// differs; columns of reader missing from target are dropped. Only casts
+// that cannot lose values are accepted: integers to wider integers,
+// integers and float32 to floating point types that hold them exactly,
+// strings and binaries to their large variants, DATE to Date64,
+// timestamps to finer units of the same zone and Decimal128 and
+// Decimal256 values to types with at least as many integer and fraction
+// digits. A null in a column whose target field is not nullable fails the
+// read. Adapt takes ownership of reader; a target it cannot adapt to is
+// reported by Err.
+func Adapt(reader *BatchReader, target *arrow.Schema) *BatchReader {
+	a := &adapter{input: reader, target: target, alloc: reader.allocator}
+	r := newDerivedReader(target, reader.allocator, reader.logger, recordSource{next: a.next, close: reader.Release})
+
+	source := reader.Schema()
+	a.cols = make([]int, target.NumFields())
+	for i, f := range target.Fields() {
+		idx := source.FieldIndices(f.Name)
+		if len(idx) != 1 {
+			r.err = errors.New(errors.CodeInvalidRequest, fmt.Sprintf("target field %q must match exactly one column", f.Name))
+			return r
+		}
+		if from := source.Field(idx[0]).Type; !canAdapt(from, f.Type) {
+			r.err = errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot adapt column %q from %s to %s", f.Name, from, f.Type))
+			return r
+		}
+		a.cols[i] = idx[0]
+	}
+	return r
+}
+
+// adapter produces the adapted records of Adapt.
+type adapter struct {
+	input  *BatchReader
+	target *arrow.Schema
+	cols   []int // column of the input for each target field
+	alloc  memory.Allocator
+}
+
+// next returns the next input record adapted to the target schema.
+func (a *adapter) next() (arrow.Record, error) {
+	if !a.input.Next() {
+		if err := a.input.Err(); err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to read adapted input")
+		}
+		return nil, nil
+	}
+	rec := a.input.record
+
+	ctx := compute.WithAllocator(context.Background(), a.alloc)
+	cols := make([]arrow.Array, 0, len(a.cols))
+	defer func() {
+		for _, c := range cols {
+			c.Release()
+		}
+	}()
+	for i, f := range a.target.Fields() {
+		col := rec.Column(a.cols[i])
+		if !f.Nullable && col.NullN() > 0 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("column %q has nulls but its target field is not nullable", f.Name))
+		}
+		if arrow.TypeEqual(col.DataType(), f.Type) {
+			col.Retain()
+			cols = append(cols, col)
+			continue
+		}
+		cast, err := compute.CastArray(ctx, col, compute.SafeCastOptions(f.Type))
+		if err != nil {
+			return nil, errors.Wrapf(err, errors.CodeInvalidRequest, "failed to cast column %q to %s", f.Name, f.Type)
+		}
+		cols = append(cols, cast)
+	}
+	return array.NewRecord(a.target, cols, rec.NumRows()), nil
+}
+
+// canAdapt reports whether every value of type from can be represented in
+// type to.
+func canAdapt(from, to arrow.DataType) bool {
+	if arrow.TypeEqual(from, to) {
+		return true
+	}
+	fromID, toID := from.ID(), to.ID()
+	switch {
+	case arrow.IsSignedInteger(fromID):
+		bits := from.(arrow.FixedWidthDataType).BitWidth()
+		switch {
+		case arrow.IsSignedInteger(toID):
+			return to.(arrow.FixedWidthDataType).BitWidth() >= bits
+		case arrow.IsFloating(toID):
+			return floatHoldsInteger(toID, bits)
+		}
+	case arrow.IsUnsignedInteger(fromID):
+		bits := from.(arrow.FixedWidthDataType).BitWidth()
+		switch {
+		case arrow.IsUnsignedInteger(toID):
+			return to.(arrow.FixedWidthDataType).BitWidth() >= bits
+		case arrow.IsSignedInteger(toID):
+			return to.(arrow.FixedWidthDataType).BitWidth() > bits
+		case arrow.IsFloating(toID):
+			return floatHoldsInteger(toID, bits)
+		}
+	case fromID == arrow.FLOAT32:
+		return toID == arrow.FLOAT64
+	case fromID == arrow.STRING:
+		return toID == arrow.LARGE_STRING
+	case fromID == arrow.BINARY:
+		return toID == arrow.LARGE_BINARY
+	case fromID == arrow.DATE32:
+		return toID == arrow.DATE64
+	case fromID == arrow.TIMESTAMP:
+		if toID != arrow.TIMESTAMP {
+			return false
+		}
+		f, t := from.(*arrow.TimestampType), to.(*arrow.TimestampType)
+		return f.TimeZone == t.TimeZone && t.Unit >= f.Unit
+	case fromID == arrow.DECIMAL128 || fromID == arrow.DECIMAL256:
+		if toID != arrow.DECIMAL128 && toID != arrow.DECIMAL256 {
+			return false
+		}
+		f, t := from.(arrow.DecimalType), to.(arrow.DecimalType)
+		return t.GetScale() >= f.GetScale() && t.GetPrecision()-t.GetScale() >= f.GetPrecision()-f.GetScale()
+	}
+	return false
+}
+
+// floatHoldsInteger reports whether the floating point type id represents
+// every integer of the given width exactly.
+func floatHoldsInteger(id arrow.Type, bits int) bool {
+	switch id {
+	case arrow.FLOAT32:
+		return bits <= 16
+	case arrow.FLOAT64:
+		return bits <= 32
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestAdapt(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = `SELECT * FROM (VALUES
+		(1::INTEGER, 'ant', 1.5::FLOAT, 200::UTINYINT, DATE '2024-03-01', 12.5::DECIMAL(4, 1), 'dropped'),
+		(2::INTEGER, NULL, 2.25::FLOAT, 7::UTINYINT, DATE '1970-01-02', NULL, 'dropped'),
+		(3::INTEGER, 'cat', NULL, 0::UTINYINT, NULL, -0.1::DECIMAL(4, 1), 'dropped')) t(id, name, score, level, day, amount, extra)`
+
+	widened := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.LargeString, Nullable: true},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "level", Type: arrow.PrimitiveTypes.Int16, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date64, Nullable: true},
+		{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 3}, Nullable: true},
+	}, nil)
+
+	t.Run("widened", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc, queryRows(t, query), logger, WithBatchSize(2))
+		require.NoError(t, err)
+		adapted := Adapt(reader, widened)
+		defer adapted.Release()
+		require.NoError(t, adapted.Err())
+		assert.Same(t, widened, adapted.Schema())
+
+		var rows [][]interface{}
+		for adapted.Next() {
+			rec := adapted.Record()
+			assert.True(t, widened.Equal(rec.Schema()))
+			batch, err := RecordToRows(rec)
+			require.NoError(t, err)
+			rows = append(rows, batch...)
+			rec.Release()
+		}
+		require.NoError(t, adapted.Err())
+
+		require.Len(t, rows, 3)
+		assert.Equal(t, []interface{}{"ant", int64(1), 1.5, int16(200), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "12.500"}, rows[0])
+		assert.Equal(t, []interface{}{nil, int64(2), 2.25, int16(7), time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), nil}, rows[1])
+		assert.Equal(t, []interface{}{"cat", int64(3), nil, int16(0), nil, "-0.100"}, rows[2])
+	})
+
+	t.Run("unsafe casts", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			target arrow.Field
+			msg    string
+		}{
+			{"narrowing", arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int16}, "from int32 to int16"},
+			{"float to int", arrow.Field{Name: "score", Type: arrow.PrimitiveTypes.Int64}, "from float32 to int64"},
+			{"unsigned to same width", arrow.Field{Name: "level", Type: arrow.PrimitiveTypes.Int8}, "from uint8 to int8"},
+			{"lost digits", arrow.Field{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 0}}, "from decimal(4, 1)"},
+			{"missing", arrow.Field{Name: "nope", Type: arrow.PrimitiveTypes.Int64}, `"nope" must match exactly one column`},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger)
+				require.NoError(t, err)
+				adapted := Adapt(reader, arrow.NewSchema([]arrow.Field{tc.target}, nil))
+				defer adapted.Release()
+				assert.False(t, adapted.Next())
+				assert.Equal(t, errors.CodeInvalidRequest, errors.GetCode(adapted.Err()))
+				assert.ErrorContains(t, adapted.Err(), tc.msg)
+			})
+		}
+	})
+
+	t.Run("nulls in non-nullable field", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger)
+		require.NoError(t, err)
+		adapted := Adapt(reader, arrow.NewSchema([]arrow.Field{{Name: "name", Type: arrow.BinaryTypes.LargeString}}, nil))
+		defer adapted.Release()
+		assert.False(t, adapted.Next())
+		assert.ErrorContains(t, adapted.Err(), `column "name" has nulls`)
+	})
+}
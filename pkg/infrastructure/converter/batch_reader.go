@@ -2,15 +2,24 @@
 package converter
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/float16"
 	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/marcboeker/go-duckdb/v2"
 	"github.com/rs/zerolog"
 
 	"github.com/TFMV/porter/pkg/errors"
@@ -18,6 +27,10 @@ import (
 
 const defaultBatchSize = 1024
 
+// maxSingleBatchRows caps the rows read into the one record of
+// WithSingleBatch.
+const maxSingleBatchRows = 1 << 20
+
 // BatchReader reads SQL rows and converts them to Arrow record batches.
 type BatchReader struct {
 	refCount  atomic.Int64
@@ -30,22 +43,78 @@ type BatchReader struct {
 	rowDest   []interface{}
 	logger    zerolog.Logger
 	batchSize int
+	opts      readerOptions
+	leaks     *leakTracker
+	nullFills []interface{}
+	skipped   bool
+	emitted   int64
+	column    int // column being appended, or -1
+	recycler  *recyclingAllocator
+	zones     []*time.Location // per-column zones for DATE conversion
+	lists     []bulkList       // per-column bulk list accumulators, or nil
+	scanDests []*scanDest      // per-column WithScanDest destinations, or nil
+	casts     []*numericCast   // per-column WithNumericCast conversions, or nil
+	buffers   []*scanBuffer    // pooled scan destinations, returned on cleanup
+	formatted []bool           // columns of unknown type formatted as strings, or nil
+	collected []error          // append errors collected by WithErrorCollection
+	computed  []computedColumn // WithComputedColumn columns, after the result columns
+	source    *recordSource    // records of a derived reader, instead of rows
+	slot      bool             // holds one of the SetMaxOpenReaders slots
+	view      RowView          // the scanned row handed to computed columns
+	dedup     *stringDedup     // WithStringDedup conversion of finished records, or nil
+	manifest  manifestState    // what has been read so far, for Manifest
+	ctx       context.Context  // NewBatchReaderContext context, or nil
+	// split is a batch larger than WithMaxRecordRows being handed out in
+	// slices; splitOffset is the first row not yet returned.
+	split       arrow.Record
+	splitOffset int64
+	// pendingErr is an append error held back until the rows read before
+	// the failing row have been returned.
+	pendingErr error
+	// fixedWidth selects the builder-free path for schemas made only of
+	// non-nullable fixed-width numeric columns.
+	fixedWidth bool
+	// held is a row left in rowDest by a batch that ended because column
+	// evolve must be widened to take it; the next batch widens the column
+	// and appends the row before scanning another.
+	held   bool
+	evolve int
+	// resultSig describes the first result set for WithAllResultSets;
+	// resultSet counts those read since, and resultErr holds a mismatch.
+	resultSig []string
+	resultSet int
+	resultErr error
+	// boolWarned marks the columns, or -1 for nested values, for which an
+	// unrecognized boolean spelling has been logged.
+	boolWarned map[int]bool
 }
 
 // NewBatchReader creates a new batch reader from SQL rows.
-func NewBatchReader(allocator memory.Allocator, rows *sql.Rows, logger zerolog.Logger) (*BatchReader, error) {
+func NewBatchReader(allocator memory.Allocator, rows *sql.Rows, logger zerolog.Logger, opts ...Option) (*BatchReader, error) {
 	cols, err := rows.ColumnTypes()
 	if err != nil {
 		rows.Close()
 		return nil, errors.Wrap(err, errors.CodeInternal, "failed to get column types")
 	}
 
-	tc := New(logger)
+	o := newReaderOptions(opts)
+	tc := newTypeConverter(logger)
+	if o.sessionTimeZone != "" {
+		conv, err := NewWithSessionTimeZone(logger, o.sessionTimeZone)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tc = conv.(*typeConverter)
+	}
+	tc.unknownTypes = o.unknownTypes
+	tc.uhugeint = o.uhugeint
 	fields := make([]arrow.Field, len(cols))
 	rowDest := make([]interface{}, len(cols))
+	var formatted []bool
 
 	for i, col := range cols {
-		field, err := tc.GetArrowFieldFromColumn(col)
+		field, asString, err := tc.fieldFromColumn(col)
 		if err != nil {
 			rows.Close()
 			return nil, errors.Wrapf(err, errors.CodeInternal, "failed to convert column %d", i)
@@ -54,18 +123,193 @@ func NewBatchReader(allocator memory.Allocator, rows *sql.Rows, logger zerolog.L
 
 		// Create destination based on field type and nullability
 		rowDest[i] = createScanDest(field)
+		if strings.EqualFold(col.DatabaseTypeName(), "UHUGEINT") {
+			// 128-bit integers arrive as *big.Int, which only scans dynamically
+			rowDest[i] = new(interface{})
+		}
+		if field.Type.ID() == arrow.BOOL && !o.booleanAsInt8 && col.ScanType() != nil && col.ScanType().Kind() == reflect.String {
+			// Some drivers return booleans as "t"/"f" and the like, which
+			// only scan dynamically
+			rowDest[i] = new(interface{})
+		}
+		if isJSONType(field.Type) {
+			rowDest[i] = new(jsonDocument)
+		}
+		if asString {
+			// Values of unknown type are scanned as they come and formatted
+			if formatted == nil {
+				formatted = make([]bool, len(cols))
+			}
+			formatted[i] = true
+			rowDest[i] = new(interface{})
+		}
+
+		if o.schemaEvolution && evolvedType(field.Type) != nil {
+			// Scanned dynamically so that a wider value reaches append
+			// instead of failing the scan.
+			rowDest[i] = new(interface{})
+		}
+
+		// Widen after choosing the destination so values are scanned at
+		// their native width and converted on append.
+		if o.unifyIntegers && arrow.IsInteger(field.Type.ID()) {
+			fields[i].Type = arrow.PrimitiveTypes.Int64
+		}
+		if o.booleanAsInt8 && field.Type.ID() == arrow.BOOL {
+			fields[i].Type = arrow.PrimitiveTypes.Int8
+		}
+		if o.dateAsInt32 && field.Type.ID() == arrow.DATE32 {
+			fields[i].Type = arrow.PrimitiveTypes.Int32
+		}
+		if o.narrowDecimals {
+			fields[i].Type = narrowDecimal(fields[i].Type)
+		}
+		if o.decimalAsFloat64 && arrow.IsDecimal(field.Type.ID()) {
+			fields[i].Type = arrow.PrimitiveTypes.Float64
+		}
+		if o.dayTimeIntervals && field.Type.ID() == arrow.INTERVAL_MONTH_DAY_NANO {
+			fields[i].Type = arrow.FixedWidthTypes.DayTimeInterval
+		}
+	}
+
+	var buffers []*scanBuffer
+	if o.bufferPool {
+		buffers = usePooledBuffers(rowDest, fields)
+	}
+
+	// Computed columns see the row as scanned, before the options below
+	// change field types and names.
+	var view RowView
+	if len(o.computed) > 0 {
+		view = RowView{fields: slices.Clone(fields), dest: rowDest}
+	}
+
+	if o.columnComments != nil {
+		comments, err := loadColumnComments(o.columnComments, logger)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applyColumnComments(fields, comments)
+	}
+
+	casts, err := resolveNumericCasts(fields, o.numericCasts)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	nullFills, err := applyNullFills(fields, o.nullFills)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	if err := applyDictionaryColumns(fields, o.dictionaryColumns); err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	zones, err := resolveColumnZones(fields, cols, o.columnZones)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	scanDests, err := resolveScanDests(fields, o.scanDests)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	useScanDests(rowDest, scanDests)
+
+	if err := renameFields(fields, o.renames); err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	if err := transformNames(fields, o.nameTransform); err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	fields, computed, err := appendComputedFields(fields, view, o.computed)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	if fields, err = appendRowNumberField(fields, o.rowNumberColumn); err != nil {
+		rows.Close()
+		return nil, err
 	}
 
-	schema := arrow.NewSchema(fields, nil)
+	schema, err := withSortedBy(arrow.NewSchema(fields, nil), o.sortedBy)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	var recycler *recyclingAllocator
+	if o.recycleBuffers {
+		recycler = newRecyclingAllocator(allocator)
+		allocator = recycler
+	}
 
 	r := &BatchReader{
 		schema:    schema,
 		rows:      rows,
-		builder:   array.NewRecordBuilder(allocator, schema),
+		builder:   array.NewRecordBuilder(allocator, builderSchema(schema, o.builderFactory)),
 		allocator: allocator,
 		rowDest:   rowDest,
 		logger:    logger,
 		batchSize: defaultBatchSize,
+		opts:      o,
+		nullFills: nullFills,
+		recycler:  recycler,
+		zones:     zones,
+		scanDests: scanDests,
+		casts:     casts,
+		buffers:   buffers,
+		formatted: formatted,
+		computed:  computed,
+		view:      view,
+	}
+	if r.opts.leakCheck {
+		r.leaks = newLeakTracker()
+	}
+	if o.allResultSets {
+		r.resultSig = resultSignature(cols)
+	}
+	if o.stringDedup {
+		r.dedup = newStringDedup(schema)
+	}
+	r.SetBatchSize(o.batchSize)
+	if err := r.checkOffsetOverflow(); err != nil {
+		r.builder.Release()
+		rows.Close()
+		return nil, err
+	}
+	if o.schemaCallback != nil {
+		if err := o.schemaCallback(r.Schema()); err != nil {
+			r.builder.Release()
+			rows.Close()
+			return nil, errors.Wrap(err, errors.CodeFailedPrecondition, "schema rejected by callback")
+		}
+	}
+	if err := acquireReaderSlot(); err != nil {
+		r.builder.Release()
+		rows.Close()
+		return nil, err
+	}
+	r.slot = true
+	// Widened, null-filled, cast, custom-scanned or computed columns need
+	// the conversions done on append.
+	convertOnAppend := o.unifyIntegers || o.booleanAsInt8 || o.dateAsInt32 || o.schemaEvolution || o.decimalAsFloat64 || nullFills != nil || scanDests != nil || casts != nil || computed != nil
+	r.fixedWidth = !convertOnAppend && !o.singleBatch && isFixedWidthSchema(schema)
+	// Null fills go through the per-element path.
+	if nullFills == nil {
+		r.lists = newBulkLists(schema)
 	}
 
 	// Initialize refCount to 1
@@ -75,22 +319,107 @@ func NewBatchReader(allocator memory.Allocator, rows *sql.Rows, logger zerolog.L
 }
 
 // NewBatchReaderWithSchema creates a new batch reader with a predefined schema.
-func NewBatchReaderWithSchema(allocator memory.Allocator, schema *arrow.Schema, rows *sql.Rows, logger zerolog.Logger) (*BatchReader, error) {
+func NewBatchReaderWithSchema(allocator memory.Allocator, schema *arrow.Schema, rows *sql.Rows, logger zerolog.Logger, opts ...Option) (_ *BatchReader, err error) {
 	rowDest := make([]interface{}, schema.NumFields())
 
 	for i, field := range schema.Fields() {
 		rowDest[i] = createScanDest(field)
 	}
 
+	o := newReaderOptions(opts)
+	var buffers []*scanBuffer
+	if o.bufferPool {
+		buffers = usePooledBuffers(rowDest, schema.Fields())
+	}
+	// Like NewBatchReader, close the rows when the reader cannot be built.
+	defer func() {
+		if err != nil {
+			rows.Close()
+			releaseScanBuffers(buffers)
+		}
+	}()
+
+	var zoneCols []*sql.ColumnType
+	if len(o.columnZones) > 0 {
+		cols, err := rows.ColumnTypes()
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to get column types")
+		}
+		zoneCols = cols
+	}
+	zones, err := resolveColumnZones(schema.Fields(), zoneCols, o.columnZones)
+	if err != nil {
+		return nil, err
+	}
+
+	scanDests, err := resolveScanDests(schema.Fields(), o.scanDests)
+	if err != nil {
+		return nil, err
+	}
+	useScanDests(rowDest, scanDests)
+
+	if o.rowNumberColumn != "" {
+		fields, err := appendRowNumberField(schema.Fields(), o.rowNumberColumn)
+		if err != nil {
+			return nil, err
+		}
+		md := schema.Metadata()
+		schema = arrow.NewSchema(fields, &md)
+	}
+
+	schema, err = withSortedBy(schema, o.sortedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultSig []string
+	if o.allResultSets {
+		cols, err := rows.ColumnTypes()
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to get column types")
+		}
+		resultSig = resultSignature(cols)
+	}
+
+	var recycler *recyclingAllocator
+	if o.recycleBuffers {
+		recycler = newRecyclingAllocator(allocator)
+		allocator = recycler
+	}
+
 	r := &BatchReader{
 		schema:    schema,
 		rows:      rows,
-		builder:   array.NewRecordBuilder(allocator, schema),
+		builder:   array.NewRecordBuilder(allocator, builderSchema(schema, o.builderFactory)),
 		allocator: allocator,
 		rowDest:   rowDest,
 		logger:    logger,
 		batchSize: defaultBatchSize,
+		opts:      o,
+		recycler:  recycler,
+		zones:     zones,
+		scanDests: scanDests,
+		buffers:   buffers,
+		resultSig: resultSig,
 	}
+	if r.opts.leakCheck {
+		r.leaks = newLeakTracker()
+	}
+	r.SetBatchSize(o.batchSize)
+	if err := r.checkOffsetOverflow(); err != nil {
+		r.builder.Release()
+		return nil, err
+	}
+	if err := acquireReaderSlot(); err != nil {
+		r.builder.Release()
+		return nil, err
+	}
+	r.slot = true
+	r.fixedWidth = scanDests == nil && !o.singleBatch && isFixedWidthSchema(schema)
+	if o.stringDedup {
+		r.dedup = newStringDedup(schema)
+	}
+	r.lists = newBulkLists(schema)
 
 	// Initialize refCount to 1
 	r.refCount.Store(1)
@@ -107,6 +436,9 @@ func (r *BatchReader) SetBatchSize(size int) {
 
 // Schema returns the Arrow schema.
 func (r *BatchReader) Schema() *arrow.Schema {
+	if r.dedup != nil {
+		return r.dedup.schema
+	}
 	return r.schema
 }
 
@@ -128,19 +460,50 @@ func (r *BatchReader) cleanup() {
 		r.rows.Close()
 		r.rows = nil
 	}
+	if r.source != nil {
+		r.source.close()
+		r.source = nil
+	}
+	if r.slot {
+		releaseReaderSlot()
+		r.slot = false
+	}
 	if r.builder != nil {
 		r.builder.Release()
 		r.builder = nil
 	}
+	// Slices handed out by Record hold their own references, so releasing
+	// the reader's does not affect them.
 	if r.record != nil {
-		r.logger.Debug().
-			Int("record_num_cols_in_cleanup_before_nil", int(r.record.NumCols())).
-			Msg("BatchReader.cleanup: r.record is not nil, but will not be released here. Setting to nil.")
-		r.record = nil // Ensure we don't hold a reference after cleanup.
+		r.record.Release()
+		r.record = nil
+	}
+	if r.leaks != nil {
+		if n := r.leaks.count(); n > 0 {
+			r.logger.Error().
+				Int("leaked_records", n).
+				Msg("BatchReader.cleanup: record slices returned by Record() are still retained")
+			r.err = errors.New(errors.CodeInternal, fmt.Sprintf("%d record slice(s) still retained at cleanup", n))
+		}
+	}
+	if r.recycler != nil {
+		r.recycler.release()
+	}
+	if r.split != nil {
+		r.split.Release()
+		r.split = nil
+	}
+	if r.buffers != nil {
+		releaseScanBuffers(r.buffers)
+		r.buffers = nil
 	}
 }
 
-// Record returns the current record batch.
+// Record returns the current record batch. The record is owned by the
+// caller, who must release it, and stays valid, with its data unchanged,
+// after later calls to Next and after the reader is released. Unlike the
+// records of array.RecordReader, which are only borrowed, every call hands
+// out a new reference.
 func (r *BatchReader) Record() arrow.Record {
 	if r.record == nil {
 		r.logger.Debug().Msg("BatchReader.Record() called, r.record is nil")
@@ -162,9 +525,76 @@ func (r *BatchReader) Record() arrow.Record {
 		Int64("slice_num_rows", newRecSlice.NumRows()).
 		Msg("BatchReader.Record: State of newRecSlice before returning")
 
+	if r.leaks != nil {
+		return r.leaks.track(newRecSlice)
+	}
 	return newRecSlice
 }
 
+// RecordSlice returns the rows [offset, offset+length) of the current record
+// batch. Like Record, the returned slice is owned by the caller and remains
+// valid after the next call to Next.
+func (r *BatchReader) RecordSlice(offset, length int64) (arrow.Record, error) {
+	if r.record == nil {
+		return nil, errors.New(errors.CodeFailedPrecondition, "no current record to slice")
+	}
+
+	numRows := r.record.NumRows()
+	if offset < 0 || length < 0 || offset > numRows || length > numRows-offset {
+		return nil, errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("slice [%d, %d) out of range for record with %d rows", offset, offset+length, numRows))
+	}
+
+	slice := r.record.NewSlice(offset, offset+length)
+	if r.leaks != nil {
+		return r.leaks.track(slice), nil
+	}
+	return slice, nil
+}
+
+// Columns returns the columns of the current record batch without a
+// record around them, for zero-copy integrations that hand buffers on
+// column by column. Each array is retained for the caller, who must
+// release every one of them; like the slices returned by Record, they stay
+// valid after later calls to Next. It returns nil when there is no current
+// record.
+func (r *BatchReader) Columns() []arrow.Array {
+	if r.record == nil {
+		return nil
+	}
+	cols := slices.Clone(r.record.Columns())
+	for _, c := range cols {
+		c.Retain()
+	}
+	return cols
+}
+
+// TakeRecord transfers ownership of the current record batch to the caller.
+// Unlike Record, no slice is created: the reader forgets the record, so the
+// next call to Next starts fresh and will not release it. The caller must
+// release the returned record.
+func (r *BatchReader) TakeRecord() (arrow.Record, error) {
+	if r.record == nil {
+		return nil, errors.New(errors.CodeFailedPrecondition, "no current record to take")
+	}
+
+	rec := r.record
+	r.record = nil
+	if r.leaks != nil {
+		return r.leaks.track(rec), nil
+	}
+	return rec, nil
+}
+
+// EmptyRecord returns a zero-row record with the reader's schema, for
+// consumers that need a valid record even when the query returns no rows.
+// The caller must release the returned record.
+func (r *BatchReader) EmptyRecord() arrow.Record {
+	b := array.NewRecordBuilder(r.allocator, r.Schema())
+	defer b.Release()
+	return b.NewRecord()
+}
+
 // Err returns any error that occurred during reading.
 func (r *BatchReader) Err() error {
 	return r.err
@@ -176,6 +606,55 @@ func (r *BatchReader) Next() bool {
 		return false
 	}
 
+	start := time.Now()
+	ok := r.safeReadBatch()
+	if ok {
+		if r.opts.manifestStats && r.manifest.stats == nil {
+			r.manifest.stats = make([]ColumnStats, r.record.NumCols())
+		}
+		if r.opts.approxDistinct && r.manifest.distinct == nil {
+			r.manifest.distinct = make([]*hyperLogLog, r.record.NumCols())
+			for i := range r.manifest.distinct {
+				r.manifest.distinct[i] = new(hyperLogLog)
+			}
+		}
+		r.manifest.observe(r.record)
+	} else {
+		r.manifest.drained = r.err == nil
+	}
+
+	if obs := r.opts.observer; obs != nil {
+		if ok {
+			obs.OnBatch(int(r.record.NumRows()), recordBytes(r.record), time.Since(start))
+		} else if r.err != nil {
+			obs.OnError(r.err)
+		}
+	}
+	return ok
+}
+
+// safeReadBatch calls readBatch, converting a panic raised while converting
+// values into an error so that a malformed value cannot take down the
+// calling goroutine.
+func (r *BatchReader) safeReadBatch() (ok bool) {
+	r.column = -1
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Error().Interface("panic", p).Int("column", r.column).Msg("BatchReader.Next: recovered from panic")
+			if r.column >= 0 {
+				r.err = errors.New(errors.CodeInternal, fmt.Sprintf("panic while appending value for column %d: %v", r.column, p))
+			} else {
+				r.err = errors.New(errors.CodeInternal, fmt.Sprintf("panic while reading batch: %v", p))
+			}
+			ok = false
+		}
+	}()
+	return r.readBatch()
+}
+
+// readBatch reads up to batchSize rows into a new record.
+func (r *BatchReader) readBatch() bool {
+
 	if r.record != nil {
 		r.logger.Debug().
 			Int("internal_rec_num_cols_pre_release", int(r.record.NumCols())).
@@ -185,19 +664,66 @@ func (r *BatchReader) Next() bool {
 		r.record = nil
 	}
 
-	// DIAGNOSTIC: Release and create a new builder for each record batch (even if batch is 1 row)
-	if r.builder != nil {
-		r.builder.Release()
+	if r.split != nil && r.nextSplitSlice() {
+		return true
+	}
+
+	if r.pendingErr != nil {
+		r.err, r.pendingErr = r.pendingErr, nil
+		return false
+	}
+
+	if r.source != nil {
+		return r.readSource()
+	}
+
+	// The rows are closed early once a row limit has been reached.
+	if r.rows == nil || !r.checkContext() {
+		return false
+	}
+
+	if !r.skipped {
+		r.skipped = true
+		if !r.skipRows(r.opts.skipRows) {
+			return false
+		}
+	}
+
+	batchSize := r.batchSize
+	if r.opts.singleBatch {
+		batchSize = maxSingleBatchRows
+	}
+	if limit := r.opts.limitRows; limit > 0 && limit-r.emitted < int64(batchSize) {
+		batchSize = int(limit - r.emitted)
+	}
+
+	if r.fixedWidth {
+		n, ok := r.readFixedWidthBatch(batchSize)
+		return ok && r.finishBatch(n)
+	}
+
+	if r.held {
+		if err := r.evolveColumn(r.evolve); err != nil {
+			r.err = err
+			return false
+		}
+	}
+
+	// The builder is reused: finishRecord leaves it empty for this batch.
+	for _, bl := range r.lists {
+		if bl != nil {
+			bl.reset()
+		}
 	}
-	r.builder = array.NewRecordBuilder(r.allocator, r.schema)
-	// Ensure the new builder is retained if the BatchReader itself is retained.
-	// No, builder itself doesn't have Retain/Release like a Record/Array.
 
 	rowsProcessedInBatch := 0
-	for i := 0; i < r.batchSize; i++ {
-		if !r.rows.Next() {
+	var appendErr error
+	for i := 0; i < batchSize; i++ {
+		if r.held {
+			r.held = false
+		} else if !r.nextRow() {
 			if i == 0 { // No rows were read in this attempt to fill a batch
-				r.err = r.rows.Err()
+				r.err = r.rowsErr()
 				if r.err == nil { // No error, but no rows means end of result set
 					r.logger.Debug().Msg("BatchReader.Next: No more rows in r.rows.Next(), end of data.")
 					// r.cleanup() // No, cleanup is for when the whole reader is done.
@@ -205,29 +731,77 @@ func (r *BatchReader) Next() bool {
 				return false // No rows in this batch, and no more rows available
 			}
 			break // End of result set, but some rows were processed for this batch
-		}
-
-		if err := r.rows.Scan(r.rowDest...); err != nil {
-			r.err = errors.Wrap(err, errors.CodeQueryFailed, "failed to scan row")
+		} else if err := r.rows.Scan(r.rowDest...); err != nil {
+			r.err = errors.WithContext(errors.Wrap(err, errors.CodeQueryFailed, "failed to scan row"),
+				map[string]string{"row": strconv.FormatInt(r.emitted+int64(i), 10)})
 			return false
 		}
 
 		for colIdx, val := range r.rowDest {
+			r.column = colIdx
+			before := r.builder.Field(colIdx).Len()
 			if err := r.appendValue(colIdx, val); err != nil {
-				r.err = errors.Wrapf(err, errors.CodeInternal, "failed to append value for column %d", colIdx)
-				return false
+				if r.canEvolve(colIdx) {
+					r.held, r.evolve = true, colIdx
+					break
+				}
+				err = errors.WithContext(
+					errors.Wrapf(err, errors.CodeInternal, "failed to append value for column %d", colIdx),
+					map[string]string{
+						"column": r.schema.Field(colIdx).Name,
+						"row":    strconv.FormatInt(r.emitted+int64(i), 10),
+					})
+				if r.collectError(colIdx, before, err) {
+					continue
+				}
+				appendErr = err
+				break
 			}
 		}
+		r.column = -1
+		if r.held {
+			break
+		}
+		if appendErr == nil && r.computed != nil {
+			if err := r.appendComputed(len(r.rowDest)); err != nil {
+				appendErr = errors.WithContext(err, map[string]string{"row": strconv.FormatInt(r.emitted+int64(i), 10)})
+			}
+		}
+		if appendErr != nil {
+			break
+		}
+		if r.opts.rowNumberColumn != "" {
+			r.builder.Field(len(r.rowDest) + len(r.computed)).(*array.Int64Builder).Append(r.emitted + int64(i))
+		}
 		rowsProcessedInBatch++
 	}
 
-	if rowsProcessedInBatch > 0 {
-		r.record = r.builder.NewRecord()
+	if batchSize == maxSingleBatchRows && rowsProcessedInBatch == batchSize && r.opts.singleBatch && r.nextRow() {
+		r.finishRecord(rowsProcessedInBatch).Release()
+		r.err = errors.New(errors.CodeResourceExhausted,
+			fmt.Sprintf("result exceeds %d rows, the most a single batch holds", maxSingleBatchRows))
+		return false
+	}
+
+	if r.held && rowsProcessedInBatch == 0 {
+		// Nothing to return in the narrower schema: widen and go again.
+		return r.readBatch()
+	} else if appendErr != nil {
+		// The failed row is rolled back; the rows before it are still
+		// emitted and the error is reported by the following Next.
+		if rowsProcessedInBatch == 0 {
+			r.err = appendErr
+			return false
+		}
+		r.pendingErr = appendErr
+		r.record = r.finishRecord(rowsProcessedInBatch)
+	} else if rowsProcessedInBatch > 0 {
+		r.record = r.finishRecord(rowsProcessedInBatch)
 		r.logger.Debug().
 			Int("rows_in_batch", rowsProcessedInBatch).
 			Int("record_num_cols_at_creation", int(r.record.NumCols())).
 			Int("record_schema_fields_at_creation", r.record.Schema().NumFields()).
-			Msg("Read batch and created record (with new builder each time - DIAGNOSTIC)")
+			Msg("Read batch and created record")
 	} else {
 		// This case should be caught by r.rows.Next() returning false earlier if no rows were processed.
 		// If we reach here, it implies batchSize might be 0 or an issue in loop logic.
@@ -235,11 +809,124 @@ func (r *BatchReader) Next() bool {
 		return false // No rows were actually processed to form a record
 	}
 
-	if err := r.rows.Err(); err != nil {
+	return r.finishBatch(rowsProcessedInBatch)
+}
+
+// FinishRecord builds a record from the rows appended to the reader's
+// builder since the previous record and leaves the builder empty for reuse.
+// Rows that are not complete in every column are discarded. Next finishes
+// each batch the same way; FinishRecord exposes that step so that batching
+// strategies such as partial flushes can be composed. The caller owns the
+// returned record.
+func (r *BatchReader) FinishRecord() arrow.Record {
+	r.flushLists()
+	n := -1
+	for i := 0; i < r.schema.NumFields(); i++ {
+		if l := r.builder.Field(i).Len(); n < 0 || l < n {
+			n = l
+		}
+	}
+	return r.finishRecord(max(n, 0))
+}
+
+// finishRecord builds a record from the first n rows in the builder and
+// resets it; it is the only place records are built from the builder.
+// Values appended for a row that failed part way through are dropped so
+// that every column has the same length.
+func (r *BatchReader) finishRecord(n int) arrow.Record {
+	rec := r.buildRecord(n)
+	if r.dedup != nil {
+		return r.dedup.apply(r.allocator, rec)
+	}
+	return rec
+}
+
+// buildRecord builds a record of the builder's schema for finishRecord.
+func (r *BatchReader) buildRecord(n int) arrow.Record {
+	r.flushLists()
+
+	complete := true
+	for i := 0; i < r.schema.NumFields(); i++ {
+		if r.builder.Field(i).Len() != n {
+			complete = false
+			break
+		}
+	}
+	// Records of factory-made builders are assembled below so that they
+	// carry the reader's schema rather than the builder's.
+	if complete && r.opts.builderFactory == nil {
+		return r.builder.NewRecord()
+	}
+
+	cols := make([]arrow.Array, r.schema.NumFields())
+	for i := range cols {
+		arr := r.builder.Field(i).NewArray()
+		if arr.Len() > n {
+			sliced := array.NewSlice(arr, 0, int64(n))
+			arr.Release()
+			arr = sliced
+		}
+		cols[i] = arr
+	}
+	rec := array.NewRecord(r.schema, cols, int64(n))
+	for _, c := range cols {
+		c.Release()
+	}
+	return rec
+}
+
+// finishBatch checks the result set after a batch of n rows has been read
+// and closes it early once the row limit is reached.
+func (r *BatchReader) finishBatch(n int) bool {
+	if err := r.rowsErr(); err != nil {
 		r.err = err
 		return false
 	}
 
+	r.emitted += int64(n)
+	if limit := r.opts.limitRows; limit > 0 && r.emitted >= limit {
+		r.logger.Debug().Int64("limit", limit).Msg("BatchReader.Next: row limit reached, closing rows")
+		if err := r.rows.Close(); err != nil {
+			r.err = errors.Wrap(err, errors.CodeInternal, "failed to close rows")
+		}
+		r.rows = nil
+	}
+
+	if maxRows := r.opts.maxRecordRows; maxRows > 0 && r.record.NumRows() > maxRows {
+		r.split, r.splitOffset = r.record, 0
+		r.record = nil
+		return r.nextSplitSlice()
+	}
+	return true
+}
+
+// nextSplitSlice makes the next WithMaxRecordRows slice of the split batch
+// the current record. It releases the batch and returns false once every
+// slice has been handed out.
+func (r *BatchReader) nextSplitSlice() bool {
+	n := r.split.NumRows()
+	if r.splitOffset >= n {
+		r.split.Release()
+		r.split = nil
+		return false
+	}
+	end := min(r.splitOffset+r.opts.maxRecordRows, n)
+	r.record = r.split.NewSlice(r.splitOffset, end)
+	r.splitOffset = end
+	return true
+}
+
+// skipRows advances past n rows without scanning them, continuing into
+// later result sets with WithAllResultSets. It returns false if the rows
+// ended first.
+func (r *BatchReader) skipRows(n int64) bool {
+	for i := int64(0); i < n; i++ {
+		if !r.nextRow() {
+			r.err = r.rowsErr()
+			r.logger.Debug().Int64("skipped", i).Msg("BatchReader.Next: result set ended while skipping rows")
+			return false
+		}
+	}
 	return true
 }
 
@@ -247,6 +934,63 @@ func (r *BatchReader) Next() bool {
 func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 	fb := r.builder.Field(colIdx)
 
+	if r.scanDests != nil && r.scanDests[colIdx] != nil {
+		return r.scanDests[colIdx].append(value, fb)
+	}
+
+	if r.lists != nil && r.lists[colIdx] != nil {
+		if v, ok := value.(*interface{}); ok {
+			if r.lists[colIdx].add(*v) {
+				return nil
+			}
+			// Keep the lists in order before appending this one per element.
+			r.lists[colIdx].flush(fb.(*array.ListBuilder))
+		}
+	}
+
+	if r.nullFills != nil && r.nullFills[colIdx] != nil && isNullScan(value) {
+		return r.appendDynamicValue(fb, r.nullFills[colIdx])
+	}
+
+	if r.formatted != nil && r.formatted[colIdx] {
+		if v, ok := value.(*interface{}); ok {
+			if *v == nil {
+				fb.AppendNull()
+				return nil
+			}
+			return appendStringValue(fb, formatValue(*v))
+		}
+	}
+
+	if r.casts != nil && r.casts[colIdx] != nil {
+		return r.casts[colIdx].append(fb, value)
+	}
+
+	if r.opts.unifyIntegers {
+		if b, ok := fb.(*array.Int64Builder); ok {
+			if handled, err := appendWidenedInteger(b, value); handled {
+				return err
+			}
+		}
+	}
+
+	if r.opts.booleanAsInt8 {
+		if b, ok := fb.(*array.Int8Builder); ok {
+			switch v := value.(type) {
+			case *bool:
+				b.Append(boolToInt8(*v))
+				return nil
+			case *sql.NullBool:
+				if !v.Valid {
+					b.AppendNull()
+				} else {
+					b.Append(boolToInt8(v.Bool))
+				}
+				return nil
+			}
+		}
+	}
+
 	switch v := value.(type) {
 	case *bool:
 		if v == nil {
@@ -279,6 +1023,12 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 		} else {
 			fb.(*array.Uint8Builder).Append(v.Byte)
 		}
+	case **uint8:
+		if v == nil || *v == nil {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint8Builder).Append(**v)
+		}
 
 	case *int16:
 		if v == nil {
@@ -292,6 +1042,12 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 		} else {
 			fb.(*array.Uint16Builder).Append(*v)
 		}
+	case **uint16:
+		if v == nil || *v == nil {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint16Builder).Append(**v)
+		}
 	case *sql.NullInt16:
 		if !v.Valid {
 			fb.AppendNull()
@@ -347,7 +1103,14 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 		if v == nil {
 			fb.AppendNull()
 		} else {
-			fb.(*array.Float64Builder).Append(*v)
+			switch b := fb.(type) {
+			case *array.Float64Builder:
+				b.Append(*v)
+			case *array.Float32Builder:
+				return r.appendFloat32(b, *v)
+			default:
+				return errors.New(errors.CodeInternal, "unexpected builder type for float")
+			}
 		}
 	case *sql.NullFloat64:
 		if !v.Valid {
@@ -357,7 +1120,7 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 			case *array.Float64Builder:
 				b.Append(v.Float64)
 			case *array.Float32Builder:
-				b.Append(float32(v.Float64))
+				return r.appendFloat32(b, v.Float64)
 			default:
 				return errors.New(errors.CodeInternal, "unexpected builder type for float")
 			}
@@ -366,14 +1129,18 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 	case *string:
 		if v == nil {
 			fb.AppendNull()
+		} else if isTemporalBuilder(fb) {
+			return r.appendTimeString(fb, *v)
 		} else {
-			fb.(*array.StringBuilder).Append(*v)
+			return appendStringValue(fb, *v)
 		}
 	case *sql.NullString:
 		if !v.Valid {
 			fb.AppendNull()
+		} else if isTemporalBuilder(fb) {
+			return r.appendTimeString(fb, v.String)
 		} else {
-			fb.(*array.StringBuilder).Append(v.String)
+			return appendStringValue(fb, v.String)
 		}
 
 	case *[]byte:
@@ -382,20 +1149,132 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 		} else {
 			fb.(*array.BinaryBuilder).Append(*v)
 		}
+	case *scanBuffer:
+		if !v.valid {
+			fb.AppendNull()
+		} else {
+			return appendBytesValue(fb, v.buf)
+		}
+	case *jsonDocument:
+		return appendJSONValue(fb, v.raw)
 
 	case *time.Time:
 		if v == nil {
 			fb.AppendNull()
 		} else {
-			if err := appendTimeValue(fb, *v); err != nil {
+			if err := r.appendColumnTime(colIdx, fb, *v); err != nil {
 				return err
 			}
 		}
+	case *time.Duration:
+		if v == nil {
+			fb.AppendNull()
+		} else {
+			return appendDuration(fb, *v)
+		}
 	case *sql.NullTime:
 		if !v.Valid {
 			fb.AppendNull()
 		} else {
-			if err := appendTimeValue(fb, v.Time); err != nil {
+			if err := r.appendColumnTime(colIdx, fb, v.Time); err != nil {
+				return err
+			}
+		}
+	case *timeOrString:
+		if !v.Valid {
+			fb.AppendNull()
+		} else if v.IsString {
+			return r.appendTimeString(fb, v.String)
+		} else {
+			if err := r.appendColumnTime(colIdx, fb, v.Time); err != nil {
+				return err
+			}
+		}
+
+	// Generic sql.Null[T] destinations (Go 1.22+)
+	case *sql.Null[bool]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.BooleanBuilder).Append(v.V)
+		}
+	case *sql.Null[int8]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Int8Builder).Append(v.V)
+		}
+	case *sql.Null[int16]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Int16Builder).Append(v.V)
+		}
+	case *sql.Null[int32]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Int32Builder).Append(v.V)
+		}
+	case *sql.Null[int64]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Int64Builder).Append(v.V)
+		}
+	case *sql.Null[uint8]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint8Builder).Append(v.V)
+		}
+	case *sql.Null[uint16]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint16Builder).Append(v.V)
+		}
+	case *sql.Null[uint32]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint32Builder).Append(v.V)
+		}
+	case *sql.Null[uint64]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Uint64Builder).Append(v.V)
+		}
+	case *sql.Null[float32]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Float32Builder).Append(v.V)
+		}
+	case *sql.Null[float64]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.Float64Builder).Append(v.V)
+		}
+	case *sql.Null[string]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			return appendStringValue(fb, v.V)
+		}
+	case *sql.Null[[]byte]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			fb.(*array.BinaryBuilder).Append(v.V)
+		}
+	case *sql.Null[time.Time]:
+		if !v.Valid {
+			fb.AppendNull()
+		} else {
+			if err := r.appendColumnTime(colIdx, fb, v.V); err != nil {
 				return err
 			}
 		}
@@ -404,9 +1283,14 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 		// Handle dynamic types
 		if v == nil || *v == nil {
 			fb.AppendNull()
-		} else {
-			return appendDynamicValue(fb, *v)
+			break
+		}
+		if s, ok := (*v).(string); ok {
+			if b, ok := fb.(*array.BooleanBuilder); ok {
+				return r.appendBoolString(colIdx, b, s)
+			}
 		}
+		return r.appendDynamicValue(fb, *v)
 
 	default:
 		return errors.New(errors.CodeInternal, "unsupported scan type: "+reflect.TypeOf(value).String())
@@ -415,6 +1299,89 @@ func (r *BatchReader) appendValue(colIdx int, value interface{}) error {
 	return nil
 }
 
+// boolToInt8 returns 1 for true and 0 for false.
+func boolToInt8(v bool) int8 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// appendWidenedInteger appends an integer scan value of any width to an
+// Int64Builder. It reports false when value is not an integer destination.
+func appendWidenedInteger(b *array.Int64Builder, value interface{}) (bool, error) {
+	var (
+		n     int64
+		valid = true
+		wide  uint64 // unsigned 64-bit values, checked against the int64 range
+	)
+
+	switch v := value.(type) {
+	case *int8:
+		n = int64(*v)
+	case *int16:
+		n = int64(*v)
+	case *int32:
+		n = int64(*v)
+	case *int64:
+		n = *v
+	case *uint8:
+		n = int64(*v)
+	case *uint16:
+		n = int64(*v)
+	case *uint32:
+		n = int64(*v)
+	case *uint64:
+		wide = *v
+		n = int64(*v)
+	case **uint8:
+		if valid = v != nil && *v != nil; valid {
+			n = int64(**v)
+		}
+	case **uint16:
+		if valid = v != nil && *v != nil; valid {
+			n = int64(**v)
+		}
+	case *sql.NullByte:
+		n, valid = int64(v.Byte), v.Valid
+	case *sql.NullInt16:
+		n, valid = int64(v.Int16), v.Valid
+	case *sql.NullInt32:
+		n, valid = int64(v.Int32), v.Valid
+	case *sql.NullInt64:
+		n, valid = v.Int64, v.Valid
+	case *sql.Null[int8]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[int16]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[int32]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[int64]:
+		n, valid = v.V, v.Valid
+	case *sql.Null[uint8]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[uint16]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[uint32]:
+		n, valid = int64(v.V), v.Valid
+	case *sql.Null[uint64]:
+		wide, valid = v.V, v.Valid
+		n = int64(v.V)
+	default:
+		return false, nil
+	}
+
+	if !valid {
+		b.AppendNull()
+		return true, nil
+	}
+	if wide > math.MaxInt64 {
+		return true, errors.New(errors.CodeInternal, fmt.Sprintf("value %d overflows int64", wide))
+	}
+	b.Append(n)
+	return true, nil
+}
+
 // createScanDest creates an appropriate scan destination based on the Arrow field type.
 func createScanDest(field arrow.Field) interface{} {
 	switch field.Type.ID() {
@@ -426,7 +1393,7 @@ func createScanDest(field arrow.Field) interface{} {
 
 	case arrow.INT8:
 		if field.Nullable {
-			return &sql.NullByte{}
+			return &sql.Null[int8]{}
 		}
 		return new(int8)
 
@@ -444,8 +1411,8 @@ func createScanDest(field arrow.Field) interface{} {
 
 	case arrow.UINT16:
 		if field.Nullable {
-			// No sql.NullUint16, use pointer
-			return new(*uint16)
+			// No sql.NullUint16, use the generic form
+			return &sql.Null[uint16]{}
 		}
 		return new(uint16)
 
@@ -457,8 +1424,8 @@ func createScanDest(field arrow.Field) interface{} {
 
 	case arrow.UINT32:
 		if field.Nullable {
-			// No sql.NullUint32, use pointer
-			return new(*uint32)
+			// No sql.NullUint32, use the generic form
+			return &sql.Null[uint32]{}
 		}
 		return new(uint32)
 
@@ -470,8 +1437,8 @@ func createScanDest(field arrow.Field) interface{} {
 
 	case arrow.UINT64:
 		if field.Nullable {
-			// No sql.NullUint64, use pointer
-			return new(*uint64)
+			// No sql.NullUint64, use the generic form
+			return &sql.Null[uint64]{}
 		}
 		return new(uint64)
 
@@ -498,17 +1465,13 @@ func createScanDest(field arrow.Field) interface{} {
 		return &b
 
 	case arrow.DATE32, arrow.DATE64, arrow.TIME32, arrow.TIME64, arrow.TIMESTAMP:
-		if field.Nullable {
-			return &sql.NullTime{}
-		}
-		return new(time.Time)
+		// Some driver versions return temporal values as strings
+		return &timeOrString{}
 
 	case arrow.DECIMAL, arrow.DECIMAL256:
-		// Handle decimal as string for now
-		if field.Nullable {
-			return &sql.NullString{}
-		}
-		return new(string)
+		// Drivers return their native decimal type, which is converted
+		// from its unscaled value on append
+		return new(interface{})
 
 	default:
 		// For unknown types, use interface{}
@@ -521,27 +1484,29 @@ func appendTimeValue(fb array.Builder, t time.Time) error {
 	switch b := fb.(type) {
 	case *array.Date32Builder:
 		// Date32 is days since Unix epoch
-		days := int32(t.Unix() / 86400)
-		b.Append(arrow.Date32(days))
+		b.Append(arrow.Date32FromTime(t))
 
 	case *array.Date64Builder:
-		// Date64 is milliseconds since Unix epoch
-		b.Append(arrow.Date64(t.UnixMilli()))
+		// Date64 is milliseconds since Unix epoch, truncated to whole days
+		b.Append(arrow.Date64FromTime(t))
 
 	case *array.Time32Builder:
-		// Time32 seconds since midnight
-		seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
-		b.Append(arrow.Time32(seconds))
+		// Time32 seconds or milliseconds since midnight
+		unit := b.Type().(*arrow.Time32Type).Unit
+		b.Append(arrow.Time32(nanosSinceMidnight(t) / int64(unit.Multiplier())))
 
 	case *array.Time64Builder:
-		// Time64 microseconds since midnight
-		micros := int64(t.Hour())*3600000000 + int64(t.Minute())*60000000 +
-			int64(t.Second())*1000000 + int64(t.Nanosecond())/1000
-		b.Append(arrow.Time64(micros))
+		// Time64 microseconds or nanoseconds since midnight
+		unit := b.Type().(*arrow.Time64Type).Unit
+		b.Append(arrow.Time64(nanosSinceMidnight(t) / int64(unit.Multiplier())))
 
 	case *array.TimestampBuilder:
-		// Timestamp microseconds since Unix epoch
-		b.Append(arrow.Timestamp(t.UnixMicro()))
+		// Timestamp since Unix epoch in the builder's unit
+		ts, err := arrow.TimestampFromTime(t, b.Type().(*arrow.TimestampType).Unit)
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "timestamp out of range")
+		}
+		b.Append(ts)
 
 	default:
 		return errors.New(errors.CodeInternal, "unexpected builder type for time value")
@@ -550,34 +1515,316 @@ func appendTimeValue(fb array.Builder, t time.Time) error {
 	return nil
 }
 
+// nanosSinceMidnight returns the time of day of t in nanoseconds.
+func nanosSinceMidnight(t time.Time) int64 {
+	return int64(t.Hour())*int64(time.Hour) + int64(t.Minute())*int64(time.Minute) +
+		int64(t.Second())*int64(time.Second) + int64(t.Nanosecond())
+}
+
+// appendTime appends t like appendTimeValue, except that timestamps count
+// from the WithTimestampEpoch epoch when one is set and that dates read
+// with WithDateAsInt32 are appended to Int32 columns as days.
+func (r *BatchReader) appendTime(fb array.Builder, t time.Time) error {
+	if b, ok := fb.(*array.Int32Builder); ok && r.opts.dateAsInt32 {
+		b.Append(int32(arrow.Date32FromTime(t)))
+		return nil
+	}
+
+	b, ok := fb.(*array.TimestampBuilder)
+	if !ok || r.opts.timestampEpoch == nil {
+		return appendTimeValue(fb, t)
+	}
+
+	unit := b.Type().(*arrow.TimestampType).Unit
+	ts, err := arrow.TimestampFromTime(t, unit)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "timestamp out of range")
+	}
+	epoch, err := arrow.TimestampFromTime(*r.opts.timestampEpoch, unit)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "timestamp epoch out of range")
+	}
+	offset := ts - epoch
+	if (epoch < 0 && offset < ts) || (epoch > 0 && offset > ts) {
+		return errors.New(errors.CodeInternal, fmt.Sprintf("timestamp %s out of range from epoch %s", t, *r.opts.timestampEpoch))
+	}
+	b.Append(offset)
+	return nil
+}
+
+// appendFloat32 narrows a float64 value into a Float32 column according to
+// WithFloatRounding.
+func (r *BatchReader) appendFloat32(b *array.Float32Builder, f float64) error {
+	narrowed := float32(f)
+	if r.opts.floatRounding == FloatRoundError && float64(narrowed) != f && !math.IsNaN(f) {
+		return errors.New(errors.CodeInvalidRequest, fmt.Sprintf("value %v is not exactly representable as float32", f))
+	}
+	b.Append(narrowed)
+	return nil
+}
+
+// appendDynamicFloat appends a floating point value of either width to
+// the column's floating point builder.
+func (r *BatchReader) appendDynamicFloat(fb array.Builder, f float64) error {
+	switch b := fb.(type) {
+	case *array.Float64Builder:
+		b.Append(f)
+	case *array.Float32Builder:
+		return r.appendFloat32(b, f)
+	case *array.Float16Builder:
+		b.Append(float16.New(float32(f)))
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for float value", fb))
+	}
+	return nil
+}
+
 // appendDynamicValue appends a dynamically typed value.
-func appendDynamicValue(fb array.Builder, value interface{}) error {
+func (r *BatchReader) appendDynamicValue(fb array.Builder, value interface{}) error {
 	if value == nil {
 		fb.AppendNull()
 		return nil
 	}
 
+	if a, ok := fb.(ValueAppender); ok {
+		return a.AppendValue(value)
+	}
+	if eb, ok := fb.(*array.ExtensionBuilder); ok && isJSONType(eb.Type()) {
+		return appendJSONDocument(eb, value)
+	}
+
 	switch v := value.(type) {
 	case bool:
 		fb.(*array.BooleanBuilder).Append(v)
-	case int64:
-		fb.(*array.Int64Builder).Append(v)
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return appendDynamicInteger(fb, v)
+	case float32:
+		return r.appendDynamicFloat(fb, float64(v))
 	case float64:
-		fb.(*array.Float64Builder).Append(v)
+		return r.appendDynamicFloat(fb, v)
 	case string:
-		fb.(*array.StringBuilder).Append(v)
+		if isTemporalBuilder(fb) {
+			return r.appendTimeString(fb, v)
+		}
+		if b, ok := fb.(*array.BooleanBuilder); ok {
+			return r.appendBoolString(-1, b, v)
+		}
+		return appendStringValue(fb, v)
 	case []byte:
-		fb.(*array.BinaryBuilder).Append(v)
+		return appendBytesValue(fb, v)
+	case json.RawMessage:
+		return appendJSONValue(fb, v)
+	case *big.Int:
+		return appendBigInt(fb, v)
+	case duckdb.Interval:
+		return appendInterval(fb, v)
+	case time.Duration:
+		return appendDuration(fb, v)
+	case duckdb.Decimal:
+		if v.Value == nil {
+			fb.AppendNull()
+			return nil
+		}
+		return appendDecimal(fb, v.Value, int32(v.Scale), r.opts.decimalRounding)
 	case time.Time:
-		return appendTimeValue(fb, v)
+		return r.appendTime(fb, v)
+	case []interface{}:
+		switch b := fb.(type) {
+		case *array.ListBuilder, *array.LargeListBuilder, *array.FixedSizeListBuilder:
+			return r.appendListValue(b.(array.ListLikeBuilder), v)
+		case *array.StructBuilder:
+			return r.appendStructPositional(b, v)
+		case *array.MapBuilder:
+			if keys, items, ok := parallelMapArrays(reflect.ValueOf(v)); ok {
+				return r.appendParallelMap(b, keys, items)
+			}
+		}
+		return errors.New(errors.CodeInternal, "unexpected builder type for list value")
+	case map[string]interface{}:
+		switch b := fb.(type) {
+		case *array.StructBuilder:
+			return r.appendStructValue(b, v)
+		case *array.MapBuilder:
+			_, err := r.appendReflectedValue(b, v)
+			return err
+		}
+		return errors.New(errors.CodeInternal, "unexpected builder type for struct value")
 	default:
+		// Typed nested values, e.g. from newer driver versions
+		if handled, err := r.appendReflectedValue(fb, v); handled {
+			return err
+		}
 		// Try to convert to string
-		fb.(*array.StringBuilder).Append(toString(v))
+		return appendStringValue(fb, toString(v))
+	}
+
+	return nil
+}
+
+// appendJSONValue appends raw JSON text as returned by drivers that scan JSON
+// columns into json.RawMessage. String builders, including the storage of
+// the JSON extension type, receive the text unchanged.
+func appendJSONValue(fb array.Builder, raw json.RawMessage) error {
+	if raw == nil {
+		fb.AppendNull()
+		return nil
+	}
+	if b, ok := fb.(*array.BinaryBuilder); ok {
+		b.Append(raw)
+		return nil
+	}
+	return appendStringValue(fb, string(raw))
+}
+
+// jsonDocument is the scan destination of JSON columns. go-duckdb decodes
+// JSON into maps, slices and scalars, which no string destination accepts,
+// so the decoded value is serialized back to JSON text. A JSON null
+// arrives as nil and is kept as a null.
+type jsonDocument struct {
+	raw json.RawMessage
+}
+
+// Scan implements sql.Scanner.
+func (d *jsonDocument) Scan(src interface{}) error {
+	d.raw = nil
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case json.RawMessage:
+		d.raw = append(d.raw[:0], v...)
+		return nil
+	}
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("cannot encode %T value as JSON: %w", src, err)
+	}
+	d.raw = raw
+	return nil
+}
+
+// appendJSONDocument appends a nested value of a JSON type, such as an
+// element of a JSON[] column. Strings, json.RawMessage and []byte are taken
+// as JSON text and appended unchanged; other values are serialized.
+func appendJSONDocument(eb *array.ExtensionBuilder, value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return appendStringValue(eb, v)
+	case json.RawMessage:
+		return appendJSONValue(eb, v)
+	case []byte:
+		return appendJSONValue(eb, v)
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, errors.CodeInvalidRequest, "cannot encode %T value as JSON", value)
+	}
+	return appendStringValue(eb, string(text))
+}
+
+// appendDynamicInteger appends an integer of any width to the column's
+// integer builder. Values outside the builder's range are rejected rather
+// than wrapped; unsigned values are compared as uint64 so that those above
+// math.MaxInt64 are not mistaken for negative ones.
+func appendDynamicInteger(fb array.Builder, value interface{}) error {
+	rv := reflect.ValueOf(value)
+	var (
+		n      int64
+		u      uint64
+		signed = rv.CanInt()
+	)
+	if signed {
+		n = rv.Int()
+		u = uint64(n)
+	} else {
+		u = rv.Uint()
+		n = int64(u)
+	}
+
+	// fits reports whether the value lies within [lo, hi] of a signed type.
+	fits := func(lo, hi int64) bool {
+		if signed {
+			return n >= lo && n <= hi
+		}
+		return u <= uint64(hi)
+	}
+	// fitsUnsigned reports whether the value lies within [0, hi].
+	fitsUnsigned := func(hi uint64) bool {
+		if signed {
+			return n >= 0 && uint64(n) <= hi
+		}
+		return u <= hi
+	}
+
+	ok := true
+	switch b := fb.(type) {
+	case *array.Int8Builder:
+		if ok = fits(math.MinInt8, math.MaxInt8); ok {
+			b.Append(int8(n))
+		}
+	case *array.Int16Builder:
+		if ok = fits(math.MinInt16, math.MaxInt16); ok {
+			b.Append(int16(n))
+		}
+	case *array.Int32Builder:
+		if ok = fits(math.MinInt32, math.MaxInt32); ok {
+			b.Append(int32(n))
+		}
+	case *array.Int64Builder:
+		if ok = fits(math.MinInt64, math.MaxInt64); ok {
+			b.Append(n)
+		}
+	case *array.Uint8Builder:
+		if ok = fitsUnsigned(math.MaxUint8); ok {
+			b.Append(uint8(u))
+		}
+	case *array.Uint16Builder:
+		if ok = fitsUnsigned(math.MaxUint16); ok {
+			b.Append(uint16(u))
+		}
+	case *array.Uint32Builder:
+		if ok = fitsUnsigned(math.MaxUint32); ok {
+			b.Append(uint32(u))
+		}
+	case *array.Uint64Builder:
+		if ok = fitsUnsigned(math.MaxUint64); ok {
+			b.Append(u)
+		}
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for integer value", fb))
+	}
+	if !ok {
+		return errors.New(errors.CodeInternal, fmt.Sprintf("value %v overflows %s", value, fb.Type()))
 	}
+	return nil
+}
 
+// appendListValue appends a list value, including null elements, to a list,
+// large list or fixed-size list builder.
+func (r *BatchReader) appendListValue(lb array.ListLikeBuilder, values []interface{}) error {
+	if fb, ok := lb.(*array.FixedSizeListBuilder); ok {
+		if n := fb.Type().(*arrow.FixedSizeListType).Len(); int32(len(values)) != n {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("list of %d elements does not fit %s", len(values), fb.Type()))
+		}
+	}
+	lb.Append(true)
+	vb := lb.ValueBuilder()
+	for _, elem := range values {
+		if err := r.appendDynamicValue(vb, elem); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// formatValue formats a value of a column whose type is unknown as text.
+func formatValue(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
 // toString converts a value to string.
 func toString(v interface{}) string {
 	switch val := v.(type) {
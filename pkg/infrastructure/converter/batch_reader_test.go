@@ -0,0 +1,1825 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/marcboeker/go-duckdb/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// queryRows runs a query against an in-memory DuckDB database.
+func queryRows(t testing.TB, query string) *sql.Rows {
+	t.Helper()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestBatchReaderTimestampList(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT [TIMESTAMP '2020-01-01', NULL] AS ts")
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	field := reader.Schema().Field(0)
+	require.Equal(t, arrow.LIST, field.Type.ID())
+	assert.Equal(t, arrow.FixedWidthTypes.Timestamp_us, field.Type.(*arrow.ListType).Elem())
+
+	require.True(t, reader.Next())
+	rec := reader.Record()
+	defer rec.Release()
+
+	list := rec.Column(0).(*array.List)
+	require.Equal(t, 1, list.Len())
+	values := list.ListValues().(*array.Timestamp)
+	require.Equal(t, 2, values.Len())
+
+	want := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, want, values.Value(0).ToTime(arrow.Microsecond))
+	assert.True(t, values.IsNull(1))
+
+	assert.False(t, reader.Next())
+	assert.NoError(t, reader.Err())
+}
+
+func TestBatchReaderLeakCheck(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("reports leaked slice", func(t *testing.T) {
+		rows := queryRows(t, "SELECT * FROM range(3)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithLeakCheck())
+		require.NoError(t, err)
+
+		require.True(t, reader.Next())
+		leaked := reader.Record()
+		reader.Release()
+
+		require.Error(t, reader.Err())
+		assert.Contains(t, reader.Err().Error(), "1 record slice(s) still retained")
+		leaked.Release()
+	})
+
+	t.Run("released slices are not reported", func(t *testing.T) {
+		rows := queryRows(t, "SELECT * FROM range(3)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithLeakCheck())
+		require.NoError(t, err)
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		rec.Retain()
+		rec.Release()
+		rec.Release()
+		reader.Release()
+
+		assert.NoError(t, reader.Err())
+	})
+}
+
+func TestBatchReaderColumnRename(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("renames selected columns", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 1::INTEGER AS a, 'x' AS b, 2.5::DOUBLE AS c")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithColumnRename(map[string]string{"a": "id", "c": "score"}))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		schema := reader.Schema()
+		require.Equal(t, 3, schema.NumFields())
+		assert.Equal(t, "id", schema.Field(0).Name)
+		assert.Equal(t, "b", schema.Field(1).Name)
+		assert.Equal(t, "score", schema.Field(2).Name)
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, schema.Field(0).Type)
+		assert.Equal(t, arrow.PrimitiveTypes.Float64, schema.Field(2).Type)
+
+		typeName, ok := schema.Field(0).Metadata.GetValue("ARROW:FLIGHT:SQL:TYPE_NAME")
+		assert.True(t, ok)
+		assert.Equal(t, "INTEGER", typeName)
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, int32(1), rec.Column(0).(*array.Int32).Value(0))
+		assert.Equal(t, 2.5, rec.Column(2).(*array.Float64).Value(0))
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 1 AS a")
+		_, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithColumnRename(map[string]string{"missing": "x"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"missing"`)
+	})
+}
+
+func TestAppendValueGenericNull(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "u", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	r := &BatchReader{builder: builder}
+
+	inputs := [][]interface{}{
+		{&sql.Null[int64]{V: 42, Valid: true}, &sql.Null[string]{V: "hello", Valid: true}, &sql.Null[uint32]{V: 7, Valid: true}},
+		{&sql.Null[int64]{}, &sql.Null[string]{}, &sql.Null[uint32]{}},
+	}
+	for _, row := range inputs {
+		for col, v := range row {
+			require.NoError(t, r.appendValue(col, v))
+		}
+	}
+
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	ints := rec.Column(0).(*array.Int64)
+	assert.Equal(t, int64(42), ints.Value(0))
+	assert.True(t, ints.IsNull(1))
+
+	strs := rec.Column(1).(*array.String)
+	assert.Equal(t, "hello", strs.Value(0))
+	assert.True(t, strs.IsNull(1))
+
+	uints := rec.Column(2).(*array.Uint32)
+	assert.Equal(t, uint32(7), uints.Value(0))
+	assert.True(t, uints.IsNull(1))
+}
+
+func TestCreateScanDestNullableUnsigned(t *testing.T) {
+	assert.IsType(t, &sql.Null[int8]{}, createScanDest(arrow.Field{Type: arrow.PrimitiveTypes.Int8, Nullable: true}))
+	assert.IsType(t, &sql.Null[uint16]{}, createScanDest(arrow.Field{Type: arrow.PrimitiveTypes.Uint16, Nullable: true}))
+	assert.IsType(t, &sql.Null[uint64]{}, createScanDest(arrow.Field{Type: arrow.PrimitiveTypes.Uint64, Nullable: true}))
+}
+
+func TestBatchReaderUnifyIntegers(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("mixed widths", func(t *testing.T) {
+		rows := queryRows(t, `SELECT -1::TINYINT AS a, 2::SMALLINT AS b, 3::INTEGER AS c, 4::BIGINT AS d,
+			5::UTINYINT AS e, 6::USMALLINT AS f, 7::UINTEGER AS g, 8::UBIGINT AS h, 'x' AS s`)
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithUnifyIntegers())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		schema := reader.Schema()
+		for i := 0; i < 8; i++ {
+			assert.Equal(t, arrow.PrimitiveTypes.Int64, schema.Field(i).Type, schema.Field(i).Name)
+		}
+		assert.Equal(t, arrow.BinaryTypes.String, schema.Field(8).Type)
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		want := []int64{-1, 2, 3, 4, 5, 6, 7, 8}
+		for i, w := range want {
+			assert.Equal(t, w, rec.Column(i).(*array.Int64).Value(0))
+		}
+	})
+
+	t.Run("uint64 overflow", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 18446744073709551615::UBIGINT AS h")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithUnifyIntegers())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Next())
+		require.Error(t, reader.Err())
+		assert.Contains(t, reader.Err().Error(), "overflows int64")
+	})
+}
+
+func TestBatchReaderBooleanAsInt8(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = "SELECT * FROM (VALUES (true, 1), (false, 2), (NULL, 3)) t(b, i)"
+
+	t.Run("default", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.FixedWidthTypes.Boolean, reader.Schema().Field(0).Type)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithBooleanAsInt8())
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.PrimitiveTypes.Int8, reader.Schema().Field(0).Type)
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, reader.Schema().Field(1).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.Int8)
+		assert.Equal(t, int8(1), col.Value(0))
+		assert.Equal(t, int8(0), col.Value(1))
+		assert.True(t, col.IsNull(2))
+	})
+}
+
+func TestBatchReaderDateAsInt32(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = `SELECT * FROM (VALUES
+		(DATE '2024-03-01'),
+		(DATE '1969-12-31'),
+		(NULL)) t(d)`
+
+	// read returns the first column of the first record read with opts.
+	read := func(t *testing.T, opts ...Option) arrow.Array {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, opts...)
+		require.NoError(t, err)
+		defer reader.Release()
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0)
+		col.Retain()
+		t.Cleanup(col.Release)
+		return col
+	}
+
+	dates := read(t).(*array.Date32)
+	days, ok := read(t, WithDateAsInt32()).(*array.Int32)
+	require.True(t, ok)
+	for i := 0; i < dates.Len(); i++ {
+		assert.Equal(t, dates.IsNull(i), days.IsNull(i))
+		if dates.IsValid(i) {
+			assert.Equal(t, int32(dates.Value(i)), days.Value(i))
+		}
+	}
+	assert.Equal(t, int32(-1), days.Value(1))
+
+	t.Run("column time zone", func(t *testing.T) {
+		zone := WithColumnTimezone("d", "America/New_York")
+		dates := read(t, zone).(*array.Date32)
+		days := read(t, WithDateAsInt32(), zone).(*array.Int32)
+		assert.Equal(t, int32(19783), int32(dates.Value(0)))
+		assert.Equal(t, int32(19783), days.Value(0))
+	})
+}
+
+func TestBatchReaderRecordSlice(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewGoAllocator()
+
+	rows := queryRows(t, "SELECT i FROM range(10) t(i)")
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+
+	_, err = reader.RecordSlice(0, 1)
+	require.Error(t, err)
+
+	require.True(t, reader.Next())
+	slice, err := reader.RecordSlice(3, 4)
+	require.NoError(t, err)
+
+	// The slice must survive the reader moving on.
+	assert.False(t, reader.Next())
+	require.Equal(t, int64(4), slice.NumRows())
+	col := slice.Column(0).(*array.Int64)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, int64(i+3), col.Value(i))
+	}
+	slice.Release()
+
+	tests := []struct {
+		name           string
+		offset, length int64
+	}{
+		{name: "negative offset", offset: -1, length: 1},
+		{name: "negative length", offset: 0, length: -1},
+		{name: "offset past end", offset: 11, length: 0},
+		{name: "length past end", offset: 8, length: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := queryRows(t, "SELECT i FROM range(10) t(i)")
+			reader, err := NewBatchReader(alloc, rows, logger)
+			require.NoError(t, err)
+			defer reader.Release()
+			require.True(t, reader.Next())
+
+			_, err = reader.RecordSlice(tt.offset, tt.length)
+			assert.Error(t, err)
+		})
+	}
+
+	reader.Release()
+}
+
+func TestBatchReaderColumns(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT i, 'row ' || i AS label FROM range(5) t(i)")
+	reader, err := NewBatchReader(alloc, rows, logger, WithBatchSize(2))
+	require.NoError(t, err)
+	assert.Nil(t, reader.Columns())
+
+	var batches [][]arrow.Array
+	for reader.Next() {
+		cols := reader.Columns()
+		require.Len(t, cols, 2)
+		batches = append(batches, cols)
+	}
+	require.NoError(t, reader.Err())
+	reader.Release()
+
+	// Columns outlive the reader until released.
+	var ids []int64
+	for _, cols := range batches {
+		ids = append(ids, cols[0].(*array.Int64).Int64Values()...)
+		assert.Equal(t, cols[0].Len(), cols[1].Len())
+		for _, c := range cols {
+			c.Release()
+		}
+	}
+	assert.Equal(t, []int64{0, 1, 2, 3, 4}, ids)
+}
+
+func TestBatchReaderTimeStrings(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("default layouts", func(t *testing.T) {
+		// Declaring temporal types over VARCHAR results simulates drivers
+		// that return temporal values as strings.
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+			{Name: "tm", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+			{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		}, nil)
+		rows := queryRows(t, `SELECT * FROM (VALUES
+			('2020-01-01 12:34:56.123456', '12:34:56.5', '2020-01-02'),
+			(NULL, NULL, NULL)) t(ts, tm, d)`)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+
+		ts := rec.Column(0).(*array.Timestamp)
+		assert.Equal(t, time.Date(2020, 1, 1, 12, 34, 56, 123456000, time.UTC), ts.Value(0).ToTime(arrow.Microsecond))
+		assert.True(t, ts.IsNull(1))
+
+		tm := rec.Column(1).(*array.Time64)
+		assert.Equal(t, arrow.Time64((12*3600+34*60+56)*1000000+500000), tm.Value(0))
+		assert.True(t, tm.IsNull(1))
+
+		d := rec.Column(2).(*array.Date32)
+		assert.Equal(t, arrow.Date32FromTime(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), d.Value(0))
+		assert.True(t, d.IsNull(1))
+	})
+
+	t.Run("custom layouts", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+		}, nil)
+
+		rows := queryRows(t, "SELECT '01/02/2020 03:04' AS ts")
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+		require.NoError(t, err)
+		assert.False(t, reader.Next())
+		assert.Error(t, reader.Err())
+		reader.Release()
+
+		rows = queryRows(t, "SELECT '01/02/2020 03:04' AS ts")
+		reader, err = NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger,
+			WithTimeLayouts("01/02/2006 15:04"))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC),
+			rec.Column(0).(*array.Timestamp).Value(0).ToTime(arrow.Microsecond))
+	})
+}
+
+func TestBatchReaderEmptyRecord(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT 1::INTEGER AS id, 'x' AS name, [1, 2] AS tags WHERE false")
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	assert.False(t, reader.Next())
+	require.NoError(t, reader.Err())
+
+	rec := reader.EmptyRecord()
+	defer rec.Release()
+	assert.Equal(t, int64(0), rec.NumRows())
+	assert.Equal(t, int64(3), rec.NumCols())
+	assert.True(t, reader.Schema().Equal(rec.Schema()))
+}
+
+func TestBatchReaderTakeRecord(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT i, 'row ' || i AS label FROM range(5) t(i)")
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	reader.SetBatchSize(2)
+
+	var taken []arrow.Record
+	for reader.Next() {
+		rec, err := reader.TakeRecord()
+		require.NoError(t, err)
+		taken = append(taken, rec)
+
+		_, err = reader.TakeRecord()
+		assert.Error(t, err, "record can only be taken once")
+	}
+	require.NoError(t, reader.Err())
+	reader.Release()
+
+	// Taken records stay valid after the reader is gone.
+	var total int64
+	for _, rec := range taken {
+		total += rec.NumRows()
+		assert.Equal(t, int64(0), rec.Column(0).(*array.Int64).Value(0)%2)
+		rec.Release()
+	}
+	assert.Equal(t, int64(5), total)
+}
+
+func TestBatchReaderRecordOutlivesNext(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "recycling allocator", opts: []Option{WithRecyclingAllocator()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+
+			rows := queryRows(t, "SELECT i, 'row ' || i AS label, [i, i + 1] AS pair FROM range(6) t(i)")
+			reader, err := NewBatchReader(alloc, rows, logger, tc.opts...)
+			require.NoError(t, err)
+			reader.SetBatchSize(2)
+
+			require.True(t, reader.Next(), reader.Err())
+			first := reader.Record()
+			defer first.Release()
+			want := [][]string{
+				{first.Column(0).ValueStr(0), first.Column(0).ValueStr(1)},
+				{first.Column(1).ValueStr(0), first.Column(1).ValueStr(1)},
+				{first.Column(2).ValueStr(0), first.Column(2).ValueStr(1)},
+			}
+			assert.Equal(t, [][]string{{"0", "1"}, {"row 0", "row 1"}, {"[0,1]", "[1,2]"}}, want)
+
+			// Two more batches overwrite whatever the reader reuses, and
+			// releasing the reader mid-stream drops its own reference.
+			require.True(t, reader.Next(), reader.Err())
+			require.True(t, reader.Next(), reader.Err())
+			reader.Release()
+
+			require.Equal(t, int64(3), first.NumCols())
+			require.Equal(t, int64(2), first.NumRows())
+			for i, col := range first.Columns() {
+				require.NotNil(t, col)
+				assert.Equal(t, want[i], []string{col.ValueStr(0), col.ValueStr(1)})
+			}
+		})
+	}
+}
+
+func TestBatchReaderStructNulls(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		({'a': NULL, 'b': 2}),
+		(NULL),
+		({'a': 3, 'b': NULL})) t(s)`)
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	st, ok := reader.Schema().Field(0).Type.(*arrow.StructType)
+	require.True(t, ok)
+	require.Equal(t, 2, st.NumFields())
+	assert.Equal(t, "a", st.Field(0).Name)
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, st.Field(0).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	col := rec.Column(0).(*array.Struct)
+	require.Equal(t, 3, col.Len())
+	a := col.Field(0).(*array.Int32)
+	b := col.Field(1).(*array.Int32)
+	require.Equal(t, 3, a.Len())
+	require.Equal(t, 3, b.Len())
+
+	assert.True(t, col.IsValid(0))
+	assert.True(t, a.IsNull(0))
+	assert.Equal(t, int32(2), b.Value(0))
+
+	assert.True(t, col.IsNull(1))
+
+	assert.True(t, col.IsValid(2))
+	assert.Equal(t, int32(3), a.Value(2))
+	assert.True(t, b.IsNull(2))
+
+	assert.False(t, reader.Next())
+}
+
+func TestBatchReaderNullFill(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = "SELECT * FROM (VALUES (1, 'a'), (NULL, NULL), (3, 'c')) t(x, s)"
+
+	t.Run("fills nulls", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithNullFill(map[string]interface{}{"x": -1}))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Schema().Field(0).Nullable)
+		assert.True(t, reader.Schema().Field(1).Nullable)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+
+		x := rec.Column(0).(*array.Int32)
+		assert.Equal(t, 0, x.NullN())
+		assert.Equal(t, []int32{1, -1, 3}, x.Int32Values())
+		assert.True(t, rec.Column(1).IsNull(1))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rows := queryRows(t, `SELECT * FROM (VALUES ('{"a": 1}'::JSON), (NULL)) t(j)`)
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithNullFill(map[string]interface{}{"j": "{}"}))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Schema().Field(0).Nullable)
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+
+		j := rec.Column(0).(*extensions.JSONArray)
+		assert.Equal(t, 0, j.NullN())
+		assert.JSONEq(t, `{"a": 1}`, j.ValueStr(0))
+		assert.JSONEq(t, `{}`, j.ValueStr(1))
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithNullFill(map[string]interface{}{"x": "none"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match column type")
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithNullFill(map[string]interface{}{"x": int64(math.MaxInt64)}))
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchReaderBooleanList(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	rows := queryRows(t, "SELECT * FROM (VALUES ([true, false, NULL]), (NULL), ([])) t(flags)")
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	assert.Equal(t, arrow.ListOf(arrow.FixedWidthTypes.Boolean), reader.Schema().Field(0).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	list := rec.Column(0).(*array.List)
+	require.Equal(t, 3, list.Len())
+	assert.True(t, list.IsValid(0))
+	assert.True(t, list.IsNull(1))
+	assert.True(t, list.IsValid(2))
+
+	start, end := list.ValueOffsets(0)
+	assert.Equal(t, int64(3), end-start)
+	start, end = list.ValueOffsets(2)
+	assert.Equal(t, int64(0), end-start)
+
+	values := list.ListValues().(*array.Boolean)
+	require.Equal(t, 3, values.Len())
+	assert.True(t, values.Value(0))
+	assert.False(t, values.Value(1))
+	assert.True(t, values.IsNull(2))
+}
+
+func TestAppendValueFloat64ToFloat32(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "f", Type: arrow.PrimitiveTypes.Float32, Nullable: false},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	r := &BatchReader{builder: builder}
+
+	v := 1.5
+	require.NoError(t, r.appendValue(0, &v))
+
+	rec := builder.NewRecord()
+	defer rec.Release()
+	assert.Equal(t, float32(1.5), rec.Column(0).(*array.Float32).Value(0))
+}
+
+func TestBatchReaderNonNullableFloat(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "f", Type: arrow.PrimitiveTypes.Float32, Nullable: false},
+	}, nil)
+
+	rows := queryRows(t, "SELECT 2.25::FLOAT AS f")
+	reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	assert.Equal(t, float32(2.25), rec.Column(0).(*array.Float32).Value(0))
+}
+
+func TestBatchReaderTimestampTZ(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = "SELECT TIMESTAMPTZ '2020-01-01 00:00:00+02' AS ts"
+	// 2019-12-31T22:00:00Z
+	const wantMicros = int64(1577829600000000)
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		wantTZ string
+	}{
+		{name: "default utc", wantTZ: "UTC"},
+		{name: "session zone", opts: []Option{WithSessionTimeZone("America/New_York")}, wantTZ: "America/New_York"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, tt.opts...)
+			require.NoError(t, err)
+			defer reader.Release()
+
+			ts, ok := reader.Schema().Field(0).Type.(*arrow.TimestampType)
+			require.True(t, ok)
+			assert.Equal(t, arrow.Microsecond, ts.Unit)
+			assert.Equal(t, tt.wantTZ, ts.TimeZone)
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			defer rec.Release()
+			assert.Equal(t, arrow.Timestamp(wantMicros), rec.Column(0).(*array.Timestamp).Value(0))
+		})
+	}
+
+	t.Run("invalid zone", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithSessionTimeZone("Not/AZone"))
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchReaderDefaultTimestampZone(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(),
+		queryRows(t, "SELECT TIMESTAMPTZ '2020-01-01 00:00:00+02' AS ts"), logger,
+		WithDefaultTimestampZone("Europe/Berlin"))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	ts, ok := rec.Column(0).DataType().(*arrow.TimestampType)
+	require.True(t, ok)
+	assert.Equal(t, "Europe/Berlin", ts.TimeZone)
+	assert.Equal(t, arrow.Timestamp(1577829600000000), rec.Column(0).(*array.Timestamp).Value(0))
+}
+
+func TestBatchReaderSkipRows(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("middle of result", func(t *testing.T) {
+		rows := queryRows(t, "SELECT i FROM range(10) t(i) ORDER BY i")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithSkipRows(6))
+		require.NoError(t, err)
+		defer reader.Release()
+		reader.SetBatchSize(3)
+
+		var got []int64
+		for reader.Next() {
+			rec := reader.Record()
+			got = append(got, rec.Column(0).(*array.Int64).Int64Values()...)
+			rec.Release()
+		}
+		require.NoError(t, reader.Err())
+		assert.Equal(t, []int64{6, 7, 8, 9}, got)
+	})
+
+	t.Run("past end", func(t *testing.T) {
+		rows := queryRows(t, "SELECT i FROM range(10) t(i)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithSkipRows(20))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Next())
+		assert.NoError(t, reader.Err())
+	})
+}
+
+func TestBatchReaderLimitRows(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	rows := queryRows(t, "SELECT i FROM range(100) t(i) ORDER BY i")
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithSkipRows(2), WithLimitRows(7))
+	require.NoError(t, err)
+	defer reader.Release()
+	reader.SetBatchSize(3)
+
+	var sizes []int64
+	var got []int64
+	for i := 0; i < 3; i++ {
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		sizes = append(sizes, rec.NumRows())
+		got = append(got, rec.Column(0).(*array.Int64).Int64Values()...)
+		rec.Release()
+	}
+	assert.Equal(t, []int64{3, 3, 1}, sizes)
+	assert.Equal(t, []int64{2, 3, 4, 5, 6, 7, 8}, got)
+
+	// The result set is closed as soon as the limit is reached.
+	_, err = rows.Columns()
+	assert.Error(t, err)
+
+	assert.False(t, reader.Next())
+	assert.NoError(t, reader.Err())
+}
+
+func TestBatchReaderIntegerKeyMap(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		(MAP {2: 'two', 1: 'one'}),
+		(NULL),
+		(MAP {3: NULL})) t(m)`)
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	mt, ok := reader.Schema().Field(0).Type.(*arrow.MapType)
+	require.True(t, ok)
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, mt.KeyType())
+	assert.Equal(t, arrow.BinaryTypes.String, mt.ItemType())
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	col := rec.Column(0).(*array.Map)
+	require.Equal(t, 3, col.Len())
+	keys := col.Keys().(*array.Int32)
+	items := col.Items().(*array.String)
+
+	start, end := col.ValueOffsets(0)
+	require.Equal(t, int64(2), end-start)
+	assert.Equal(t, []int32{1, 2}, keys.Int32Values()[start:end])
+	assert.Equal(t, "one", items.Value(int(start)))
+	assert.Equal(t, "two", items.Value(int(start)+1))
+
+	assert.True(t, col.IsNull(1))
+
+	start, end = col.ValueOffsets(2)
+	require.Equal(t, int64(1), end-start)
+	assert.Equal(t, int32(3), keys.Value(int(start)))
+	assert.True(t, items.IsNull(int(start)))
+
+	assert.False(t, reader.Next())
+}
+
+func TestBatchReaderMapListNesting(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	tests := []struct {
+		name  string
+		query string
+		want  arrow.DataType
+		str   string
+	}{
+		{
+			name:  "map of lists",
+			query: `SELECT * FROM (VALUES (MAP {'a': [1, 2], 'b': [], 'c': NULL}), (NULL)) t(v)`,
+			want:  arrow.MapOf(arrow.BinaryTypes.String, arrow.ListOf(arrow.PrimitiveTypes.Int32)),
+			str:   `[{["a" "b" "c"] [[1 2] [] (null)]} (null)]`,
+		},
+		{
+			name:  "list of maps",
+			query: `SELECT * FROM (VALUES ([MAP {1: 'x', 2: 'y'}, NULL, MAP {}]), (NULL)) t(v)`,
+			want:  arrow.ListOf(arrow.MapOf(arrow.PrimitiveTypes.Int32, arrow.BinaryTypes.String)),
+			str:   `[[{[1 2] ["x" "y"]} (null) {[] []}] (null)]`,
+		},
+		{
+			name:  "list of maps of lists",
+			query: `SELECT [MAP {'k': [1, NULL]}] AS v`,
+			want: arrow.ListOf(arrow.MapOf(arrow.BinaryTypes.String,
+				arrow.ListOf(arrow.PrimitiveTypes.Int32))),
+			str: `[[{["k"] [[1 (null)]]}]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+
+			reader, err := NewBatchReader(alloc, queryRows(t, tt.query), logger)
+			require.NoError(t, err)
+			defer reader.Release()
+			assert.True(t, arrow.TypeEqual(tt.want, reader.Schema().Field(0).Type), "got %s", reader.Schema().Field(0).Type)
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			assert.Equal(t, tt.str, rec.Column(0).String())
+			rec.Release()
+			assert.False(t, reader.Next())
+			require.NoError(t, reader.Err())
+		})
+	}
+}
+
+func TestBatchReaderRecoversFromPanic(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	rows := queryRows(t, "SELECT 1 AS id, 'x' AS s")
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	// Declare the integer column as a string so that appending the scanned
+	// integer hits a bad builder type assertion.
+	reader.schema = arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.BinaryTypes.String, Nullable: true},
+		reader.schema.Field(1),
+	}, nil)
+	reader.builder.Release()
+	reader.builder = array.NewRecordBuilder(memory.NewGoAllocator(), reader.schema)
+
+	require.NotPanics(t, func() {
+		assert.False(t, reader.Next())
+	})
+	err = reader.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "panic while appending value for column 0")
+	assert.False(t, reader.Next())
+}
+
+func TestBatchReaderNestedDictionary(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		({'id': 1, 'category': 'red'}),
+		({'id': 2, 'category': 'blue'}),
+		({'id': 3, 'category': 'red'}),
+		({'id': 4, 'category': NULL})) t(s)`)
+	reader, err := NewBatchReader(alloc, rows, logger, WithDictionaryColumns("s.category"))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	st := reader.Schema().Field(0).Type.(*arrow.StructType)
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, st.Field(0).Type)
+	assert.Equal(t, dictionaryStringType, st.Field(1).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	col := rec.Column(0).(*array.Struct)
+	dict := col.Field(1).(*array.Dictionary)
+	values := dict.Dictionary().(*array.String)
+	assert.Equal(t, 2, values.Len())
+
+	var got []string
+	for i := 0; i < dict.Len(); i++ {
+		if dict.IsNull(i) {
+			got = append(got, "")
+			continue
+		}
+		got = append(got, values.Value(dict.GetValueIndex(i)))
+	}
+	assert.Equal(t, []string{"red", "blue", "red", ""}, got)
+	assert.True(t, dict.IsNull(3))
+
+	assert.False(t, reader.Next())
+}
+
+func TestBatchReaderDictionaryColumnsInvalid(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "unknown column", path: "missing"},
+		{name: "unknown struct field", path: "s.missing"},
+		{name: "non-string field", path: "s.id"},
+		{name: "path ends at struct", path: "s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := queryRows(t, "SELECT {'id': 1, 'category': 'red'} AS s")
+			_, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithDictionaryColumns(tt.path))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBatchReaderAnonymousRow(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	// go-duckdb cannot scan unnamed struct members, so anonymous structs are
+	// read from a driver reporting them as ordered member slices.
+	db := sql.OpenDB(fakeConnector{&fakeResult{
+		columns:   []string{"r"},
+		typeNames: []string{`STRUCT("" INTEGER, "" VARCHAR)`},
+		scanTypes: []reflect.Type{reflect.TypeOf([]interface{}{})},
+		rows: [][]driver.Value{
+			{[]interface{}{int32(1), "a"}},
+			{[]interface{}{nil, "b"}},
+			{[]interface{}{int32(1)}},
+		},
+	}})
+	defer db.Close()
+	rows, err := db.Query("SELECT r")
+	require.NoError(t, err)
+
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+	reader.SetBatchSize(2)
+
+	st, ok := reader.Schema().Field(0).Type.(*arrow.StructType)
+	require.True(t, ok)
+	require.Equal(t, 2, st.NumFields())
+	assert.Equal(t, "f0", st.Field(0).Name)
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, st.Field(0).Type)
+	assert.Equal(t, "f1", st.Field(1).Name)
+	assert.Equal(t, arrow.BinaryTypes.String, st.Field(1).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	col := rec.Column(0).(*array.Struct)
+	assert.Equal(t, int32(1), col.Field(0).(*array.Int32).Value(0))
+	assert.Equal(t, "a", col.Field(1).(*array.String).Value(0))
+	assert.True(t, col.Field(0).IsNull(1))
+	assert.Equal(t, "b", col.Field(1).(*array.String).Value(1))
+	rec.Release()
+
+	// A member count not matching the type is an error
+	assert.False(t, reader.Next())
+	assert.Error(t, reader.Err())
+
+	t.Run("duckdb", func(t *testing.T) {
+		// The schema is inferred, but the driver fails to read the values.
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT ROW(1, 'a') AS r"), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.STRUCT, reader.Schema().Field(0).Type.ID())
+		assert.False(t, reader.Next())
+		assert.Error(t, reader.Err())
+	})
+}
+
+func TestBatchReaderColumnTimezone(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "d64", Type: arrow.FixedWidthTypes.Date64, Nullable: true},
+	}, nil)
+	const query = "SELECT TIMESTAMP '2020-01-01 23:00:00' AS d, TIMESTAMP '2020-01-01 23:00:00' AS d64"
+
+	tests := []struct {
+		name string
+		opts []Option
+		want time.Time
+	}{
+		{name: "utc", want: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{
+			name: "plus two",
+			opts: []Option{WithColumnTimezone("d", "Etc/GMT-2"), WithColumnTimezone("d64", "Etc/GMT-2")},
+			want: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "minus five",
+			opts: []Option{WithColumnTimezone("d", "Etc/GMT+5"), WithColumnTimezone("d64", "Etc/GMT+5")},
+			want: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger, tt.opts...)
+			require.NoError(t, err)
+			defer reader.Release()
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			defer rec.Release()
+			assert.Equal(t, arrow.Date32FromTime(tt.want), rec.Column(0).(*array.Date32).Value(0))
+			assert.Equal(t, arrow.Date64FromTime(tt.want), rec.Column(1).(*array.Date64).Value(0))
+		})
+	}
+
+	t.Run("date column", func(t *testing.T) {
+		// Real dates have no time of day, so the zone must not move them.
+		const query = "SELECT DATE '2024-01-01' AS d"
+		for _, newReader := range []func(*sql.Rows) (*BatchReader, error){
+			func(rows *sql.Rows) (*BatchReader, error) {
+				return NewBatchReader(memory.NewGoAllocator(), rows, logger, WithColumnTimezone("d", "America/New_York"))
+			},
+			func(rows *sql.Rows) (*BatchReader, error) {
+				return NewBatchReaderWithSchema(memory.NewGoAllocator(), arrow.NewSchema(schema.Fields()[:1], nil), rows, logger,
+					WithColumnTimezone("d", "America/New_York"))
+			},
+		} {
+			reader, err := newReader(queryRows(t, query))
+			require.NoError(t, err)
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			assert.Equal(t, arrow.Date32(19723), rec.Column(0).(*array.Date32).Value(0))
+			rec.Release()
+			reader.Release()
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger,
+			WithColumnTimezone("missing", "UTC"))
+		assert.Error(t, err)
+		_, err = NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger,
+			WithColumnTimezone("d", "Not/AZone"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewBatchReaderWithSchemaClosesRowsOnError(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	schema := arrow.NewSchema([]arrow.Field{{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true}}, nil)
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "column time zone", opt: WithColumnTimezone("missing", "UTC")},
+		{name: "scan dest", opt: WithScanDest("missing", func() any { return new(string) }, nil)},
+		{name: "row number column", opt: WithRowNumberColumn("s")},
+		{name: "sorted by", opt: WithSortedBy([]string{"missing"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := queryRows(t, "SELECT 'a' AS s")
+			_, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger, WithBufferPool(), tt.opt)
+			require.Error(t, err)
+
+			_, err = rows.Columns()
+			assert.Error(t, err, "rows left open")
+		})
+	}
+}
+
+func TestBatchReaderErrorContext(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	rows := queryRows(t, "SELECT i, CASE WHEN i = 5 THEN 'bad' ELSE '2020-01-01' END AS d FROM range(8) t(i) ORDER BY i")
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+	}, nil)
+	reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+	reader.SetBatchSize(4)
+
+	require.True(t, reader.Next(), reader.Err())
+	reader.Record().Release()
+	// Row 4 precedes the failing row and is still returned.
+	require.True(t, reader.Next(), reader.Err())
+	reader.Record().Release()
+	require.False(t, reader.Next())
+
+	err = reader.Err()
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"column": "d", "row": "5"}, errors.Context(err))
+}
+
+func TestBatchReaderRollsBackFailedRow(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT i, [i, i] AS l, CASE WHEN i = 2 THEN 'bad' ELSE '2020-01-01' END AS d, 'x' AS s
+		FROM range(4) t(i) ORDER BY i`)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "l", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	reader, err := NewBatchReaderWithSchema(alloc, schema, rows, logger, WithRowNumberColumn("rn"))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	// Row 2 fails in column d after i and l have been appended.
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	assert.EqualValues(t, 2, rec.NumRows())
+	for i, col := range rec.Columns() {
+		assert.Equal(t, 2, col.Len(), "column %s", rec.ColumnName(i))
+	}
+	assert.Equal(t, []int64{0, 1}, rec.Column(0).(*array.Int64).Int64Values())
+	assert.Equal(t, `[[0 0] [1 1]]`, rec.Column(1).String())
+	rec.Release()
+
+	assert.False(t, reader.Next())
+	assert.Equal(t, map[string]string{"column": "d", "row": "2"}, errors.Context(reader.Err()))
+	assert.False(t, reader.Next())
+}
+
+func TestBatchReaderRowNumberColumn(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// collect reads all batches, returning the values of the row number
+	// column and the number of batches.
+	collect := func(t *testing.T, reader *BatchReader) ([]int64, int) {
+		t.Helper()
+		defer reader.Release()
+		reader.SetBatchSize(4)
+
+		field := reader.Schema().Field(reader.Schema().NumFields() - 1)
+		assert.Equal(t, "rn", field.Name)
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, field.Type)
+		assert.False(t, field.Nullable)
+
+		var got []int64
+		batches := 0
+		for reader.Next() {
+			rec := reader.Record()
+			got = append(got, rec.Column(int(rec.NumCols())-1).(*array.Int64).Int64Values()...)
+			rec.Release()
+			batches++
+		}
+		require.NoError(t, reader.Err())
+		return got, batches
+	}
+	want := []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	t.Run("generic path", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 'v' || i AS s FROM range(12) t(i)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithSkipRows(2), WithRowNumberColumn("rn"))
+		require.NoError(t, err)
+		got, batches := collect(t, reader)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 3, batches)
+	})
+
+	t.Run("fixed width path", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{{Name: "i", Type: arrow.PrimitiveTypes.Int64}}, nil)
+		rows := queryRows(t, "SELECT i FROM range(10) t(i)")
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger, WithRowNumberColumn("rn"))
+		require.NoError(t, err)
+		require.True(t, reader.fixedWidth)
+		got, batches := collect(t, reader)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 3, batches)
+	})
+
+	t.Run("name clash", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 1 AS rn")
+		_, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithRowNumberColumn("rn"))
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchReaderMaxRecordRows(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	for _, tt := range []struct {
+		name   string
+		query  string
+		schema *arrow.Schema
+	}{
+		{name: "generic path", query: "SELECT i, 'v' || i AS s FROM range(2500) t(i)"},
+		{
+			name:   "fixed width path",
+			query:  "SELECT i FROM range(2500) t(i)",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "i", Type: arrow.PrimitiveTypes.Int64}}, nil),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+
+			var (
+				reader *BatchReader
+				err    error
+			)
+			opts := []Option{WithBatchSize(1000), WithMaxRecordRows(300), WithRowNumberColumn("rn")}
+			if tt.schema != nil {
+				reader, err = NewBatchReaderWithSchema(alloc, tt.schema, queryRows(t, tt.query), logger, opts...)
+			} else {
+				reader, err = NewBatchReader(alloc, queryRows(t, tt.query), logger, opts...)
+			}
+			require.NoError(t, err)
+			defer reader.Release()
+
+			var sizes []int64
+			next := int64(0)
+			for reader.Next() {
+				rec := reader.Record()
+				assert.LessOrEqual(t, rec.NumRows(), int64(300))
+				sizes = append(sizes, rec.NumRows())
+				for _, rn := range rec.Column(int(rec.NumCols()) - 1).(*array.Int64).Int64Values() {
+					assert.Equal(t, next, rn)
+					next++
+				}
+				rec.Release()
+			}
+			require.NoError(t, reader.Err())
+			assert.Equal(t, []int64{300, 300, 300, 100, 300, 300, 300, 100, 300, 200}, sizes)
+		})
+	}
+}
+
+func TestBatchReaderSingleBatch(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	for _, tt := range []struct {
+		name   string
+		query  string
+		schema *arrow.Schema
+	}{
+		{name: "generic path", query: "SELECT i, 'v' || i AS s FROM range(500) t(i)"},
+		{
+			name:   "fixed width schema",
+			query:  "SELECT i FROM range(500) t(i)",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "i", Type: arrow.PrimitiveTypes.Int64}}, nil),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+
+			var (
+				reader *BatchReader
+				err    error
+			)
+			opts := []Option{WithBatchSize(64), WithSingleBatch()}
+			if tt.schema != nil {
+				reader, err = NewBatchReaderWithSchema(alloc, tt.schema, queryRows(t, tt.query), logger, opts...)
+			} else {
+				reader, err = NewBatchReader(alloc, queryRows(t, tt.query), logger, opts...)
+			}
+			require.NoError(t, err)
+			defer reader.Release()
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			defer rec.Release()
+			assert.Equal(t, int64(500), rec.NumRows())
+			assert.Equal(t, int64(499), rec.Column(0).(*array.Int64).Value(499))
+
+			assert.False(t, reader.Next())
+			require.NoError(t, reader.Err())
+		})
+	}
+
+	t.Run("cap", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("reads over a million rows")
+		}
+		reader, err := NewBatchReader(memory.NewGoAllocator(),
+			queryRows(t, fmt.Sprintf("SELECT i FROM range(%d) t(i)", maxSingleBatchRows+1)), logger, WithSingleBatch())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Next())
+		assert.Equal(t, errors.CodeResourceExhausted, errors.GetCode(reader.Err()))
+	})
+}
+
+func TestBatchReaderFinishRecord(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc, queryRows(t, "SELECT 1::BIGINT AS id, 'a' AS s"), logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	ids := reader.builder.Field(0).(*array.Int64Builder)
+	strs := reader.builder.Field(1).(*array.StringBuilder)
+	ids.AppendValues([]int64{10, 20}, nil)
+	strs.AppendValues([]string{"x", "y"}, nil)
+
+	rec := reader.FinishRecord()
+	assert.Equal(t, int64(2), rec.NumRows())
+	assert.Equal(t, []int64{10, 20}, rec.Column(0).(*array.Int64).Int64Values())
+	assert.Equal(t, "y", rec.Column(1).(*array.String).Value(1))
+	rec.Release()
+
+	// The builder is reused, and a row missing from a column is dropped
+	assert.Zero(t, ids.Len())
+	ids.AppendValues([]int64{30, 40}, nil)
+	strs.Append("z")
+	rec = reader.FinishRecord()
+	assert.Equal(t, int64(1), rec.NumRows())
+	assert.Equal(t, []int64{30}, rec.Column(0).(*array.Int64).Int64Values())
+	assert.Equal(t, "z", rec.Column(1).(*array.String).Value(0))
+	rec.Release()
+	assert.Zero(t, ids.Len())
+	assert.Zero(t, strs.Len())
+}
+
+func TestBatchReaderUnsignedEdgeValues(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = "SELECT * FROM (VALUES (255::UTINYINT, 65535::USMALLINT), (0::UTINYINT, 0::USMALLINT)) t(u8, u16)"
+
+	read := func(t *testing.T, reader *BatchReader) ([]uint8, []uint16) {
+		defer reader.Release()
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		return rec.Column(0).(*array.Uint8).Uint8Values(), rec.Column(1).(*array.Uint16).Uint16Values()
+	}
+
+	t.Run("nullable", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger)
+		require.NoError(t, err)
+		require.True(t, reader.Schema().Field(0).Nullable)
+		u8, u16 := read(t, reader)
+		assert.Equal(t, []uint8{255, 0}, u8)
+		assert.Equal(t, []uint16{65535, 0}, u16)
+	})
+
+	t.Run("non-nullable", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "u8", Type: arrow.PrimitiveTypes.Uint8},
+			{Name: "u16", Type: arrow.PrimitiveTypes.Uint16},
+		}, nil)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger)
+		require.NoError(t, err)
+		u8, u16 := read(t, reader)
+		assert.Equal(t, []uint8{255, 0}, u8)
+		assert.Equal(t, []uint16{65535, 0}, u16)
+	})
+
+	t.Run("pointer destinations", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "u8", Type: arrow.PrimitiveTypes.Uint8, Nullable: true},
+			{Name: "u16", Type: arrow.PrimitiveTypes.Uint16, Nullable: true},
+		}, nil)
+		reader := &BatchReader{builder: array.NewRecordBuilder(memory.NewGoAllocator(), schema)}
+		defer reader.builder.Release()
+
+		u8, u16 := uint8(255), uint16(65535)
+		pu8, pu16 := &u8, &u16
+		require.NoError(t, reader.appendValue(0, &pu8))
+		require.NoError(t, reader.appendValue(1, &pu16))
+		pu8, pu16 = nil, nil
+		require.NoError(t, reader.appendValue(0, &pu8))
+		require.NoError(t, reader.appendValue(1, &pu16))
+		assert.True(t, isNullScan(&pu16))
+
+		rec := reader.builder.NewRecord()
+		defer rec.Release()
+		assert.Equal(t, uint8(255), rec.Column(0).(*array.Uint8).Value(0))
+		assert.Equal(t, uint16(65535), rec.Column(1).(*array.Uint16).Value(0))
+		assert.True(t, rec.Column(0).IsNull(1))
+		assert.True(t, rec.Column(1).IsNull(1))
+
+		b := array.NewInt64Builder(memory.NewGoAllocator())
+		defer b.Release()
+		pu16 = &u16
+		ok, err := appendWidenedInteger(b, &pu16)
+		require.True(t, ok)
+		require.NoError(t, err)
+		arr := b.NewInt64Array()
+		defer arr.Release()
+		assert.Equal(t, int64(65535), arr.Value(0))
+	})
+}
+
+func TestBatchReaderLargeUint64(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const (
+		above = uint64(1)<<63 + 1
+		most  = uint64(math.MaxUint64)
+	)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		(9223372036854775809::UBIGINT, [9223372036854775809, 18446744073709551615]::UBIGINT[]),
+		(18446744073709551615::UBIGINT, NULL),
+		(NULL, [0]::UBIGINT[])) t(u, l)`)
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next())
+	rec := reader.Record()
+	defer rec.Release()
+	u := rec.Column(0).(*array.Uint64)
+	assert.Equal(t, []uint64{above, most}, u.Uint64Values()[:2])
+	assert.True(t, u.IsNull(2))
+	l := rec.Column(1).(*array.List)
+	assert.Equal(t, []uint64{above, most, 0}, l.ListValues().(*array.Uint64).Uint64Values())
+
+	t.Run("coercion", func(t *testing.T) {
+		for _, tc := range []struct {
+			dt    arrow.DataType
+			value interface{}
+			ok    bool
+		}{
+			{arrow.PrimitiveTypes.Uint64, above, true},
+			{arrow.PrimitiveTypes.Uint64, most, true},
+			{arrow.PrimitiveTypes.Int64, uint64(math.MaxInt64), true},
+			{arrow.PrimitiveTypes.Int64, above, false},
+			{arrow.PrimitiveTypes.Int64, most, false},
+			{arrow.PrimitiveTypes.Int8, above, false},
+			{arrow.PrimitiveTypes.Uint32, above, false},
+			{arrow.PrimitiveTypes.Uint64, int64(-1), false},
+			{arrow.PrimitiveTypes.Uint8, int16(256), false},
+			{arrow.PrimitiveTypes.Int16, int64(math.MinInt16), true},
+		} {
+			b := array.NewBuilder(memory.NewGoAllocator(), tc.dt)
+			err := appendDynamicInteger(b, tc.value)
+			if tc.ok {
+				assert.NoError(t, err, "%v into %s", tc.value, tc.dt)
+				assert.Equal(t, 1, b.Len())
+			} else {
+				assert.ErrorContains(t, err, "overflows "+tc.dt.String(), "%v into %s", tc.value, tc.dt)
+				assert.Equal(t, 0, b.Len())
+			}
+			b.Release()
+		}
+	})
+}
+
+func TestBatchReaderMidnightTime(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("query", func(t *testing.T) {
+		rows := queryRows(t, "SELECT * FROM (VALUES (1, '00:00:00'::TIME), (2, NULL), (3, '00:00:01'::TIME)) t(id, tm)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		tm := rec.Column(1).(*array.Time32)
+		assert.True(t, tm.IsValid(0))
+		assert.Equal(t, arrow.Time32(0), tm.Value(0))
+		assert.True(t, tm.IsNull(1))
+		assert.Equal(t, arrow.Time32(1), tm.Value(2))
+	})
+
+	t.Run("scan destinations", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "tm", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+		}, nil)
+		reader := &BatchReader{builder: array.NewRecordBuilder(memory.NewGoAllocator(), schema)}
+		defer reader.builder.Release()
+
+		// The zero time.Time is a valid midnight, only Valid marks a null
+		midnight := time.Time{}
+		for _, v := range []interface{}{
+			&sql.NullTime{Time: midnight, Valid: true},
+			&sql.NullTime{},
+			&timeOrString{Time: midnight, Valid: true},
+			&timeOrString{},
+			&midnight,
+		} {
+			require.NoError(t, reader.appendValue(0, v))
+		}
+		assert.False(t, isNullScan(&sql.NullTime{Valid: true}))
+		assert.True(t, isNullScan(&sql.NullTime{}))
+
+		rec := reader.builder.NewRecord()
+		defer rec.Release()
+		tm := rec.Column(0).(*array.Time64)
+		for i, null := range []bool{false, true, false, true, false} {
+			assert.Equal(t, null, tm.IsNull(i), "row %d", i)
+			if !null {
+				assert.Equal(t, arrow.Time64(0), tm.Value(i), "row %d", i)
+			}
+		}
+	})
+}
+
+func TestBatchReaderBlobWithNulBytes(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = `SELECT * FROM (VALUES
+		('\x00a\x00\xFF'::BLOB, ''::BLOB, ['\x00'::BLOB, NULL, '\x00\x00b'::BLOB], {'b': '\x00z\x00'::BLOB}),
+		(NULL, '\x00'::BLOB, [], NULL)) t(b, e, l, s)`
+	want := [][]interface{}{
+		{[]byte("\x00a\x00\xff"), []byte{}, []interface{}{[]byte("\x00"), nil, []byte("\x00\x00b")}, map[string]interface{}{"b": []byte("\x00z\x00")}},
+		{nil, []byte("\x00"), []interface{}{}, nil},
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"buffer pool", []Option{WithBufferPool()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, tc.opts...)
+			require.NoError(t, err)
+			defer reader.Release()
+			assert.Equal(t, arrow.BINARY, reader.Schema().Field(0).Type.ID())
+
+			require.True(t, reader.Next())
+			rec := reader.Record()
+			defer rec.Release()
+			b := rec.Column(0).(*array.Binary)
+			assert.Equal(t, []byte("\x00a\x00\xff"), b.Value(0))
+			assert.True(t, b.IsNull(1))
+			// An empty BLOB is not a null one
+			e := rec.Column(1).(*array.Binary)
+			assert.True(t, e.IsValid(0))
+			assert.Empty(t, e.Value(0))
+
+			got, err := RecordToRows(rec)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("dynamic values", func(t *testing.T) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "b", Type: arrow.BinaryTypes.Binary, Nullable: true},
+			{Name: "l", Type: arrow.ListOf(arrow.BinaryTypes.Binary), Nullable: true},
+		}, nil)
+		reader := &BatchReader{builder: array.NewRecordBuilder(memory.NewGoAllocator(), schema)}
+		defer reader.builder.Release()
+
+		require.NoError(t, reader.appendDynamicValue(reader.builder.Field(0), []byte("\x00\x01\x00")))
+		require.NoError(t, reader.appendDynamicValue(reader.builder.Field(1), []interface{}{[]byte("x\x00"), nil}))
+		rec := reader.builder.NewRecord()
+		defer rec.Release()
+		assert.Equal(t, []byte("\x00\x01\x00"), rec.Column(0).(*array.Binary).Value(0))
+		l := rec.Column(1).(*array.List).ListValues().(*array.Binary)
+		assert.Equal(t, []byte("x\x00"), l.Value(0))
+		assert.True(t, l.IsNull(1))
+	})
+}
+
+func TestBatchReaderFloatRounding(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "f", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+	}, nil)
+	// 0.1 has no exact float32 representation; the others do
+	const query = "SELECT * FROM (VALUES (1, 0.5::DOUBLE), (2, NULL), (3, 'NaN'::DOUBLE), (4, 0.1::DOUBLE)) t(id, f)"
+
+	t.Run("nearest", func(t *testing.T) {
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		f := rec.Column(1).(*array.Float32)
+		assert.Equal(t, float32(0.5), f.Value(0))
+		assert.True(t, f.IsNull(1))
+		assert.True(t, math.IsNaN(float64(f.Value(2))))
+		assert.Equal(t, float32(0.1), f.Value(3))
+	})
+
+	t.Run("error on loss", func(t *testing.T) {
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger,
+			WithFloatRounding(FloatRoundError))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		// The rows before the inexact value are still returned
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		assert.Equal(t, int64(3), rec.NumRows())
+		rec.Release()
+
+		assert.False(t, reader.Next())
+		require.Error(t, reader.Err())
+		assert.Contains(t, reader.Err().Error(), "0.1 is not exactly representable as float32")
+		assert.Equal(t, map[string]string{"column": "f", "row": "3"}, errors.Context(reader.Err()))
+	})
+}
+
+func TestBatchReaderTimestampEpoch(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	epoch := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	const query = `SELECT TIMESTAMP '1900-01-02 00:00:01' AS a, TIMESTAMP '1970-01-01' AS b,
+		NULL::TIMESTAMP AS c, DATE '1970-01-02' AS d`
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithTimestampEpoch(epoch))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	const day = int64(24 * time.Hour / time.Microsecond)
+	assert.Equal(t, arrow.Timestamp(day+1_000_000), rec.Column(0).(*array.Timestamp).Value(0))
+	// 25567 days separate 1900-01-01 from the Unix epoch
+	assert.Equal(t, arrow.Timestamp(25567*day), rec.Column(1).(*array.Timestamp).Value(0))
+	assert.True(t, rec.Column(2).IsNull(0))
+	assert.Equal(t, arrow.Date32(1), rec.Column(3).(*array.Date32).Value(0))
+
+	t.Run("out of range", func(t *testing.T) {
+		// 300 years of nanoseconds do not fit in an int64
+		schema := arrow.NewSchema([]arrow.Field{{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_ns}}, nil)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema,
+			queryRows(t, "SELECT TIMESTAMP '2200-01-01' AS ts"), logger, WithTimestampEpoch(epoch))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "out of range from epoch")
+	})
+}
+
+// TestBatchReaderScanCoverage reads a value of every Arrow type the reader
+// supports, nullable or not, through the destination createScanDest
+// chooses for it, so that no combination ends in an unsupported scan type.
+func TestBatchReaderScanCoverage(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t)).Level(zerolog.InfoLevel)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 1, 12, 30, 15, 250000000, time.UTC)
+	clock := time.Date(1, 1, 1, 12, 30, 15, 250000000, time.UTC)
+
+	tests := []struct {
+		dt    arrow.DataType
+		value driver.Value
+		want  string
+	}{
+		{arrow.Null, nil, "(null)"},
+		{arrow.FixedWidthTypes.Boolean, true, "true"},
+		{arrow.PrimitiveTypes.Int8, int8(-8), "-8"},
+		{arrow.PrimitiveTypes.Int16, int16(-16), "-16"},
+		{arrow.PrimitiveTypes.Int32, int32(-32), "-32"},
+		{arrow.PrimitiveTypes.Int64, int64(-64), "-64"},
+		{arrow.PrimitiveTypes.Uint8, uint8(8), "8"},
+		{arrow.PrimitiveTypes.Uint16, uint16(16), "16"},
+		{arrow.PrimitiveTypes.Uint32, uint32(32), "32"},
+		{arrow.PrimitiveTypes.Uint64, uint64(math.MaxUint64), "18446744073709551615"},
+		{arrow.FixedWidthTypes.Float16, float32(1.5), "1.5"},
+		{arrow.PrimitiveTypes.Float32, float32(1.5), "1.5"},
+		{arrow.PrimitiveTypes.Float64, 2.5, "2.5"},
+		{arrow.BinaryTypes.String, "s", "s"},
+		{arrow.BinaryTypes.LargeString, "s", "s"},
+		{arrow.BinaryTypes.StringView, "s", "s"},
+		{arrow.BinaryTypes.Binary, []byte{0, 1}, "AAE="},
+		{arrow.BinaryTypes.LargeBinary, []byte{0, 1}, "AAE="},
+		{arrow.BinaryTypes.BinaryView, []byte{0, 1}, "AAE="},
+		{&arrow.FixedSizeBinaryType{ByteWidth: 2}, []byte{0, 1}, "AAE="},
+		{arrow.FixedWidthTypes.Date32, day, "2024-03-01"},
+		{arrow.FixedWidthTypes.Date64, day, "2024-03-01"},
+		{arrow.FixedWidthTypes.Time32s, clock, "12:30:15"},
+		{arrow.FixedWidthTypes.Time32ms, clock, "12:30:15.250"},
+		{arrow.FixedWidthTypes.Time64us, clock, "12:30:15.250000"},
+		{arrow.FixedWidthTypes.Time64ns, clock, "12:30:15.250000000"},
+		{arrow.FixedWidthTypes.Timestamp_us, at, "2024-03-01 12:30:15.25Z"},
+		{arrow.FixedWidthTypes.Duration_us, 1500 * time.Millisecond, "1500000us"},
+		{arrow.FixedWidthTypes.MonthInterval, duckdb.Interval{Months: 14}, "14"},
+		{arrow.FixedWidthTypes.DayTimeInterval, duckdb.Interval{Days: 2, Micros: 3000}, `{"days":2,"milliseconds":3}`},
+		{arrow.FixedWidthTypes.MonthDayNanoInterval, duckdb.Interval{Months: 1, Days: 2, Micros: 3}, `{"months":1,"days":2,"nanoseconds":3000}`},
+		{&arrow.Decimal32Type{Precision: 5, Scale: 2}, duckdb.Decimal{Width: 5, Scale: 2, Value: big.NewInt(125)}, "1.25"},
+		{&arrow.Decimal64Type{Precision: 12, Scale: 2}, duckdb.Decimal{Width: 12, Scale: 2, Value: big.NewInt(125)}, "1.25"},
+		{&arrow.Decimal128Type{Precision: 20, Scale: 2}, duckdb.Decimal{Width: 20, Scale: 2, Value: big.NewInt(125)}, "1.25"},
+		{&arrow.Decimal256Type{Precision: 40, Scale: 2}, duckdb.Decimal{Width: 40, Scale: 2, Value: big.NewInt(125)}, "1.25"},
+		{arrow.ListOf(arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.LargeListOf(arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true}),
+			map[string]interface{}{"a": int32(1)}, `{"a":1}`},
+		{arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32), duckdb.Map{"k": int32(1)}, `[{"key":"k","value":1}]`},
+		{&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}, "s", "s"},
+		{extensions.NewUUIDType(), []byte("0123456789abcdef"), "30313233-3435-3637-3839-616263646566"},
+	}
+
+	// Unions, run-end encoding and list views have no DuckDB counterpart.
+	unsupported := map[arrow.Type]bool{
+		arrow.SPARSE_UNION: true, arrow.DENSE_UNION: true, arrow.RUN_END_ENCODED: true,
+		arrow.LIST_VIEW: true, arrow.LARGE_LIST_VIEW: true,
+	}
+	covered := make(map[arrow.Type]bool)
+	for _, tt := range tests {
+		covered[tt.dt.ID()] = true
+	}
+	for id := arrow.NULL; id <= arrow.DECIMAL64; id++ {
+		assert.True(t, covered[id] || unsupported[id], "type %s is not covered", id)
+	}
+
+	for _, tt := range tests {
+		for _, nullable := range []bool{false, true} {
+			if tt.dt.ID() == arrow.NULL && !nullable {
+				continue
+			}
+			name := tt.dt.String()
+			if nullable {
+				name += " nullable"
+			}
+			t.Run(name, func(t *testing.T) {
+				result := &fakeResult{
+					columns:   []string{"v"},
+					typeNames: []string{""},
+					scanTypes: []reflect.Type{reflect.TypeOf((*interface{})(nil)).Elem()},
+					rows:      [][]driver.Value{{tt.value}},
+				}
+				if nullable {
+					result.rows = append(result.rows, []driver.Value{nil})
+				}
+				db := sql.OpenDB(fakeConnector{result})
+				defer db.Close()
+				rows, err := db.Query("SELECT v")
+				require.NoError(t, err)
+
+				schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: tt.dt, Nullable: nullable}}, nil)
+				reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+				require.NoError(t, err)
+				defer reader.Release()
+
+				require.True(t, reader.Next(), "%v", reader.Err())
+				rec := reader.Record()
+				defer rec.Release()
+				require.Equal(t, int64(len(result.rows)), rec.NumRows())
+				assert.Equal(t, tt.want, rec.Column(0).ValueStr(0))
+				nulls := 0
+				for _, row := range result.rows {
+					if row[0] == nil {
+						nulls++
+					}
+				}
+				assert.Equal(t, nulls, rec.Column(0).NullN())
+			})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// parseBoolString parses the usual spellings of a boolean, ignoring case
+// and surrounding space. It reports false if s is none of them.
+func parseBoolString(s string) (value, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "t", "true", "y", "yes", "on", "1":
+		return true, true
+	case "f", "false", "n", "no", "off", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// appendBoolString appends a boolean the driver returned as a string to
+// the builder of column colIdx, or of a nested value if colIdx is -1. An
+// unrecognized spelling is an error with WithStrictBooleans or in a
+// non-nullable column, and a null otherwise, logged once per column.
+func (r *BatchReader) appendBoolString(colIdx int, b *array.BooleanBuilder, s string) error {
+	v, ok := parseBoolString(s)
+	if ok {
+		b.Append(v)
+		return nil
+	}
+	if r.opts.strictBooleans {
+		return errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot parse %q as a boolean", s))
+	}
+	if colIdx >= 0 && !r.schema.Field(colIdx).Nullable {
+		return errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("cannot parse %q as a boolean for non-nullable column %q", s, r.schema.Field(colIdx).Name))
+	}
+
+	if !r.boolWarned[colIdx] {
+		if r.boolWarned == nil {
+			r.boolWarned = make(map[int]bool)
+		}
+		r.boolWarned[colIdx] = true
+		ev := r.logger.Warn().Str("value", s)
+		if colIdx >= 0 {
+			ev = ev.Str("column", r.schema.Field(colIdx).Name)
+		}
+		ev.Msg("BatchReader: appending nulls in place of unrecognized booleans")
+	}
+	b.AppendNull()
+	return nil
+}
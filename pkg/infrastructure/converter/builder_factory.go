@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// BuilderFactory creates the builder of a field. It may return nil to use
+// DefaultBuilderFactory.
+type BuilderFactory func(field arrow.Field, mem memory.Allocator) array.Builder
+
+// DefaultBuilderFactory creates the builder Arrow provides for the field's
+// type, which for extension types is either the type's own builder or a
+// plain *array.ExtensionBuilder.
+func DefaultBuilderFactory(field arrow.Field, mem memory.Allocator) array.Builder {
+	return array.NewBuilder(mem, field.Type)
+}
+
+// ValueAppender may be implemented by the builders of a BuilderFactory to
+// receive each non-null value as the driver returned it, instead of the
+// reader's own conversion.
+type ValueAppender interface {
+	AppendValue(v any) error
+}
+
+// factoryType stands in for an extension field's type in the schema given
+// to the RecordBuilder, so that the builder comes from a BuilderFactory.
+// The arrays built keep the field's own type.
+type factoryType struct {
+	arrow.ExtensionType
+	field   arrow.Field
+	factory BuilderFactory
+}
+
+// NewBuilder implements array.CustomExtensionBuilder.
+func (t *factoryType) NewBuilder(mem memory.Allocator) array.Builder {
+	if b := t.factory(t.field, mem); b != nil {
+		return b
+	}
+	return DefaultBuilderFactory(t.field, mem)
+}
+
+// ExtensionEquals compares the wrapped types, so that records built with
+// the stand-in validate against the field's own type.
+func (t *factoryType) ExtensionEquals(other arrow.ExtensionType) bool {
+	if o, ok := other.(*factoryType); ok {
+		other = o.ExtensionType
+	}
+	return t.ExtensionType.ExtensionEquals(other)
+}
+
+// builderSchema returns the schema to create the record builder of schema
+// with, in which the top-level extension fields take their builders from
+// factory. It returns schema itself if factory is nil.
+func builderSchema(schema *arrow.Schema, factory BuilderFactory) *arrow.Schema {
+	if factory == nil {
+		return schema
+	}
+	fields := schema.Fields()
+	for i, f := range fields {
+		if ext, ok := f.Type.(arrow.ExtensionType); ok {
+			fields[i].Type = &factoryType{ExtensionType: ext, field: f, factory: factory}
+		}
+	}
+	md := schema.Metadata()
+	return arrow.NewSchema(fields, &md)
+}
+
+// newRecordBuilder creates the record builder of the reader's schema.
+func (r *BatchReader) newRecordBuilder() *array.RecordBuilder {
+	return array.NewRecordBuilder(r.allocator, builderSchema(r.schema, r.opts.builderFactory))
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codeType is an extension type over strings holding upper-case codes.
+type codeType struct {
+	arrow.ExtensionBase
+}
+
+func newCodeType() *codeType {
+	return &codeType{ExtensionBase: arrow.ExtensionBase{Storage: arrow.BinaryTypes.String}}
+}
+
+func (*codeType) ArrayType() reflect.Type { return reflect.TypeOf(codeArray{}) }
+func (*codeType) ExtensionName() string   { return "test.code" }
+func (*codeType) Serialize() string       { return "" }
+func (t *codeType) ExtensionEquals(o arrow.ExtensionType) bool {
+	return o.ExtensionName() == t.ExtensionName()
+}
+func (t *codeType) Deserialize(arrow.DataType, string) (arrow.ExtensionType, error) {
+	return t, nil
+}
+
+type codeArray struct {
+	array.ExtensionArrayBase
+}
+
+// codeBuilder upper-cases the values appended to it.
+type codeBuilder struct {
+	*array.ExtensionBuilder
+}
+
+func (b codeBuilder) AppendValue(v any) error {
+	b.StorageBuilder().(*array.StringBuilder).Append(strings.ToUpper(v.(string)))
+	return nil
+}
+
+func TestBatchReaderBuilderFactory(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	db := sql.OpenDB(fakeConnector{&fakeResult{
+		columns:   []string{"id", "code"},
+		typeNames: []string{"INTEGER", "VARCHAR"},
+		scanTypes: []reflect.Type{reflect.TypeOf(int32(0)), reflect.TypeOf("")},
+		rows:      [][]driver.Value{{int32(1), "abc"}, {int32(2), nil}},
+	}})
+	defer db.Close()
+	rows, err := db.Query("SELECT id, code")
+	require.NoError(t, err)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "code", Type: newCodeType(), Nullable: true},
+	}, nil)
+
+	var built []string
+	reader, err := NewBatchReaderWithSchema(alloc, schema, rows, zerolog.New(zerolog.NewTestWriter(t)),
+		WithBuilderFactory(func(field arrow.Field, mem memory.Allocator) array.Builder {
+			built = append(built, field.Name)
+			if field.Type.(arrow.ExtensionType).ExtensionName() != "test.code" {
+				return nil
+			}
+			return codeBuilder{array.NewExtensionBuilder(mem, field.Type.(arrow.ExtensionType))}
+		}))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	assert.False(t, reader.Next())
+	require.NoError(t, reader.Err())
+
+	assert.Equal(t, []string{"code"}, built)
+	assert.True(t, arrow.TypeEqual(newCodeType(), rec.Schema().Field(1).Type))
+	codes, ok := rec.Column(1).(*codeArray)
+	require.True(t, ok, "got %T", rec.Column(1))
+	storage := codes.Storage().(*array.String)
+	assert.Equal(t, "ABC", storage.Value(0))
+	assert.True(t, codes.IsNull(1))
+	assert.Equal(t, "[1 2]", rec.Column(0).String())
+}
@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"context"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// defaultChannelBuffer is the number of records Channel buffers unless
+// WithChannelBuffer is given.
+const defaultChannelBuffer = 8
+
+// Channel reads the remaining batches on a separate goroutine and sends them
+// on the returned channel, which is closed at the end of the stream or when
+// ctx is done. The receiver owns each record and must release it. Once the
+// producer has filled the channel's buffer it blocks until the consumer
+// catches up, so at most the buffer size plus one record are in flight. The
+// reader is released when the stream ends; check Err after the channel is
+// closed.
+func (r *BatchReader) Channel(ctx context.Context) <-chan arrow.Record {
+	size := defaultChannelBuffer
+	if r.opts.channelBufferSet {
+		size = r.opts.channelBuffer
+	}
+
+	out := make(chan arrow.Record, size)
+	go func() {
+		defer close(out)
+		defer r.Release()
+
+		for ctx.Err() == nil && r.Next() {
+			rec, err := r.TakeRecord()
+			if err != nil {
+				r.err = err
+				return
+			}
+			select {
+			case out <- rec:
+			case <-ctx.Done():
+				rec.Release()
+				r.err = ctx.Err()
+				return
+			}
+		}
+		if r.err == nil && ctx.Err() != nil {
+			r.err = ctx.Err()
+		}
+	}()
+	return out
+}
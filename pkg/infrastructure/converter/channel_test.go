@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchCounter counts the batches a reader has produced.
+type batchCounter struct {
+	batches atomic.Int64
+}
+
+func (c *batchCounter) OnBatch(int, int64, time.Duration) { c.batches.Add(1) }
+func (c *batchCounter) OnError(error)                     {}
+
+func TestBatchReaderChannelBackpressure(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	const buffer = 2
+	var produced batchCounter
+	rows := queryRows(t, "SELECT i FROM range(20) t(i)")
+	reader, err := NewBatchReader(alloc, rows, logger, WithChannelBuffer(buffer), WithMetricsObserver(&produced))
+	require.NoError(t, err)
+	reader.SetBatchSize(1)
+
+	ch := reader.Channel(context.Background())
+	assert.Equal(t, buffer, cap(ch))
+
+	var consumed, maxOutstanding int64
+	for rec := range ch {
+		consumed++
+		// Give the producer time to run ahead as far as it can.
+		time.Sleep(5 * time.Millisecond)
+		maxOutstanding = max(maxOutstanding, produced.batches.Load()-consumed)
+		rec.Release()
+	}
+	require.NoError(t, reader.Err())
+	assert.EqualValues(t, 20, consumed)
+	// The buffered records plus the one the producer is blocked sending.
+	assert.LessOrEqual(t, maxOutstanding, int64(buffer+1))
+	assert.Positive(t, maxOutstanding)
+}
+
+func TestBatchReaderChannelCanceled(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT i FROM range(20) t(i)")
+	reader, err := NewBatchReader(alloc, rows, logger, WithChannelBuffer(0))
+	require.NoError(t, err)
+	reader.SetBatchSize(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := reader.Channel(ctx)
+	rec := <-ch
+	rec.Release()
+	cancel()
+	for rec := range ch {
+		rec.Release()
+	}
+	assert.ErrorIs(t, reader.Err(), context.Canceled)
+}
@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Coalesce returns a reader combining consecutive records of reader until
+// each holds at least rows rows, for sinks that handle many small batches
+// poorly, such as those left by a selective Filter. Records already that
+// large are passed on unchanged and the last record may be smaller; rows
+// below 1 is treated as 1, so every non-empty record is passed on as is. An
+// error reading reader is reported once the rows read before it have been
+// returned. Coalesce takes ownership of reader.
+func Coalesce(reader *BatchReader, rows int64) *BatchReader {
+	if rows < 1 {
+		rows = 1
+	}
+	c := &coalescer{input: reader, rows: rows}
+	return newDerivedReader(reader.Schema(), reader.allocator, reader.logger,
+		recordSource{next: c.next, close: reader.Release})
+}
+
+// coalescer produces the combined records of Coalesce.
+type coalescer struct {
+	input *BatchReader
+	rows  int64
+}
+
+// next returns the next combined record, or nil at the end of the input.
+func (c *coalescer) next() (arrow.Record, error) {
+	var (
+		parts []arrow.Record
+		n     int64
+	)
+	defer func() {
+		for _, p := range parts {
+			p.Release()
+		}
+	}()
+	for n < c.rows && c.input.Next() {
+		rec, err := c.input.TakeRecord()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, rec)
+		n += rec.NumRows()
+	}
+
+	switch len(parts) {
+	case 0:
+		if err := c.input.Err(); err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to read coalesce input")
+		}
+		return nil, nil
+	case 1:
+		out := parts[0]
+		parts = nil
+		return out, nil
+	}
+	return concatRecords(c.input.allocator, c.input.Schema(), parts, n)
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"database/sql"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// ColumnCommentKey is the Arrow field metadata key holding a column's
+// DuckDB comment, added by WithColumnComments.
+const ColumnCommentKey = "duckdb:comment"
+
+// columnCommentSource is the catalog configured with WithColumnComments.
+type columnCommentSource struct {
+	db     *sql.DB
+	tables []string
+}
+
+// loadColumnComments reads the column comments of src's catalog, keyed by
+// column name. Result columns carry no table, so names commented
+// differently in several of the tables considered are left out.
+func loadColumnComments(src *columnCommentSource, logger zerolog.Logger) (map[string]string, error) {
+	rows, err := src.db.Query(`SELECT table_name, column_name, comment FROM duckdb_columns() WHERE comment IS NOT NULL AND comment <> ''`)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to query column comments")
+	}
+	defer rows.Close()
+
+	comments := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	for rows.Next() {
+		var table, column, comment string
+		if err := rows.Scan(&table, &column, &comment); err != nil {
+			return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to scan column comment")
+		}
+		if len(src.tables) > 0 && !slices.Contains(src.tables, table) {
+			continue
+		}
+		if prev, ok := comments[column]; ok && prev != comment {
+			ambiguous[column] = true
+		}
+		comments[column] = comment
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to read column comments")
+	}
+
+	for column := range ambiguous {
+		logger.Warn().Str("column", column).Msg("column is commented differently in several tables, skipping its comment")
+		delete(comments, column)
+	}
+	return comments, nil
+}
+
+// applyColumnComments adds the ColumnCommentKey metadata to the fields
+// that have a comment.
+func applyColumnComments(fields []arrow.Field, comments map[string]string) {
+	for i, f := range fields {
+		comment, ok := comments[f.Name]
+		if !ok {
+			continue
+		}
+		keys := append(f.Metadata.Keys(), ColumnCommentKey)
+		values := append(f.Metadata.Values(), comment)
+		fields[i].Metadata = arrow.NewMetadata(keys, values)
+	}
+}
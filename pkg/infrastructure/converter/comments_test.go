@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderColumnComments(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER, amount DOUBLE, note VARCHAR)",
+		"COMMENT ON COLUMN orders.amount IS 'Order total in EUR'",
+		"COMMENT ON COLUMN orders.id IS 'Order id'",
+		"CREATE TABLE customers (id INTEGER)",
+		"COMMENT ON COLUMN customers.id IS 'Customer id'",
+		"INSERT INTO orders VALUES (1, 9.5, 'first')",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	comment := func(t *testing.T, reader *BatchReader, column string) (string, bool) {
+		idx := reader.Schema().FieldIndices(column)
+		require.Len(t, idx, 1)
+		return reader.Schema().Field(idx[0]).Metadata.GetValue(ColumnCommentKey)
+	}
+
+	t.Run("table", func(t *testing.T) {
+		rows, err := db.Query("SELECT id, amount, note FROM orders")
+		require.NoError(t, err)
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithColumnComments(db, "orders"))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		got, ok := comment(t, reader, "amount")
+		assert.True(t, ok)
+		assert.Equal(t, "Order total in EUR", got)
+		got, _ = comment(t, reader, "id")
+		assert.Equal(t, "Order id", got)
+		_, ok = comment(t, reader, "note")
+		assert.False(t, ok)
+		// The type name metadata is kept
+		_, ok = reader.Schema().Field(1).Metadata.GetValue("ARROW:FLIGHT:SQL:TYPE_NAME")
+		assert.True(t, ok)
+	})
+
+	t.Run("ambiguous without tables", func(t *testing.T) {
+		rows, err := db.Query("SELECT id, amount FROM orders")
+		require.NoError(t, err)
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithColumnComments(db))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		_, ok := comment(t, reader, "id")
+		assert.False(t, ok)
+		got, _ := comment(t, reader, "amount")
+		assert.Equal(t, "Order total in EUR", got)
+	})
+}
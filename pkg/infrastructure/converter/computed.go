@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// RowView gives read access to the values of the current row. For a
+// computed column, columns are indexed and named as returned by the query,
+// and values are as scanned from the driver, before any conversion by other
+// options. For Filter, the row is read from a record and values are
+// returned as by RecordToRows.
+type RowView struct {
+	fields []arrow.Field
+	dest   []interface{} // nil while the column type is being determined
+
+	// rec is the record row is read from, instead of dest
+	rec arrow.Record
+	row int
+}
+
+// Len returns the number of columns in the row.
+func (v RowView) Len() int {
+	return len(v.fields)
+}
+
+// Name returns the name of column i.
+func (v RowView) Name(i int) string {
+	return v.fields[i].Name
+}
+
+// Index returns the index of the named column, or -1 if there is none.
+func (v RowView) Index(name string) int {
+	for i, f := range v.fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsNull reports whether column i is NULL.
+func (v RowView) IsNull(i int) bool {
+	if v.rec != nil {
+		return v.rec.Column(i).IsNull(v.row)
+	}
+	return v.dest == nil || isNullScan(v.dest[i])
+}
+
+// Value returns the value of column i, or nil if it is NULL.
+func (v RowView) Value(i int) any {
+	if v.IsNull(i) {
+		return nil
+	}
+	if v.rec != nil {
+		// Types RecordToRows cannot convert read as nil
+		value, _ := arrowValue(v.rec.Column(i), v.row)
+		return value
+	}
+	switch d := v.dest[i].(type) {
+	case *interface{}:
+		return *d
+	case *timeOrString:
+		if d.IsString {
+			return d.String
+		}
+		return d.Time
+	case *scanBuffer:
+		// The buffer is reused for the next row
+		if v.fields[i].Type.ID() == arrow.STRING {
+			return string(d.buf)
+		}
+		return bytes.Clone(d.buf)
+	}
+
+	rv := reflect.ValueOf(v.dest[i]).Elem()
+	if rv.Kind() == reflect.Struct && rv.FieldByName("Valid").IsValid() {
+		// sql.NullXxx and sql.Null[T] hold the value in their first field
+		return rv.Field(0).Interface()
+	}
+	return rv.Interface()
+}
+
+// computedColumn is a derived column registered with WithComputedColumn.
+type computedColumn struct {
+	name string
+	expr func(RowView) (any, arrow.DataType)
+	typ  arrow.DataType
+}
+
+// appendComputedFields determines the type of each computed column by
+// evaluating it over a row of nulls, and adds the columns to fields. view
+// describes the scanned row the columns are computed from.
+func appendComputedFields(fields []arrow.Field, view RowView, computed []computedColumn) ([]arrow.Field, []computedColumn, error) {
+	if len(computed) == 0 {
+		return fields, nil, nil
+	}
+
+	out := make([]computedColumn, len(computed))
+	probe := RowView{fields: view.fields}
+	for i, c := range computed {
+		for _, f := range fields {
+			if f.Name == c.name {
+				return nil, nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("computed column %q clashes with a result column", c.name))
+			}
+		}
+		_, dt := c.expr(probe)
+		if dt == nil {
+			return nil, nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("computed column %q has no type", c.name))
+		}
+		c.typ = dt
+		out[i] = c
+		fields = append(fields, arrow.Field{Name: c.name, Type: dt, Nullable: true})
+	}
+	return fields, out, nil
+}
+
+// appendComputed evaluates the computed columns for the current row and
+// appends their values to the builders starting at column first.
+func (r *BatchReader) appendComputed(first int) error {
+	for i, c := range r.computed {
+		value, dt := c.expr(r.view)
+		if !arrow.TypeEqual(dt, c.typ) {
+			return errors.New(errors.CodeInternal, fmt.Sprintf("computed column %q returned type %s, expected %s", c.name, dt, c.typ))
+		}
+		if err := r.appendDynamicValue(r.builder.Field(first+i), value); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "failed to append computed column %q", c.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullName concatenates the first and last columns, or is null if either is.
+func fullName(row RowView) (any, arrow.DataType) {
+	first, last := row.Value(row.Index("first")), row.Value(row.Index("last"))
+	if first == nil || last == nil {
+		return nil, arrow.BinaryTypes.String
+	}
+	return first.(string) + " " + last.(string), arrow.BinaryTypes.String
+}
+
+func TestBatchReaderComputedColumn(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	query := `SELECT * FROM (VALUES ('Ada', 'Lovelace'), ('Alan', NULL), ('Grace', 'Hopper')) t(first, last)`
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "pooled buffers", opts: []Option{WithBufferPool()}},
+		{name: "renamed with row numbers", opts: []Option{
+			WithColumnRename(map[string]string{"first": "given"}),
+			WithRowNumberColumn("n"),
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithComputedColumn("full_name", fullName)}, tt.opts...)
+			reader, err := NewBatchReader(alloc, queryRows(t, query), logger, opts...)
+			require.NoError(t, err)
+			defer reader.Release()
+
+			idx := reader.Schema().FieldIndices("full_name")
+			require.Equal(t, []int{2}, idx)
+			assert.Equal(t, arrow.BinaryTypes.String, reader.Schema().Field(2).Type)
+
+			require.True(t, reader.Next(), reader.Err())
+			rec := reader.Record()
+			defer rec.Release()
+			col := rec.Column(2).(*array.String)
+			assert.Equal(t, "Ada Lovelace", col.Value(0))
+			assert.True(t, col.IsNull(1))
+			assert.Equal(t, "Grace Hopper", col.Value(2))
+			if rec.NumCols() == 4 {
+				assert.Equal(t, []int64{0, 1, 2}, rec.Column(3).(*array.Int64).Int64Values())
+			}
+			assert.False(t, reader.Next())
+			require.NoError(t, reader.Err())
+		})
+	}
+}
+
+func TestBatchReaderComputedColumnErrors(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("name clash", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 'a' AS first, 'b' AS last"), logger,
+			WithComputedColumn("first", fullName))
+		assert.ErrorContains(t, err, "clashes")
+	})
+
+	t.Run("type changes", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS n"), logger,
+			WithComputedColumn("c", func(row RowView) (any, arrow.DataType) {
+				if row.IsNull(0) {
+					return nil, arrow.PrimitiveTypes.Int64
+				}
+				return "x", arrow.BinaryTypes.String
+			}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), `computed column "c" returned type utf8`)
+	})
+}
@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// queryIDKey is the context key under which WithQueryID stores a query id.
+type queryIDKey struct{}
+
+// WithQueryID returns a copy of ctx carrying the given query or request id,
+// which readers created by NewBatchReaderContext attach to every log line.
+func WithQueryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, queryIDKey{}, id)
+}
+
+// QueryIDFromContext returns the id stored by WithQueryID, or "" if absent.
+func QueryIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(queryIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// NewBatchReaderContext creates a batch reader like NewBatchReader whose log
+// lines carry the query id found in ctx as the "query_id" field. rows should
+// come from QueryContext with the same ctx: once ctx is done the reader
+// closes rows before reading another batch and Err reports CodeCanceled, or
+// CodeDeadlineExceeded if the deadline passed.
+func NewBatchReaderContext(ctx context.Context, allocator memory.Allocator, rows *sql.Rows, logger zerolog.Logger, opts ...Option) (*BatchReader, error) {
+	if id := QueryIDFromContext(ctx); id != "" {
+		logger = logger.With().Str("query_id", id).Logger()
+	}
+	r, err := NewBatchReader(allocator, rows, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.ctx = ctx
+	return r, nil
+}
+
+// checkContext closes the rows and sets r.err if the reader's context is
+// done. It returns false in that case.
+func (r *BatchReader) checkContext() bool {
+	if r.ctx == nil || r.ctx.Err() == nil {
+		return true
+	}
+	r.logger.Debug().Err(r.ctx.Err()).Msg("BatchReader.Next: context done, closing rows")
+	r.rows.Close()
+	r.rows = nil
+	r.err = contextError(r.ctx.Err())
+	return false
+}
+
+// rowsErr returns the error that ended iteration over r.rows, including a
+// WithAllResultSets mismatch. database/sql reports the context's error once
+// it closes rows on cancellation, which is surfaced as CodeCanceled or
+// CodeDeadlineExceeded.
+func (r *BatchReader) rowsErr() error {
+	if r.resultErr != nil {
+		return r.resultErr
+	}
+	err := r.rows.Err()
+	if err != nil && r.ctx != nil && r.ctx.Err() != nil {
+		return contextError(r.ctx.Err())
+	}
+	return err
+}
+
+// contextError wraps the error of a done context.
+func contextError(err error) error {
+	if err == context.DeadlineExceeded {
+		return errors.Wrap(err, errors.CodeDeadlineExceeded, "query deadline exceeded")
+	}
+	return errors.Wrap(err, errors.CodeCanceled, "query canceled")
+}
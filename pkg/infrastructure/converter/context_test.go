@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// logCapture collects the lines written through a zerolog.TestWriter.
+type logCapture struct {
+	lines []string
+}
+
+func (c *logCapture) Log(args ...interface{}) { c.lines = append(c.lines, fmt.Sprint(args...)) }
+func (c *logCapture) Logf(format string, args ...interface{}) {
+	c.lines = append(c.lines, fmt.Sprintf(format, args...))
+}
+func (c *logCapture) Helper() {}
+
+func TestNewBatchReaderContextQueryID(t *testing.T) {
+	var logs logCapture
+	logger := zerolog.New(zerolog.TestWriter{T: &logs})
+
+	ctx := WithQueryID(context.Background(), "q-42")
+	assert.Equal(t, "q-42", QueryIDFromContext(ctx))
+	assert.Empty(t, QueryIDFromContext(context.Background()))
+
+	rows := queryRows(t, "SELECT i FROM range(3) t(i)")
+	reader, err := NewBatchReaderContext(ctx, memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	for reader.Next() {
+		reader.Record().Release()
+	}
+	require.NoError(t, reader.Err())
+	reader.Release()
+
+	require.NotEmpty(t, logs.lines)
+	for _, line := range logs.lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		assert.Equal(t, "q-42", entry["query_id"], line)
+	}
+}
+
+// cancelDriver serves an endless result set and records when the context
+// of its query is cancelled and when its rows are closed.
+type cancelDriver struct {
+	cancelled chan struct{}
+	closed    atomic.Bool
+}
+
+func (d *cancelDriver) Connect(context.Context) (driver.Conn, error) { return cancelConn{d}, nil }
+func (d *cancelDriver) Driver() driver.Driver                        { return nil }
+
+type cancelConn struct{ d *cancelDriver }
+
+func (cancelConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("not supported") }
+func (cancelConn) Close() error                        { return nil }
+func (cancelConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+func (c cancelConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	go func() {
+		<-ctx.Done()
+		close(c.d.cancelled)
+	}()
+	return &cancelRows{d: c.d}, nil
+}
+
+type cancelRows struct {
+	d    *cancelDriver
+	next int64
+}
+
+func (r *cancelRows) Columns() []string { return []string{"i"} }
+func (r *cancelRows) Close() error {
+	r.d.closed.Store(true)
+	return nil
+}
+func (r *cancelRows) ColumnTypeDatabaseTypeName(int) string { return "BIGINT" }
+func (r *cancelRows) Next(dest []driver.Value) error {
+	dest[0] = r.next
+	r.next++
+	return nil
+}
+
+func TestQueryCancellation(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	d := &cancelDriver{cancelled: make(chan struct{})}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader, err := Query(ctx, db, alloc, logger, "SELECT i FROM endless")
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	reader.Record().Release()
+
+	cancel()
+	select {
+	case <-d.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("driver did not observe the cancellation")
+	}
+
+	assert.False(t, reader.Next())
+	assert.Equal(t, errors.CodeCanceled, errors.GetCode(reader.Err()))
+	assert.ErrorIs(t, reader.Err(), context.Canceled)
+	assert.True(t, d.closed.Load(), "rows were not closed")
+}
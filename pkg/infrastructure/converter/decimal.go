@@ -0,0 +1,177 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/decimal256"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// appendBigInt appends a 128-bit integer, as the driver returns HUGEINT and
+// UHUGEINT values, to a decimal, Float64 or String builder. Values that do
+// not fit the decimal's precision are errors.
+func appendBigInt(fb array.Builder, v *big.Int) error {
+	switch fb.(type) {
+	case *array.Decimal32Builder, *array.Decimal64Builder, *array.Decimal128Builder, *array.Decimal256Builder,
+		*array.Float64Builder:
+		return appendDecimal(fb, v, 0, DecimalRoundError)
+	default:
+		return appendStringValue(fb, v.String())
+	}
+}
+
+// appendDecimal appends the decimal unscaled*10^-scale, as drivers return
+// their native decimal values, to a Decimal128 or Decimal256 builder without
+// going through text. Narrow Decimal32 and Decimal64 builders are also
+// accepted. The value is rescaled to the column's scale, dropping digits
+// beyond it as rounding says; values exceeding the column's precision are
+// errors. A Float64 builder, for WithDecimalAsFloat64, takes the nearest
+// float64.
+func appendDecimal(fb array.Builder, unscaled *big.Int, scale int32, rounding DecimalRoundingMode) error {
+	if b, ok := fb.(*array.Float64Builder); ok {
+		f, _ := new(big.Rat).SetFrac(unscaled, pow10(scale)).Float64()
+		b.Append(f)
+		return nil
+	}
+
+	dt, ok := fb.Type().(arrow.DecimalType)
+	if !ok {
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for decimal value", fb))
+	}
+
+	n := new(big.Int).Set(unscaled)
+	switch diff := dt.GetScale() - scale; {
+	case diff > 0:
+		n.Mul(n, pow10(diff))
+	case diff < 0:
+		if !roundDecimal(n, pow10(-diff), rounding) {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("value %s loses digits at scale %d", formatDecimal(unscaled, scale), dt.GetScale()))
+		}
+	}
+	if n.CmpAbs(pow10(dt.GetPrecision())) >= 0 {
+		return errors.New(errors.CodeInvalidRequest, fmt.Sprintf("value %s overflows %s", formatDecimal(unscaled, scale), dt))
+	}
+
+	// The precision check above guarantees n fits the builder's width
+	switch b := fb.(type) {
+	case *array.Decimal32Builder:
+		b.Append(decimal.Decimal32(n.Int64()))
+	case *array.Decimal64Builder:
+		b.Append(decimal.Decimal64(n.Int64()))
+	case *array.Decimal128Builder:
+		b.Append(decimal128.FromBigInt(n))
+	case *array.Decimal256Builder:
+		b.Append(decimal256.FromBigInt(n))
+	}
+	return nil
+}
+
+// roundDecimal divides n by d in place, rounding the quotient as mode
+// says. It reports false, leaving n undefined, if the division is inexact
+// and mode is DecimalRoundError.
+func roundDecimal(n, d *big.Int, mode DecimalRoundingMode) bool {
+	neg := n.Sign() < 0
+	var rem big.Int
+	n.QuoRem(n, d, &rem)
+	if rem.Sign() == 0 {
+		return true
+	}
+
+	// QuoRem truncates toward zero, so rounding up moves the quotient one
+	// step away from it.
+	half := rem.Lsh(rem.Abs(&rem), 1).Cmp(d)
+	switch mode {
+	case DecimalRoundError:
+		return false
+	case DecimalRoundHalfUp:
+		if half < 0 {
+			return true
+		}
+	case DecimalRoundHalfEven:
+		if half < 0 || half == 0 && n.Bit(0) == 0 {
+			return true
+		}
+	default:
+		return true
+	}
+	if neg {
+		n.Sub(n, big.NewInt(1))
+	} else {
+		n.Add(n, big.NewInt(1))
+	}
+	return true
+}
+
+// appendDecimalString parses s, as drivers without a native decimal type
+// return decimals, and appends it to a decimal builder of any width.
+func appendDecimalString(fb array.Builder, s string) error {
+	var err error
+	switch b := fb.(type) {
+	case *array.Decimal32Builder:
+		dt := b.Type().(*arrow.Decimal32Type)
+		var n decimal.Decimal32
+		if n, err = decimal.Decimal32FromString(s, dt.Precision, dt.Scale); err == nil {
+			b.Append(n)
+		}
+	case *array.Decimal64Builder:
+		dt := b.Type().(*arrow.Decimal64Type)
+		var n decimal.Decimal64
+		if n, err = decimal.Decimal64FromString(s, dt.Precision, dt.Scale); err == nil {
+			b.Append(n)
+		}
+	case *array.Decimal128Builder:
+		dt := b.Type().(*arrow.Decimal128Type)
+		var n decimal128.Num
+		if n, err = decimal128.FromString(s, dt.Precision, dt.Scale); err == nil {
+			b.Append(n)
+		}
+	case *array.Decimal256Builder:
+		dt := b.Type().(*arrow.Decimal256Type)
+		var n decimal256.Num
+		if n, err = decimal256.FromString(s, dt.Precision, dt.Scale); err == nil {
+			b.Append(n)
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, errors.CodeInvalidRequest, "cannot parse %q as %s", s, fb.Type())
+	}
+	return nil
+}
+
+// narrowDecimal returns the narrowest decimal type holding the precision of
+// dt, Decimal32 up to 9 digits and Decimal64 up to 18, for
+// WithNarrowDecimals. Other types are returned unchanged.
+func narrowDecimal(dt arrow.DataType) arrow.DataType {
+	d, ok := dt.(*arrow.Decimal128Type)
+	if !ok {
+		return dt
+	}
+	switch {
+	case d.Precision <= 9:
+		return &arrow.Decimal32Type{Precision: d.Precision, Scale: d.Scale}
+	case d.Precision <= 18:
+		return &arrow.Decimal64Type{Precision: d.Precision, Scale: d.Scale}
+	default:
+		return dt
+	}
+}
+
+// pow10 returns 10^n.
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// formatDecimal renders unscaled*10^-scale for error messages.
+func formatDecimal(unscaled *big.Int, scale int32) string {
+	if scale <= 0 {
+		return unscaled.String()
+	}
+	return new(big.Rat).SetFrac(unscaled, pow10(scale)).FloatString(int(scale))
+}
@@ -0,0 +1,240 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/marcboeker/go-duckdb/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderNativeDecimal(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		(12.50::DECIMAL(10,2), 123456789012345678901234567890.1234567::DECIMAL(38,7)),
+		(-0.01::DECIMAL(10,2), 0::DECIMAL(38,7)),
+		(NULL, NULL)) t(price, big)`)
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	assert.Equal(t, &arrow.Decimal128Type{Precision: 10, Scale: 2}, reader.Schema().Field(0).Type)
+	assert.Equal(t, &arrow.Decimal128Type{Precision: 38, Scale: 7}, reader.Schema().Field(1).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	price := rec.Column(0).(*array.Decimal128)
+	assert.Equal(t, "12.50", price.Value(0).ToString(2))
+	assert.Equal(t, "-0.01", price.Value(1).ToString(2))
+	assert.True(t, price.IsNull(2))
+	bigCol := rec.Column(1).(*array.Decimal128)
+	assert.Equal(t, "123456789012345678901234567890.1234567", bigCol.Value(0).ToString(7))
+	assert.True(t, bigCol.IsNull(2))
+	for reader.Next() {
+	}
+}
+
+func TestBatchReaderDecimalRescale(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// open returns rows of a DECIMAL(10,2) column holding the given values.
+	open := func(values ...driver.Value) *sql.Rows {
+		result := &fakeResult{
+			columns:   []string{"d"},
+			typeNames: []string{"DECIMAL(10,2)"},
+			scanTypes: []reflect.Type{reflect.TypeOf(duckdb.Decimal{})},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v})
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT d")
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("scales and text", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(
+			duckdb.Decimal{Width: 10, Scale: 1, Value: big.NewInt(125)},
+			duckdb.Decimal{Width: 10, Scale: 4, Value: big.NewInt(-12300)},
+			"3.14",
+		), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.Decimal128)
+		assert.Equal(t, "12.50", col.Value(0).ToString(2))
+		assert.Equal(t, "-1.23", col.Value(1).ToString(2))
+		assert.Equal(t, "3.14", col.Value(2).ToString(2))
+	})
+
+	t.Run("lost digits", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(
+			duckdb.Decimal{Width: 10, Scale: 3, Value: big.NewInt(12345)},
+		), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "value 12.345 loses digits at scale 2")
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(
+			duckdb.Decimal{Width: 18, Scale: 2, Value: big.NewInt(100000000000)},
+		), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "overflows decimal(10, 2)")
+	})
+}
+
+func TestBatchReaderNarrowDecimals(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		(1234567.89::DECIMAL(9,2), 1234567890123456.78::DECIMAL(18,2), 12345678901234567890.12::DECIMAL(22,2)),
+		(-0.01::DECIMAL(9,2), -0.01::DECIMAL(18,2), -0.01::DECIMAL(22,2)),
+		(NULL, NULL, NULL)) t(d9, d18, d22)`)
+	reader, err := NewBatchReader(alloc, rows, logger, WithNarrowDecimals())
+	require.NoError(t, err)
+	defer reader.Release()
+
+	schema := reader.Schema()
+	assert.Equal(t, &arrow.Decimal32Type{Precision: 9, Scale: 2}, schema.Field(0).Type)
+	assert.Equal(t, &arrow.Decimal64Type{Precision: 18, Scale: 2}, schema.Field(1).Type)
+	assert.Equal(t, &arrow.Decimal128Type{Precision: 22, Scale: 2}, schema.Field(2).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	d9 := rec.Column(0).(*array.Decimal32)
+	assert.Equal(t, "1234567.89", d9.Value(0).ToString(2))
+	assert.Equal(t, "-0.01", d9.Value(1).ToString(2))
+	assert.True(t, d9.IsNull(2))
+	d18 := rec.Column(1).(*array.Decimal64)
+	assert.Equal(t, "1234567890123456.78", d18.Value(0).ToString(2))
+	assert.Equal(t, "-0.01", d18.Value(1).ToString(2))
+	assert.True(t, d18.IsNull(2))
+	d22 := rec.Column(2).(*array.Decimal128)
+	assert.Equal(t, "12345678901234567890.12", d22.Value(0).ToString(2))
+	assert.True(t, d22.IsNull(2))
+	for reader.Next() {
+	}
+}
+
+func TestBatchReaderDecimalAsFloat64(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		(1234567.89::DECIMAL(9,2), 12345678901234567890.12::DECIMAL(22,2), 42::INTEGER),
+		(-0.01::DECIMAL(9,2), 0.000001::DECIMAL(22,6), 7),
+		(NULL, NULL, NULL)) t(d9, d22, i)`)
+	reader, err := NewBatchReader(alloc, rows, logger, WithDecimalAsFloat64(), WithNarrowDecimals())
+	require.NoError(t, err)
+	defer reader.Release()
+
+	schema := reader.Schema()
+	assert.Equal(t, arrow.PrimitiveTypes.Float64, schema.Field(0).Type)
+	assert.Equal(t, arrow.PrimitiveTypes.Float64, schema.Field(1).Type)
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, schema.Field(2).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	d9 := rec.Column(0).(*array.Float64)
+	assert.Equal(t, 1234567.89, d9.Value(0))
+	assert.Equal(t, -0.01, d9.Value(1))
+	assert.True(t, d9.IsNull(2))
+	// The 22 digit value keeps float64's precision only
+	d22 := rec.Column(1).(*array.Float64)
+	assert.Equal(t, 1.2345678901234567e19, d22.Value(0))
+	assert.Equal(t, 0.000001, d22.Value(1))
+	assert.True(t, d22.IsNull(2))
+	for reader.Next() {
+	}
+	require.NoError(t, reader.Err())
+}
+
+func TestBatchReaderDecimalRounding(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "d", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+	}, nil)
+
+	// read appends DECIMAL(10,4) values, given unscaled, to the scale-2
+	// column and returns them as text.
+	read := func(t *testing.T, mode DecimalRoundingMode, values ...int64) ([]string, error) {
+		result := &fakeResult{
+			columns:   []string{"d"},
+			typeNames: []string{"DECIMAL(10,4)"},
+			scanTypes: []reflect.Type{reflect.TypeOf(duckdb.Decimal{})},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{duckdb.Decimal{Width: 10, Scale: 4, Value: big.NewInt(v)}})
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		defer db.Close()
+		rows, err := db.Query("SELECT d")
+		require.NoError(t, err)
+
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger, WithDecimalRounding(mode))
+		require.NoError(t, err)
+		defer reader.Release()
+		if !reader.Next() {
+			return nil, reader.Err()
+		}
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.Decimal128)
+		out := make([]string, col.Len())
+		for i := range out {
+			out[i] = col.Value(i).ToString(2)
+		}
+		return out, nil
+	}
+
+	values := []int64{12345, 12350, -12350, 12250, 50, -50, 10000}
+	for _, tc := range []struct {
+		mode DecimalRoundingMode
+		want []string
+	}{
+		{DecimalRoundHalfUp, []string{"1.23", "1.24", "-1.24", "1.23", "0.01", "-0.01", "1.00"}},
+		{DecimalRoundHalfEven, []string{"1.23", "1.24", "-1.24", "1.22", "0.00", "0.00", "1.00"}},
+		{DecimalRoundTruncate, []string{"1.23", "1.23", "-1.23", "1.22", "0.00", "0.00", "1.00"}},
+	} {
+		got, err := read(t, tc.mode, values...)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "mode %d", tc.mode)
+	}
+
+	_, err := read(t, DecimalRoundError, 12345)
+	assert.ErrorContains(t, err, "value 1.2345 loses digits at scale 2")
+
+	// Rounding up can carry past the column's precision.
+	_, err = read(t, DecimalRoundHalfUp, 999999999950)
+	assert.ErrorContains(t, err, "overflows decimal(10, 2)")
+}
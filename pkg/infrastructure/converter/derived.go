@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// recordSource produces the records of a BatchReader derived from other
+// readers rather than read from SQL rows.
+type recordSource struct {
+	// next returns the next record, owned by the reader, or nil at the end
+	// of the stream.
+	next func() (arrow.Record, error)
+	// close releases the inputs.
+	close func()
+	// rewind restarts the records from the first one, or is nil if the
+	// source cannot be read again.
+	rewind func() error
+}
+
+// newDerivedReader returns a BatchReader handing out the records of src.
+func newDerivedReader(schema *arrow.Schema, allocator memory.Allocator, logger zerolog.Logger, src recordSource) *BatchReader {
+	r := &BatchReader{
+		schema:    schema,
+		allocator: allocator,
+		logger:    logger,
+		batchSize: defaultBatchSize,
+		column:    -1,
+		source:    &src,
+	}
+	r.refCount.Store(1)
+	return r
+}
+
+// readSource makes the next record of the derived reader's source current.
+func (r *BatchReader) readSource() bool {
+	rec, err := r.source.next()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	if rec == nil {
+		return false
+	}
+	r.record = rec
+	return true
+}
+
+// Rewind restarts the reader from its first record, for algorithms that
+// read the data twice. Only readers over a source that is kept in memory,
+// such as those of NewReplayReader, can be rewound; readers over SQL rows
+// fail with CodeFailedPrecondition, as does a reader that failed. The
+// current record is released; records returned by Record stay valid.
+func (r *BatchReader) Rewind() error {
+	if r.err != nil {
+		return errors.Wrap(r.err, errors.CodeFailedPrecondition, "cannot rewind a failed reader")
+	}
+	if r.source == nil || r.source.rewind == nil {
+		return errors.New(errors.CodeFailedPrecondition, "reader cannot be rewound")
+	}
+	if err := r.source.rewind(); err != nil {
+		return err
+	}
+	if r.record != nil {
+		r.record.Release()
+		r.record = nil
+	}
+	r.manifest = manifestState{}
+	return nil
+}
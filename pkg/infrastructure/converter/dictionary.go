@@ -0,0 +1,118 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// dictionaryStringType is the type used for dictionary-encoded strings.
+var dictionaryStringType = &arrow.DictionaryType{
+	IndexType: arrow.PrimitiveTypes.Int32,
+	ValueType: arrow.BinaryTypes.String,
+}
+
+// applyDictionaryColumns replaces the string types selected by paths with
+// dictionary-encoded strings, in place.
+func applyDictionaryColumns(fields []arrow.Field, paths []string) error {
+	for _, path := range paths {
+		name, rest, _ := strings.Cut(path, ".")
+		idx := -1
+		for i := range fields {
+			if fields[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot dictionary-encode unknown column %q", name))
+		}
+
+		var segments []string
+		if rest != "" {
+			segments = strings.Split(rest, ".")
+		}
+		dt, err := dictionaryEncode(fields[idx].Type, segments)
+		if err != nil {
+			return errors.Wrapf(err, errors.CodeInvalidRequest, "cannot dictionary-encode %q", path)
+		}
+		fields[idx].Type = dt
+	}
+	return nil
+}
+
+// dictionaryEncode returns dt with the string type at path dictionary
+// encoded. Struct fields are selected by name and lists are traversed.
+func dictionaryEncode(dt arrow.DataType, path []string) (arrow.DataType, error) {
+	switch t := dt.(type) {
+	case *arrow.ListType:
+		elem := t.ElemField()
+		elemType, err := dictionaryEncode(elem.Type, path)
+		if err != nil {
+			return nil, err
+		}
+		elem.Type = elemType
+		return arrow.ListOfField(elem), nil
+
+	case *arrow.StructType:
+		if len(path) == 0 {
+			return nil, fmt.Errorf("path ends at a struct")
+		}
+		idx, ok := t.FieldIdx(path[0])
+		if !ok {
+			return nil, fmt.Errorf("struct has no field %q", path[0])
+		}
+		fields := t.Fields()
+		childType, err := dictionaryEncode(fields[idx].Type, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		fields[idx].Type = childType
+		return arrow.StructOf(fields...), nil
+
+	case *arrow.StringType:
+		if len(path) > 0 {
+			return nil, fmt.Errorf("string has no field %q", path[0])
+		}
+		return dictionaryStringType, nil
+
+	case *arrow.DictionaryType:
+		if len(path) > 0 {
+			return nil, fmt.Errorf("dictionary has no field %q", path[0])
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("type %s cannot be dictionary-encoded", dt)
+}
+
+// storageBuilder is implemented by the builders of extension types, such as
+// *array.ExtensionBuilder and the builders embedding it.
+type storageBuilder interface {
+	StorageBuilder() array.Builder
+}
+
+// appendStringValue appends s to a plain or dictionary-encoded string builder.
+func appendStringValue(fb array.Builder, s string) error {
+	switch b := fb.(type) {
+	case *array.StringBuilder:
+		b.Append(s)
+	case *array.LargeStringBuilder:
+		b.Append(s)
+	case *array.StringViewBuilder:
+		b.Append(s)
+	case *array.BinaryDictionaryBuilder:
+		return b.AppendString(s)
+	case storageBuilder:
+		// String-backed extension types such as JSON
+		return appendStringValue(b.StorageBuilder(), s)
+	case *array.Decimal32Builder, *array.Decimal64Builder, *array.Decimal128Builder, *array.Decimal256Builder:
+		return appendDecimalString(b, s)
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for string value", fb))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// hllPrecision is the number of hash bits selecting a HyperLogLog
+// register. 2^12 registers take 4 KiB per column and give a standard
+// error of about 1.6%.
+const hllPrecision = 12
+
+// hllSeed seeds the hash of every sketch; estimates only need it to be
+// the same for all values added to one sketch.
+var hllSeed = maphash.MakeSeed()
+
+// hyperLogLog estimates the number of distinct values added to it.
+type hyperLogLog struct {
+	registers [1 << hllPrecision]uint8
+}
+
+// add records a value by its 64-bit hash.
+func (h *hyperLogLog) add(hash uint64) {
+	idx := hash >> (64 - hllPrecision)
+	// The guard bit bounds the rank when the remaining bits are all zero.
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// estimate returns the approximate number of distinct values added,
+// using linear counting while many registers are still empty.
+func (h *hyperLogLog) estimate() uint64 {
+	const m = float64(len(h.registers))
+	var sum float64
+	zeros := 0
+	for _, reg := range h.registers {
+		sum += math.Ldexp(1, -int(reg))
+		if reg == 0 {
+			zeros++
+		}
+	}
+
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(e))
+}
+
+// observe adds the non-null values of arr to the sketch. Primitive values
+// are hashed through their bits and strings through their bytes; other
+// types fall back to their string form, which costs more per value.
+func (h *hyperLogLog) observe(arr arrow.Array) {
+	switch a := arr.(type) {
+	case *array.Int8:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Int16:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Int32:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Int64:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Uint8:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Uint16:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Uint32:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Uint64:
+		observeHashes(h, a, func(i int) uint64 { return mix64(a.Value(i)) })
+	case *array.Float32:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(math.Float32bits(a.Value(i)))) })
+	case *array.Float64:
+		observeHashes(h, a, func(i int) uint64 { return mix64(math.Float64bits(a.Value(i))) })
+	case *array.Date32:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.Timestamp:
+		observeHashes(h, a, func(i int) uint64 { return mix64(uint64(a.Value(i))) })
+	case *array.String:
+		observeHashes(h, a, func(i int) uint64 { return maphash.String(hllSeed, a.Value(i)) })
+	case *array.LargeString:
+		observeHashes(h, a, func(i int) uint64 { return maphash.String(hllSeed, a.Value(i)) })
+	case *array.StringView:
+		observeHashes(h, a, func(i int) uint64 { return maphash.String(hllSeed, a.Value(i)) })
+	case *array.Binary:
+		observeHashes(h, a, func(i int) uint64 { return maphash.Bytes(hllSeed, a.Value(i)) })
+	default:
+		observeHashes(h, a, func(i int) uint64 { return maphash.String(hllSeed, a.ValueStr(i)) })
+	}
+}
+
+// observeHashes adds the hash of each non-null value of arr to h.
+func observeHashes(h *hyperLogLog, arr arrow.Array, hash func(int) uint64) {
+	for i := 0; i < arr.Len(); i++ {
+		if arr.IsValid(i) {
+			h.add(hash(i))
+		}
+	}
+}
+
+// mix64 is the splitmix64 finalizer, spreading the bits of fixed-width
+// values, which are often sequential, over the whole hash.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// ApproxDistinct returns the estimated number of distinct non-null values
+// of each column in the batches read so far, keyed by column name. It
+// returns nil unless the reader was created with WithApproxDistinct.
+func (r *BatchReader) ApproxDistinct() map[string]uint64 {
+	if !r.opts.approxDistinct {
+		return nil
+	}
+	schema := r.Schema()
+	counts := make(map[string]uint64, schema.NumFields())
+	for i, f := range schema.Fields() {
+		if i < len(r.manifest.distinct) {
+			counts[f.Name] = r.manifest.distinct[i].estimate()
+		} else {
+			counts[f.Name] = 0
+		}
+	}
+	return counts
+}
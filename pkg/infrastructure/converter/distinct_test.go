@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderApproxDistinct(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t)).Level(zerolog.InfoLevel)
+	const query = `SELECT i % 50000 AS n, 'value ' || (i % 300) AS s, (i % 7)::DOUBLE AS f,
+		NULL::INTEGER AS z FROM range(200000) t(i)`
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithApproxDistinct())
+	require.NoError(t, err)
+	defer reader.Release()
+	for reader.Next() {
+		reader.Record().Release()
+	}
+	require.NoError(t, reader.Err())
+
+	counts := reader.ApproxDistinct()
+	assert.InEpsilon(t, 50000, counts["n"], 0.05)
+	assert.InEpsilon(t, 300, counts["s"], 0.05)
+	assert.Equal(t, uint64(7), counts["f"])
+	assert.Zero(t, counts["z"])
+
+	t.Run("disabled", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS n"), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Nil(t, reader.ApproxDistinct())
+	})
+}
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// maxDumpCellWidth is the widest cell DumpRecord prints before truncating.
+const maxDumpCellWidth = 32
+
+// DumpRecord writes rec to w as a bordered, human-readable table for
+// troubleshooting. At most maxRows rows are printed (all when maxRows <= 0),
+// nulls are shown as NULL and long values are truncated.
+func DumpRecord(w io.Writer, rec arrow.Record, maxRows int) error {
+	numRows := int(rec.NumRows())
+	shown := numRows
+	if maxRows > 0 && maxRows < numRows {
+		shown = maxRows
+	}
+
+	numCols := int(rec.NumCols())
+	cells := make([][]string, shown+1)
+	cells[0] = make([]string, numCols)
+	for c, f := range rec.Schema().Fields() {
+		cells[0][c] = truncateCell(f.Name)
+	}
+	for r := 0; r < shown; r++ {
+		cells[r+1] = make([]string, numCols)
+		for c := 0; c < numCols; c++ {
+			cells[r+1][c] = truncateCell(formatCell(rec.Column(c), r))
+		}
+	}
+
+	widths := make([]int, numCols)
+	for _, row := range cells {
+		for c, cell := range row {
+			widths[c] = max(widths[c], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var sb strings.Builder
+	border := func() {
+		sb.WriteByte('+')
+		for _, width := range widths {
+			sb.WriteString(strings.Repeat("-", width+2))
+			sb.WriteByte('+')
+		}
+		sb.WriteByte('\n')
+	}
+	line := func(row []string) {
+		sb.WriteByte('|')
+		for c, cell := range row {
+			sb.WriteByte(' ')
+			sb.WriteString(cell)
+			sb.WriteString(strings.Repeat(" ", widths[c]-utf8.RuneCountInString(cell)+1))
+			sb.WriteByte('|')
+		}
+		sb.WriteByte('\n')
+	}
+
+	border()
+	line(cells[0])
+	border()
+	for _, row := range cells[1:] {
+		line(row)
+	}
+	border()
+	fmt.Fprintf(&sb, "(%d of %d rows)\n", shown, numRows)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatCell renders a single value of arr for display.
+func formatCell(arr arrow.Array, i int) string {
+	if arr.IsNull(i) {
+		return "NULL"
+	}
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return strconv.FormatBool(a.Value(i))
+	case *array.Int8:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int16:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int32:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int64:
+		return strconv.FormatInt(a.Value(i), 10)
+	case *array.Uint8:
+		return strconv.FormatUint(uint64(a.Value(i)), 10)
+	case *array.Uint16:
+		return strconv.FormatUint(uint64(a.Value(i)), 10)
+	case *array.Uint32:
+		return strconv.FormatUint(uint64(a.Value(i)), 10)
+	case *array.Uint64:
+		return strconv.FormatUint(a.Value(i), 10)
+	case *array.Float32:
+		return strconv.FormatFloat(float64(a.Value(i)), 'g', -1, 32)
+	case *array.Float64:
+		return strconv.FormatFloat(a.Value(i), 'g', -1, 64)
+	case *array.String:
+		return a.Value(i)
+	case *array.Binary:
+		return "0x" + hex.EncodeToString(a.Value(i))
+	case *array.Date32:
+		return a.Value(i).FormattedString()
+	case *array.Date64:
+		return a.Value(i).FormattedString()
+	case *array.Time32:
+		return a.Value(i).FormattedString(a.DataType().(*arrow.Time32Type).Unit)
+	case *array.Time64:
+		return a.Value(i).FormattedString(a.DataType().(*arrow.Time64Type).Unit)
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return a.Value(i).ToTime(unit).Format("2006-01-02 15:04:05.999999999Z07:00")
+	case *array.Decimal128:
+		return a.Value(i).ToString(a.DataType().(*arrow.Decimal128Type).Scale)
+	default:
+		return arr.ValueStr(i)
+	}
+}
+
+// truncateCell shortens s to maxDumpCellWidth runes, marking the cut.
+func truncateCell(s string) string {
+	if utf8.RuneCountInString(s) <= maxDumpCellWidth {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxDumpCellWidth-3]) + "..."
+}
@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpRecord(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues(
+		[]string{"alice", "", strings.Repeat("x", 40)}, []bool{true, false, true})
+	b.Field(2).(*array.BooleanBuilder).AppendValues([]bool{true, false, false}, []bool{true, true, false})
+	b.Field(3).(*array.Float64Builder).AppendValues([]float64{1.5, 0, 2.25}, []bool{true, false, true})
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	t.Run("all rows", func(t *testing.T) {
+		var sb strings.Builder
+		require.NoError(t, DumpRecord(&sb, rec, 0))
+
+		want := "" +
+			"+----+----------------------------------+-------+-------+\n" +
+			"| id | name                             | ok    | score |\n" +
+			"+----+----------------------------------+-------+-------+\n" +
+			"| 1  | alice                            | true  | 1.5   |\n" +
+			"| 2  | NULL                             | false | NULL  |\n" +
+			"| 3  | xxxxxxxxxxxxxxxxxxxxxxxxxxxxx... | NULL  | 2.25  |\n" +
+			"+----+----------------------------------+-------+-------+\n" +
+			"(3 of 3 rows)\n"
+		assert.Equal(t, want, sb.String())
+	})
+
+	t.Run("max rows", func(t *testing.T) {
+		var sb strings.Builder
+		require.NoError(t, DumpRecord(&sb, rec, 1))
+
+		want := "" +
+			"+----+-------+------+-------+\n" +
+			"| id | name  | ok   | score |\n" +
+			"+----+-------+------+-------+\n" +
+			"| 1  | alice | true | 1.5   |\n" +
+			"+----+-------+------+-------+\n" +
+			"(1 of 3 rows)\n"
+		assert.Equal(t, want, sb.String())
+	})
+}
@@ -0,0 +1,32 @@
+package converter
+
+// CollectedErrors returns the append errors collected so far by
+// WithErrorCollection, in the order they occurred. Each error carries the
+// row and column it was raised for in its context.
+func (r *BatchReader) CollectedErrors() []error {
+	return r.collected
+}
+
+// collectError records err for WithErrorCollection and appends a null in
+// place of the failed value. It reports false if errors are not collected,
+// the budget is spent, or the failed append left a partial value behind;
+// before is the length of the column's builder prior to the append.
+func (r *BatchReader) collectError(colIdx, before int, err error) bool {
+	if len(r.collected) >= r.opts.maxErrors {
+		return false
+	}
+	// Bulk list columns buffer values outside the builder, so its length
+	// cannot tell whether anything was appended.
+	if r.lists != nil && r.lists[colIdx] != nil {
+		return false
+	}
+	fb := r.builder.Field(colIdx)
+	if fb.Len() != before {
+		return false
+	}
+
+	fb.AppendNull()
+	r.collected = append(r.collected, err)
+	r.logger.Warn().Err(err).Msg("BatchReader: appended null in place of a value that failed to convert")
+	return true
+}
@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+const dirtyQuery = `SELECT * FROM (VALUES (1, '10'), (2, 'x'), (3, '30'), (4, '4O'), (5, NULL), (6, '?')) t(id, amount)`
+
+func TestBatchReaderErrorCollection(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc, queryRows(t, dirtyQuery), logger,
+		WithNumericCast("amount", arrow.PrimitiveTypes.Int64, NumberFormat{}),
+		WithErrorCollection(5),
+		WithBatchSize(4))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	var amounts []interface{}
+	for reader.Next() {
+		rec := reader.Record()
+		col := rec.Column(1).(*array.Int64)
+		for i := 0; i < col.Len(); i++ {
+			if col.IsNull(i) {
+				amounts = append(amounts, nil)
+			} else {
+				amounts = append(amounts, col.Value(i))
+			}
+		}
+		rec.Release()
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, []interface{}{int64(10), nil, int64(30), nil, nil, nil}, amounts)
+
+	collected := reader.CollectedErrors()
+	require.Len(t, collected, 3)
+	for i, row := range []string{"1", "3", "5"} {
+		ctx := errors.Context(collected[i])
+		assert.Equal(t, "amount", ctx["column"])
+		assert.Equal(t, row, ctx["row"])
+	}
+}
+
+func TestBatchReaderErrorCollectionBudget(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, dirtyQuery), logger,
+		WithNumericCast("amount", arrow.PrimitiveTypes.Int64, NumberFormat{}),
+		WithErrorCollection(1))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	var rows int64
+	for reader.Next() {
+		rec := reader.Record()
+		rows += rec.NumRows()
+		rec.Release()
+	}
+	// The second bad value fails the reader; the rows before it are kept
+	require.Error(t, reader.Err())
+	assert.Equal(t, "3", errors.Context(reader.Err())["row"])
+	assert.Equal(t, int64(3), rows)
+	assert.Len(t, reader.CollectedErrors(), 1)
+}
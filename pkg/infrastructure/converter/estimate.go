@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// defaultAverageValueWidth is the assumed average size in bytes of a
+// variable-width value when estimating batch sizes.
+const defaultAverageValueWidth = 32
+
+// EstimateBatchBytes estimates the minimum buffer bytes needed to build a
+// full batch with the reader's schema and batch size. Variable-width values
+// are assumed to average the width configured with WithAverageValueWidth.
+func (r *BatchReader) EstimateBatchBytes() int64 {
+	avg := r.averageValueWidth()
+	var total int64
+	for _, f := range r.schema.Fields() {
+		total += estimateColumnBytes(f.Type, int64(r.batchSize), avg)
+	}
+	return total
+}
+
+// estimateColumnBytes estimates the buffers of a column of n values,
+// including its validity bitmap.
+func estimateColumnBytes(dt arrow.DataType, n, avg int64) int64 {
+	bitmap := (n + 7) / 8
+
+	switch t := dt.(type) {
+	case *arrow.BooleanType:
+		return bitmap + (n+7)/8
+	case arrow.FixedWidthDataType:
+		return bitmap + n*int64(t.BitWidth()/8)
+	case arrow.BinaryDataType:
+		// 32-bit offsets plus the value bytes
+		return bitmap + (n+1)*4 + n*avg
+	case *arrow.ListType:
+		return bitmap + (n+1)*4 + estimateColumnBytes(t.Elem(), n, avg)
+	case *arrow.StructType:
+		total := bitmap
+		for _, f := range t.Fields() {
+			total += estimateColumnBytes(f.Type, n, avg)
+		}
+		return total
+	default:
+		return bitmap + n*avg
+	}
+}
+
+// averageValueWidth returns the configured average variable-width value size.
+func (r *BatchReader) averageValueWidth() int64 {
+	if r.opts.averageValueWidth > 0 {
+		return int64(r.opts.averageValueWidth)
+	}
+	return defaultAverageValueWidth
+}
+
+// checkOffsetOverflow reports whether a full batch risks overflowing the
+// 32-bit offsets of a String or Binary column, assuming values of the
+// average width. The risk is an error with WithStrictOffsetCheck and a
+// logged warning otherwise.
+func (r *BatchReader) checkOffsetOverflow() error {
+	avg := r.averageValueWidth()
+	for _, f := range r.schema.Fields() {
+		if !hasInt32Offsets(f.Type) || int64(r.batchSize)*avg <= math.MaxInt32 {
+			continue
+		}
+		msg := fmt.Sprintf("batch of %d rows with values averaging %d bytes may overflow the 32-bit offsets of column %q; "+
+			"use a smaller batch size or LargeString", r.batchSize, avg, f.Name)
+		if r.opts.strictOffsets {
+			return errors.New(errors.CodeInvalidRequest, msg)
+		}
+		r.logger.Warn().Str("column", f.Name).Int("batch_size", r.batchSize).Int64("average_width", avg).Msg(msg)
+	}
+	return nil
+}
+
+// hasInt32Offsets reports whether dt is or contains a String or Binary type.
+func hasInt32Offsets(dt arrow.DataType) bool {
+	switch t := dt.(type) {
+	case *arrow.StringType, *arrow.BinaryType:
+		return true
+	case *arrow.DictionaryType:
+		return hasInt32Offsets(t.ValueType)
+	case arrow.ExtensionType:
+		return hasInt32Offsets(t.StorageType())
+	case arrow.NestedType:
+		for _, f := range t.Fields() {
+			if hasInt32Offsets(f.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
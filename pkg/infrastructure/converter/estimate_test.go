@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateBatchBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		fields    []arrow.Field
+		batchSize int
+		opts      []Option
+		want      int64
+	}{
+		{
+			name: "all int64",
+			fields: []arrow.Field{
+				{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "b", Type: arrow.PrimitiveTypes.Int64},
+			},
+			batchSize: 1000,
+			want:      2 * (1000*8 + 125),
+		},
+		{
+			name:      "boolean is bit packed",
+			fields:    []arrow.Field{{Name: "flag", Type: arrow.FixedWidthTypes.Boolean}},
+			batchSize: 100,
+			want:      13 + 13,
+		},
+		{
+			name:      "variable width uses average",
+			fields:    []arrow.Field{{Name: "s", Type: arrow.BinaryTypes.String}},
+			batchSize: 10,
+			opts:      []Option{WithAverageValueWidth(5)},
+			want:      2 + 11*4 + 10*5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &BatchReader{
+				schema:    arrow.NewSchema(tt.fields, nil),
+				batchSize: tt.batchSize,
+				opts:      newReaderOptions(tt.opts),
+			}
+			assert.Equal(t, tt.want, r.EstimateBatchBytes())
+		})
+	}
+}
+
+func TestBatchReaderOffsetOverflowCheck(t *testing.T) {
+	const query = "SELECT 'x' AS s, 1 AS i"
+
+	t.Run("warning", func(t *testing.T) {
+		var logs logCapture
+		logger := zerolog.New(zerolog.TestWriter{T: &logs})
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithBatchSize(1<<20), WithAverageValueWidth(4096))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		var warnings []map[string]interface{}
+		for _, line := range logs.lines {
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			if entry["level"] == "warn" {
+				warnings = append(warnings, entry)
+			}
+		}
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "s", warnings[0]["column"])
+		assert.Contains(t, warnings[0]["message"], "LargeString")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		logger := zerolog.New(zerolog.NewTestWriter(t))
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithBatchSize(1<<20), WithAverageValueWidth(4096), WithStrictOffsetCheck())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `column "s"`)
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		logger := zerolog.New(zerolog.NewTestWriter(t))
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithBatchSize(1<<20), WithStrictOffsetCheck())
+		require.NoError(t, err)
+		reader.Release()
+	})
+}
@@ -0,0 +1,118 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// jsonType is the type inferred for JSON columns. String storage is always
+// valid for it, so creating it cannot fail.
+var jsonType, _ = extensions.NewJSONType(arrow.BinaryTypes.String)
+
+// isJSONType reports whether dt is the JSON extension type.
+func isJSONType(dt arrow.DataType) bool {
+	ext, ok := dt.(arrow.ExtensionType)
+	return ok && ext.ExtensionName() == jsonType.ExtensionName()
+}
+
+// GeometryExtensionName is the extension name of GeometryType.
+const GeometryExtensionName = "porter.geometry"
+
+// GeometryType is an extension type for spatial values encoded as
+// well-known binary (WKB), as DuckDB's spatial extension exports them. The
+// converter never infers it; it is registered so that consumers building
+// geometry columns themselves can exchange them over IPC and Flight.
+type GeometryType struct {
+	arrow.ExtensionBase
+}
+
+// NewGeometryType returns a GeometryType backed by Binary storage.
+func NewGeometryType() *GeometryType {
+	return &GeometryType{ExtensionBase: arrow.ExtensionBase{Storage: arrow.BinaryTypes.Binary}}
+}
+
+// ArrayType returns the array type for geometry values.
+func (*GeometryType) ArrayType() reflect.Type { return reflect.TypeOf(GeometryArray{}) }
+
+// ExtensionName returns the registered name of the type.
+func (*GeometryType) ExtensionName() string { return GeometryExtensionName }
+
+// Serialize returns the type's metadata, which is empty.
+func (*GeometryType) Serialize() string { return "" }
+
+// Deserialize reconstructs the type from IPC metadata.
+func (*GeometryType) Deserialize(storageType arrow.DataType, _ string) (arrow.ExtensionType, error) {
+	if !arrow.TypeEqual(storageType, arrow.BinaryTypes.Binary) {
+		return nil, fmt.Errorf("invalid storage type for %s: %s", GeometryExtensionName, storageType)
+	}
+	return NewGeometryType(), nil
+}
+
+// ExtensionEquals reports whether other is also a geometry type.
+func (t *GeometryType) ExtensionEquals(other arrow.ExtensionType) bool {
+	return t.ExtensionName() == other.ExtensionName()
+}
+
+// GeometryArray holds WKB-encoded geometry values.
+type GeometryArray struct {
+	array.ExtensionArrayBase
+}
+
+// extensionTypes returns the extension types registered by
+// RegisterExtensionTypes: UUID and JSON, which the converter infers for
+// columns of those types, and geometry, which is only provided for
+// consumers.
+func extensionTypes() []arrow.ExtensionType {
+	return []arrow.ExtensionType{
+		extensions.NewUUIDType(),
+		jsonType,
+		NewGeometryType(),
+	}
+}
+
+var (
+	extensionMu        sync.Mutex
+	registeredByPorter []string
+)
+
+// RegisterExtensionTypes registers the converter's extension types with the
+// Arrow extension registry, so that IPC and Flight readers decode them as
+// extension arrays rather than their storage types. Types
+// that are already registered are left alone. It is safe to call more than
+// once.
+func RegisterExtensionTypes() error {
+	extensionMu.Lock()
+	defer extensionMu.Unlock()
+	for _, typ := range extensionTypes() {
+		if arrow.GetExtensionType(typ.ExtensionName()) != nil {
+			continue
+		}
+		if err := arrow.RegisterExtensionType(typ); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "failed to register extension type %s", typ.ExtensionName())
+		}
+		registeredByPorter = append(registeredByPorter, typ.ExtensionName())
+	}
+	return nil
+}
+
+// UnregisterExtensionTypes removes the extension types added by
+// RegisterExtensionTypes, leaving types registered by others in place. It
+// is intended for tests.
+func UnregisterExtensionTypes() error {
+	extensionMu.Lock()
+	defer extensionMu.Unlock()
+	for _, name := range registeredByPorter {
+		if err := arrow.UnregisterExtensionType(name); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "failed to unregister extension type %s", name)
+		}
+	}
+	registeredByPorter = nil
+	return nil
+}
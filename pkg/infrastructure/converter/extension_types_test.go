@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ipcRoundTrip writes rec as an IPC stream and reads the first record back.
+func ipcRoundTrip(t *testing.T, rec arrow.Record) arrow.Record {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(rec.Schema()))
+	require.NoError(t, w.Write(rec))
+	require.NoError(t, w.Close())
+
+	r, err := ipc.NewReader(&buf)
+	require.NoError(t, err)
+	defer r.Release()
+	require.True(t, r.Next(), r.Err())
+	out := r.Record()
+	out.Retain()
+	return out
+}
+
+func TestExtensionTypesIPCRoundTrip(t *testing.T) {
+	mem := memory.NewGoAllocator()
+
+	storage := array.NewBinaryBuilder(mem, arrow.BinaryTypes.Binary)
+	defer storage.Release()
+	storage.Append([]byte{0x01, 0x01, 0x00, 0x00, 0x00})
+	storage.AppendNull()
+	storageArr := storage.NewArray()
+	defer storageArr.Release()
+
+	geom := array.NewExtensionArrayWithStorage(NewGeometryType(), storageArr)
+	defer geom.Release()
+	schema := arrow.NewSchema([]arrow.Field{{Name: "geom", Type: geom.DataType(), Nullable: true}}, nil)
+	rec := array.NewRecord(schema, []arrow.Array{geom}, int64(geom.Len()))
+	defer rec.Release()
+
+	require.NoError(t, RegisterExtensionTypes())
+	require.NoError(t, RegisterExtensionTypes(), "registration must be idempotent")
+
+	out := ipcRoundTrip(t, rec)
+	defer out.Release()
+	require.IsType(t, &GeometryType{}, out.Schema().Field(0).Type)
+	got, ok := out.Column(0).(*GeometryArray)
+	require.True(t, ok)
+	assert.True(t, array.Equal(geom, got))
+
+	require.NoError(t, UnregisterExtensionTypes())
+	assert.Nil(t, arrow.GetExtensionType(GeometryExtensionName))
+
+	// Without the registration only the storage type survives.
+	out = ipcRoundTrip(t, rec)
+	defer out.Release()
+	assert.Equal(t, arrow.BinaryTypes.Binary, out.Schema().Field(0).Type)
+}
+
+func TestBatchReaderJSONRawMessage(t *testing.T) {
+	jsonType, err := extensions.NewJSONType(arrow.BinaryTypes.String)
+	require.NoError(t, err)
+	schema := arrow.NewSchema([]arrow.Field{{Name: "j", Type: jsonType, Nullable: true}}, nil)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	r := &BatchReader{builder: builder}
+
+	// Drivers that decode JSON columns hand back json.RawMessage through an
+	// interface{} destination.
+	var raw interface{} = json.RawMessage(`{"a": [1, 2]}`)
+	require.NoError(t, r.appendValue(0, &raw))
+	raw = json.RawMessage(nil)
+	require.NoError(t, r.appendValue(0, &raw))
+
+	rec := builder.NewRecord()
+	defer rec.Release()
+	assert.Equal(t, "arrow.json", rec.Schema().Field(0).Type.(arrow.ExtensionType).ExtensionName())
+	col, ok := rec.Column(0).(*extensions.JSONArray)
+	require.True(t, ok, "got %T", rec.Column(0))
+	assert.Equal(t, `{"a": [1, 2]}`, col.Storage().(*array.String).Value(0))
+	assert.True(t, col.IsNull(1))
+
+	t.Run("string from driver", func(t *testing.T) {
+		logger := zerolog.New(zerolog.NewTestWriter(t))
+		rows := queryRows(t, `SELECT '{"b": true}' AS j`)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, rows, logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col, ok := rec.Column(0).(*extensions.JSONArray)
+		require.True(t, ok, "got %T", rec.Column(0))
+		assert.Equal(t, `{"b": true}`, col.Storage().(*array.String).Value(0))
+	})
+}
+
+func TestBatchReaderJSONAndUUIDColumns(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	rows := queryRows(t, `SELECT * FROM (VALUES
+		('{"a":1}'::JSON, '00000000-0000-0000-0000-000000000001'::UUID),
+		('[1,"x"]'::JSON, NULL),
+		('"s"'::JSON, NULL),
+		(NULL, NULL)) t(j, u)`)
+	reader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	assert.True(t, isJSONType(reader.Schema().Field(0).Type))
+	assert.Equal(t, "arrow.uuid", reader.Schema().Field(1).Type.(arrow.ExtensionType).ExtensionName())
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+
+	j := rec.Column(0).(*extensions.JSONArray)
+	assert.Equal(t, `{"a":1}`, j.ValueStr(0))
+	assert.Equal(t, `[1,"x"]`, j.ValueStr(1))
+	assert.Equal(t, `"s"`, j.ValueStr(2))
+	assert.True(t, j.IsNull(3))
+
+	u := rec.Column(1).(*extensions.UUIDArray)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", u.ValueStr(0))
+	assert.True(t, u.IsNull(1))
+
+	assert.False(t, reader.Next())
+	assert.NoError(t, reader.Err())
+}
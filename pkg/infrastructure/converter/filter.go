@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Filter returns a reader over the rows of reader for which predicate
+// returns true. Batches are filtered as they are read: batches in which
+// every row matches are passed on unchanged, batches without a match are
+// dropped without building a record, and the others are compacted into
+// records holding only the matching rows. Filter takes ownership of reader.
+func Filter(reader *BatchReader, predicate func(RowView) bool) *BatchReader {
+	f := &rowFilter{input: reader, predicate: predicate}
+	return newDerivedReader(reader.Schema(), reader.allocator, reader.logger,
+		recordSource{next: f.next, close: reader.Release})
+}
+
+// rowFilter produces the filtered records of Filter.
+type rowFilter struct {
+	input     *BatchReader
+	predicate func(RowView) bool
+}
+
+// next returns the next record with at least one matching row, or nil at
+// the end of the input.
+func (f *rowFilter) next() (arrow.Record, error) {
+	for f.input.Next() {
+		rec, err := f.input.TakeRecord()
+		if err != nil {
+			return nil, err
+		}
+		out, err := f.filter(rec)
+		rec.Release()
+		if err != nil || out != nil {
+			return out, err
+		}
+	}
+	if err := f.input.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to read filter input")
+	}
+	return nil, nil
+}
+
+// filter returns the matching rows of rec, or nil if there are none. The
+// caller keeps its reference to rec.
+func (f *rowFilter) filter(rec arrow.Record) (arrow.Record, error) {
+	view := RowView{fields: rec.Schema().Fields(), rec: rec}
+	n := rec.NumRows()
+
+	// Matching rows are collected as runs of consecutive rows so that they
+	// can be sliced out without copying values one by one.
+	var (
+		runs    []arrow.Record
+		matched int64
+		start   int64 = -1
+	)
+	defer func() {
+		for _, r := range runs {
+			r.Release()
+		}
+	}()
+	for i := int64(0); i <= n; i++ {
+		keep := false
+		if i < n {
+			view.row = int(i)
+			keep = f.predicate(view)
+		}
+		switch {
+		case keep && start < 0:
+			start = i
+		case !keep && start >= 0:
+			runs = append(runs, rec.NewSlice(start, i))
+			matched += i - start
+			start = -1
+		}
+	}
+
+	switch {
+	case matched == 0:
+		return nil, nil
+	case matched == n:
+		rec.Retain()
+		return rec, nil
+	case len(runs) == 1:
+		out := runs[0]
+		runs = nil
+		return out, nil
+	}
+	return concatRecords(f.input.allocator, rec.Schema(), runs, matched)
+}
@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// run filters ids 0-15, read in batches of four, and returns the ids
+	// and number of records returned.
+	run := func(t *testing.T, predicate func(id int64) bool) ([]int64, int) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc,
+			queryRows(t, "SELECT i AS id, 'row ' || i AS label FROM range(16) t(i)"),
+			logger, WithBatchSize(4))
+		require.NoError(t, err)
+
+		filtered := Filter(reader, func(row RowView) bool {
+			return predicate(row.Value(row.Index("id")).(int64))
+		})
+		defer filtered.Release()
+
+		var (
+			ids     []int64
+			records int
+		)
+		for filtered.Next() {
+			records++
+			rec := filtered.Record()
+			batch := rec.Column(0).(*array.Int64).Int64Values()
+			labels := rec.Column(1).(*array.String)
+			for i, id := range batch {
+				assert.Equal(t, fmt.Sprintf("row %d", id), labels.Value(i))
+			}
+			ids = append(ids, batch...)
+			rec.Release()
+		}
+		require.NoError(t, filtered.Err())
+		return ids, records
+	}
+
+	t.Run("even ids", func(t *testing.T) {
+		ids, records := run(t, func(id int64) bool { return id%2 == 0 })
+		assert.Equal(t, []int64{0, 2, 4, 6, 8, 10, 12, 14}, ids)
+		assert.Equal(t, 4, records)
+	})
+
+	t.Run("whole batches", func(t *testing.T) {
+		// 0-3 is kept whole, 4-11 has no match and 12-15 is partly kept
+		ids, records := run(t, func(id int64) bool { return id < 4 || id == 12 || id == 15 })
+		assert.Equal(t, []int64{0, 1, 2, 3, 12, 15}, ids)
+		assert.Equal(t, 2, records)
+	})
+}
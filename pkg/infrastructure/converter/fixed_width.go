@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// isFixedWidthSchema reports whether every field of schema is a
+// non-nullable integer or floating point column, which readFixedWidthBatch
+// can scan straight into Arrow buffers.
+func isFixedWidthSchema(schema *arrow.Schema) bool {
+	if schema.NumFields() == 0 {
+		return false
+	}
+	for _, field := range schema.Fields() {
+		if field.Nullable {
+			return false
+		}
+		switch field.Type.ID() {
+		case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+			arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+			arrow.FLOAT32, arrow.FLOAT64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// readFixedWidthBatch reads up to batchSize rows of a fixed-width schema,
+// scanning each value directly into its slot of the column's data buffer so
+// that no builder or per-value append is involved. It returns the number of
+// rows read and false if the batch is empty or failed.
+func (r *BatchReader) readFixedWidthBatch(batchSize int) (int, bool) {
+	fields := r.schema.Fields()
+	buffers := make([]*memory.Buffer, len(fields))
+	widths := make([]int, len(fields))
+	for i, field := range fields {
+		widths[i] = field.Type.(arrow.FixedWidthDataType).Bytes()
+		buffers[i] = memory.NewResizableBuffer(r.allocator)
+		buffers[i].Resize(batchSize * widths[i])
+	}
+	release := func() {
+		for _, buf := range buffers {
+			buf.Release()
+		}
+	}
+
+	// Scanned columns come first; a trailing row number column is filled in
+	// rather than scanned.
+	dest := make([]interface{}, len(r.rowDest))
+	n := 0
+	for ; n < batchSize; n++ {
+		if !r.nextRow() {
+			break
+		}
+		for i, field := range fields[:len(dest)] {
+			dest[i] = fixedWidthSlot(field.Type.ID(), buffers[i].Bytes(), n)
+		}
+		if len(dest) < len(fields) {
+			arrow.Int64Traits.CastFromBytes(buffers[len(dest)].Bytes())[n] = r.emitted + int64(n)
+		}
+		if err := r.rows.Scan(dest...); err != nil {
+			release()
+			r.err = errors.Wrap(err, errors.CodeQueryFailed, "failed to scan row")
+			return 0, false
+		}
+	}
+	if n == 0 {
+		release()
+		r.err = r.rowsErr()
+		return 0, false
+	}
+
+	cols := make([]arrow.Array, len(fields))
+	for i, field := range fields {
+		buffers[i].Resize(n * widths[i])
+		data := array.NewData(field.Type, n, []*memory.Buffer{nil, buffers[i]}, nil, 0, 0)
+		cols[i] = array.MakeFromData(data)
+		data.Release()
+	}
+	release()
+
+	r.record = array.NewRecord(r.schema, cols, int64(n))
+	for _, col := range cols {
+		col.Release()
+	}
+	return n, true
+}
+
+// fixedWidthSlot returns a pointer to the row'th value of a buffer holding
+// values of type id, for use as a scan destination.
+func fixedWidthSlot(id arrow.Type, buf []byte, row int) interface{} {
+	switch id {
+	case arrow.INT8:
+		return &arrow.Int8Traits.CastFromBytes(buf)[row]
+	case arrow.INT16:
+		return &arrow.Int16Traits.CastFromBytes(buf)[row]
+	case arrow.INT32:
+		return &arrow.Int32Traits.CastFromBytes(buf)[row]
+	case arrow.INT64:
+		return &arrow.Int64Traits.CastFromBytes(buf)[row]
+	case arrow.UINT8:
+		return &arrow.Uint8Traits.CastFromBytes(buf)[row]
+	case arrow.UINT16:
+		return &arrow.Uint16Traits.CastFromBytes(buf)[row]
+	case arrow.UINT32:
+		return &arrow.Uint32Traits.CastFromBytes(buf)[row]
+	case arrow.UINT64:
+		return &arrow.Uint64Traits.CastFromBytes(buf)[row]
+	case arrow.FLOAT32:
+		return &arrow.Float32Traits.CastFromBytes(buf)[row]
+	default:
+		return &arrow.Float64Traits.CastFromBytes(buf)[row]
+	}
+}
@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixedWidthQuery = `SELECT
+	(i % 100)::TINYINT AS i8, i::SMALLINT AS i16, i::INTEGER AS i32, -i::BIGINT AS i64,
+	(i % 200)::UTINYINT AS u8, i::USMALLINT AS u16, i::UINTEGER AS u32, i::UBIGINT AS u64,
+	(i / 4)::REAL AS f32, (i / 3)::DOUBLE AS f64
+FROM range(1050) t(i) ORDER BY i`
+
+var fixedWidthSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "i8", Type: arrow.PrimitiveTypes.Int8},
+	{Name: "i16", Type: arrow.PrimitiveTypes.Int16},
+	{Name: "i32", Type: arrow.PrimitiveTypes.Int32},
+	{Name: "i64", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "u8", Type: arrow.PrimitiveTypes.Uint8},
+	{Name: "u16", Type: arrow.PrimitiveTypes.Uint16},
+	{Name: "u32", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "u64", Type: arrow.PrimitiveTypes.Uint64},
+	{Name: "f32", Type: arrow.PrimitiveTypes.Float32},
+	{Name: "f64", Type: arrow.PrimitiveTypes.Float64},
+}, nil)
+
+// readFixedWidth reads fixedWidthQuery in batches of 100, optionally forcing
+// the generic builder path.
+func readFixedWidth(t *testing.T, alloc memory.Allocator, fixedWidth bool) []arrow.Record {
+	t.Helper()
+
+	reader, err := NewBatchReaderWithSchema(alloc, fixedWidthSchema, queryRows(t, fixedWidthQuery), zerolog.Nop())
+	require.NoError(t, err)
+	defer reader.Release()
+	require.True(t, reader.fixedWidth)
+	reader.fixedWidth = fixedWidth
+	reader.SetBatchSize(100)
+
+	var recs []arrow.Record
+	for reader.Next() {
+		recs = append(recs, reader.Record())
+	}
+	require.NoError(t, reader.Err())
+	return recs
+}
+
+func TestBatchReaderFixedWidth(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	fast := readFixedWidth(t, alloc, true)
+	generic := readFixedWidth(t, alloc, false)
+	require.Len(t, fast, 11)
+	require.Len(t, generic, len(fast))
+	for i := range fast {
+		assert.True(t, array.RecordEqual(generic[i], fast[i]), "batch %d differs", i)
+		fast[i].Release()
+		generic[i].Release()
+	}
+}
+
+func TestIsFixedWidthSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []arrow.Field
+		want   bool
+	}{
+		{name: "numeric", fields: fixedWidthSchema.Fields(), want: true},
+		{name: "nullable", fields: []arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true}}},
+		{name: "boolean", fields: []arrow.Field{{Name: "a", Type: arrow.FixedWidthTypes.Boolean}}},
+		{name: "string", fields: []arrow.Field{{Name: "a", Type: arrow.BinaryTypes.String}}},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isFixedWidthSchema(arrow.NewSchema(tt.fields, nil)))
+		})
+	}
+}
+
+func BenchmarkBatchReaderFixedWidth(b *testing.B) {
+	const query = "SELECT i::INTEGER AS a, i::BIGINT AS b, (i / 2)::DOUBLE AS c FROM range(100000) t(i)"
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "b", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "c", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	for _, fixedWidth := range []bool{false, true} {
+		name := "generic"
+		if fixedWidth {
+			name = "fixed width"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(b, query), zerolog.Nop())
+				require.NoError(b, err)
+				reader.fixedWidth = fixedWidth
+				for reader.Next() {
+					reader.Record().Release()
+				}
+				require.NoError(b, reader.Err())
+				reader.Release()
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/marcboeker/go-duckdb/v2"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// appendInterval appends a DuckDB interval to a MonthDayNano builder or, for
+// WithDayTimeInterval, to a DayTime builder. DayTime intervals cannot hold
+// months or sub-millisecond parts, and Month intervals of a predefined
+// schema nothing but months, so such values are errors.
+func appendInterval(fb array.Builder, v duckdb.Interval) error {
+	switch b := fb.(type) {
+	case *array.MonthIntervalBuilder:
+		if v.Days != 0 || v.Micros != 0 {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("interval of %d days and %dus cannot be represented as a month interval", v.Days, v.Micros))
+		}
+		b.Append(arrow.MonthInterval(v.Months))
+	case *array.MonthDayNanoIntervalBuilder:
+		b.Append(arrow.MonthDayNanoInterval{Months: v.Months, Days: v.Days, Nanoseconds: v.Micros * 1000})
+	case *array.DayTimeIntervalBuilder:
+		if v.Months != 0 {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("interval of %d months cannot be represented as a day-time interval", v.Months))
+		}
+		if v.Micros%1000 != 0 || v.Micros/1000 > math.MaxInt32 || v.Micros/1000 < math.MinInt32 {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("interval time part of %dus cannot be represented in milliseconds", v.Micros))
+		}
+		b.Append(arrow.DayTimeInterval{Days: v.Days, Milliseconds: int32(v.Micros / 1000)})
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for interval value", fb))
+	}
+	return nil
+}
+
+// appendDuration appends a time.Duration, as returned by some drivers for
+// interval columns, to a Duration builder of any unit or to an interval
+// builder. Durations that the column cannot hold exactly are errors.
+func appendDuration(fb array.Builder, d time.Duration) error {
+	switch b := fb.(type) {
+	case *array.DurationBuilder:
+		unit := time.Duration(b.Type().(*arrow.DurationType).Unit.Multiplier())
+		if d%unit != 0 {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("duration %s cannot be represented in %s", d, b.Type()))
+		}
+		b.Append(arrow.Duration(d / unit))
+	case *array.MonthDayNanoIntervalBuilder:
+		b.Append(arrow.MonthDayNanoInterval{Nanoseconds: int64(d)})
+	case *array.DayTimeIntervalBuilder:
+		days, rest := d/(24*time.Hour), d%(24*time.Hour)
+		if rest%time.Millisecond != 0 || days > math.MaxInt32 || days < math.MinInt32 {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("duration %s cannot be represented as a day-time interval", d))
+		}
+		b.Append(arrow.DayTimeInterval{Days: int32(days), Milliseconds: int32(rest / time.Millisecond)})
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected builder type %T for duration value", fb))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderIntervals(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	query := `SELECT * FROM (VALUES (INTERVAL 3 DAY), (INTERVAL '1 day 02:00:03.5'), (NULL)) t(i)`
+
+	t.Run("month day nano", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query+" UNION ALL SELECT INTERVAL 1 MONTH"), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.FixedWidthTypes.MonthDayNanoInterval, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.MonthDayNanoInterval)
+		assert.Equal(t, arrow.MonthDayNanoInterval{Days: 3}, col.Value(0))
+		assert.Equal(t, arrow.MonthDayNanoInterval{Days: 1, Nanoseconds: 7203500000000}, col.Value(1))
+		assert.True(t, col.IsNull(2))
+		assert.Equal(t, arrow.MonthDayNanoInterval{Months: 1}, col.Value(3))
+	})
+
+	t.Run("day time", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithDayTimeInterval())
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.FixedWidthTypes.DayTimeInterval, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.DayTimeInterval)
+		assert.Equal(t, arrow.DayTimeInterval{Days: 3}, col.Value(0))
+		assert.Equal(t, arrow.DayTimeInterval{Days: 1, Milliseconds: 7203500}, col.Value(1))
+		assert.True(t, col.IsNull(2))
+	})
+
+	t.Run("day time with months", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT INTERVAL 1 MONTH AS i"), logger, WithDayTimeInterval())
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "1 months cannot be represented")
+	})
+}
+
+func TestBatchReaderDurations(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// No driver at hand returns time.Duration, so a fake one reports the
+	// scan type without a type name, as such drivers do.
+	result := &fakeResult{
+		columns:   []string{"d"},
+		typeNames: []string{""},
+		scanTypes: []reflect.Type{reflect.TypeOf(time.Duration(0))},
+		rows:      [][]driver.Value{{1500 * time.Millisecond}, {nil}, {-36 * time.Hour}},
+	}
+	db := sql.OpenDB(fakeConnector{result})
+	defer db.Close()
+	rows, err := db.Query("SELECT d")
+	require.NoError(t, err)
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+	assert.Equal(t, arrow.FixedWidthTypes.Duration_ns, reader.Schema().Field(0).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	col := rec.Column(0).(*array.Duration)
+	assert.Equal(t, arrow.Duration(1500*time.Millisecond), col.Value(0))
+	assert.True(t, col.IsNull(1))
+	assert.Equal(t, arrow.Duration(-36*time.Hour), col.Value(2))
+
+	t.Run("builders", func(t *testing.T) {
+		d := 26*time.Hour + 1500*time.Millisecond
+		for _, tc := range []struct {
+			dt   arrow.DataType
+			want string
+			err  string
+		}{
+			{dt: arrow.FixedWidthTypes.Duration_ms, want: "93601500ms"},
+			{dt: arrow.FixedWidthTypes.Duration_s, err: "cannot be represented in duration[s]"},
+			{dt: arrow.FixedWidthTypes.MonthDayNanoInterval, want: `{"months":0,"days":0,"nanoseconds":93601500000000}`},
+			{dt: arrow.FixedWidthTypes.DayTimeInterval, want: `{"days":1,"milliseconds":7201500}`},
+		} {
+			b := array.NewBuilder(memory.NewGoAllocator(), tc.dt)
+			err := appendDuration(b, d)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				arr := b.NewArray()
+				assert.Equal(t, tc.want, arr.ValueStr(0), tc.dt.String())
+				arr.Release()
+			}
+			b.Release()
+		}
+	})
+}
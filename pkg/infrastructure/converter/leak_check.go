@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// leakTracker keeps track of record slices that have not been fully released.
+type leakTracker struct {
+	mu          sync.Mutex
+	outstanding map[*trackedRecord]struct{}
+}
+
+func newLeakTracker() *leakTracker {
+	return &leakTracker{outstanding: make(map[*trackedRecord]struct{})}
+}
+
+// track wraps rec so that its final Release is observed by the tracker.
+func (lt *leakTracker) track(rec arrow.Record) arrow.Record {
+	tr := &trackedRecord{Record: rec, tracker: lt}
+	tr.refs.Store(1)
+
+	lt.mu.Lock()
+	lt.outstanding[tr] = struct{}{}
+	lt.mu.Unlock()
+
+	return tr
+}
+
+func (lt *leakTracker) untrack(tr *trackedRecord) {
+	lt.mu.Lock()
+	delete(lt.outstanding, tr)
+	lt.mu.Unlock()
+}
+
+// count returns the number of slices that are still retained.
+func (lt *leakTracker) count() int {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	return len(lt.outstanding)
+}
+
+// trackedRecord is an arrow.Record that reports its final Release to a leakTracker.
+type trackedRecord struct {
+	arrow.Record
+	refs    atomic.Int64
+	tracker *leakTracker
+}
+
+// Retain increases the reference count.
+func (tr *trackedRecord) Retain() {
+	tr.refs.Add(1)
+	tr.Record.Retain()
+}
+
+// Release decreases the reference count and untracks the record when it reaches 0.
+func (tr *trackedRecord) Release() {
+	if tr.refs.Add(-1) == 0 {
+		tr.tracker.untrack(tr)
+	}
+	tr.Record.Release()
+}
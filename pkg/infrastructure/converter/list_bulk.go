@@ -0,0 +1,167 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// bulkList accumulates the values of a top-level list column over a batch so
+// that offsets, validity and element values can each be appended in one
+// call instead of element by element.
+type bulkList interface {
+	// add buffers a scanned list value. It reports false if the value does
+	// not have the expected shape, in which case the caller must flush and
+	// append it through the generic path.
+	add(value interface{}) bool
+	// flush appends the buffered lists to lb and resets the buffer.
+	flush(lb *array.ListBuilder)
+	// reset discards the buffered lists.
+	reset()
+}
+
+// newBulkLists returns an accumulator for each list column of schema whose
+// element type supports bulk appends, or nil if there are none.
+func newBulkLists(schema *arrow.Schema) []bulkList {
+	var lists []bulkList
+	for i, field := range schema.Fields() {
+		lt, ok := field.Type.(*arrow.ListType)
+		if !ok {
+			continue
+		}
+		bl := newBulkList(lt.Elem())
+		if bl == nil {
+			continue
+		}
+		if lists == nil {
+			lists = make([]bulkList, schema.NumFields())
+		}
+		lists[i] = bl
+	}
+	return lists
+}
+
+// newBulkList returns an accumulator for lists of elemType, or nil if the
+// element type is not supported.
+func newBulkList(elemType arrow.DataType) bulkList {
+	switch elemType.ID() {
+	case arrow.BOOL:
+		return &typedList[bool]{appendValues: func(b array.Builder, v []bool, valid []bool) {
+			b.(*array.BooleanBuilder).AppendValues(v, valid)
+		}}
+	case arrow.INT8:
+		return &typedList[int8]{appendValues: func(b array.Builder, v []int8, valid []bool) {
+			b.(*array.Int8Builder).AppendValues(v, valid)
+		}}
+	case arrow.INT16:
+		return &typedList[int16]{appendValues: func(b array.Builder, v []int16, valid []bool) {
+			b.(*array.Int16Builder).AppendValues(v, valid)
+		}}
+	case arrow.INT32:
+		return &typedList[int32]{appendValues: func(b array.Builder, v []int32, valid []bool) {
+			b.(*array.Int32Builder).AppendValues(v, valid)
+		}}
+	case arrow.INT64:
+		return &typedList[int64]{appendValues: func(b array.Builder, v []int64, valid []bool) {
+			b.(*array.Int64Builder).AppendValues(v, valid)
+		}}
+	case arrow.UINT8:
+		return &typedList[uint8]{appendValues: func(b array.Builder, v []uint8, valid []bool) {
+			b.(*array.Uint8Builder).AppendValues(v, valid)
+		}}
+	case arrow.UINT16:
+		return &typedList[uint16]{appendValues: func(b array.Builder, v []uint16, valid []bool) {
+			b.(*array.Uint16Builder).AppendValues(v, valid)
+		}}
+	case arrow.UINT32:
+		return &typedList[uint32]{appendValues: func(b array.Builder, v []uint32, valid []bool) {
+			b.(*array.Uint32Builder).AppendValues(v, valid)
+		}}
+	case arrow.UINT64:
+		return &typedList[uint64]{appendValues: func(b array.Builder, v []uint64, valid []bool) {
+			b.(*array.Uint64Builder).AppendValues(v, valid)
+		}}
+	case arrow.FLOAT32:
+		return &typedList[float32]{appendValues: func(b array.Builder, v []float32, valid []bool) {
+			b.(*array.Float32Builder).AppendValues(v, valid)
+		}}
+	case arrow.FLOAT64:
+		return &typedList[float64]{appendValues: func(b array.Builder, v []float64, valid []bool) {
+			b.(*array.Float64Builder).AppendValues(v, valid)
+		}}
+	case arrow.STRING:
+		return &typedList[string]{appendValues: func(b array.Builder, v []string, valid []bool) {
+			b.(*array.StringBuilder).AppendValues(v, valid)
+		}}
+	}
+	return nil
+}
+
+// typedList is a bulkList for elements of Go type T.
+type typedList[T any] struct {
+	offsets      []int32 // start of each list, relative to the first buffered list
+	valid        []bool
+	values       []T
+	valuesValid  []bool
+	appendValues func(b array.Builder, values []T, valid []bool)
+}
+
+func (l *typedList[T]) add(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		l.offsets = append(l.offsets, int32(len(l.values)))
+		l.valid = append(l.valid, false)
+	case []interface{}:
+		for _, elem := range v {
+			if _, ok := elem.(T); !ok && elem != nil {
+				return false
+			}
+		}
+		l.offsets = append(l.offsets, int32(len(l.values)))
+		l.valid = append(l.valid, true)
+		for _, elem := range v {
+			t, ok := elem.(T)
+			l.values = append(l.values, t)
+			l.valuesValid = append(l.valuesValid, ok)
+		}
+	case []T:
+		l.offsets = append(l.offsets, int32(len(l.values)))
+		l.valid = append(l.valid, true)
+		l.values = append(l.values, v...)
+		for range v {
+			l.valuesValid = append(l.valuesValid, true)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *typedList[T]) flush(lb *array.ListBuilder) {
+	if len(l.valid) == 0 {
+		return
+	}
+	vb := lb.ValueBuilder()
+	base := int32(vb.Len())
+	for i := range l.offsets {
+		l.offsets[i] += base
+	}
+	lb.AppendValues(l.offsets, l.valid)
+	l.appendValues(vb, l.values, l.valuesValid)
+	l.reset()
+}
+
+func (l *typedList[T]) reset() {
+	l.offsets = l.offsets[:0]
+	l.valid = l.valid[:0]
+	l.values = l.values[:0]
+	l.valuesValid = l.valuesValid[:0]
+}
+
+// flushLists appends the lists buffered for the current batch.
+func (r *BatchReader) flushLists() {
+	for i, bl := range r.lists {
+		if bl != nil {
+			bl.flush(r.builder.Field(i).(*array.ListBuilder))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listQuery = `SELECT
+	CASE WHEN i % 7 = 0 THEN NULL ELSE [j FOR j IN range(i % 5)] END AS ids,
+	[CASE WHEN i % 3 = 0 THEN NULL ELSE 'v' || i END, 'x'] AS names,
+	[i % 2 = 0] AS flags
+FROM range(300) t(i) ORDER BY i`
+
+// readLists reads listQuery in batches of 64 with or without bulk list appends.
+func readLists(t *testing.T, alloc memory.Allocator, bulk bool) []arrow.Record {
+	t.Helper()
+
+	reader, err := NewBatchReader(alloc, queryRows(t, listQuery), zerolog.Nop())
+	require.NoError(t, err)
+	defer reader.Release()
+	require.NotNil(t, reader.lists)
+	if !bulk {
+		reader.lists = nil
+	}
+	reader.SetBatchSize(64)
+
+	var recs []arrow.Record
+	for reader.Next() {
+		recs = append(recs, reader.Record())
+	}
+	require.NoError(t, reader.Err())
+	return recs
+}
+
+func TestBatchReaderBulkLists(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	bulk := readLists(t, alloc, true)
+	generic := readLists(t, alloc, false)
+	require.Len(t, bulk, 5)
+	require.Len(t, generic, len(bulk))
+	for i := range bulk {
+		assert.True(t, array.RecordEqual(generic[i], bulk[i]), "batch %d differs", i)
+		bulk[i].Release()
+		generic[i].Release()
+	}
+}
+
+func TestBulkListFallback(t *testing.T) {
+	lb := array.NewListBuilder(memory.NewGoAllocator(), arrow.PrimitiveTypes.Int32)
+	defer lb.Release()
+	bl := newBulkList(arrow.PrimitiveTypes.Int32)
+	r := &BatchReader{}
+
+	require.True(t, bl.add([]interface{}{int32(1), nil}))
+	require.True(t, bl.add(nil))
+	// An unexpected element type is appended through the generic path after
+	// the buffered lists.
+	require.False(t, bl.add([]interface{}{int64(3)}))
+	bl.flush(lb)
+	require.NoError(t, r.appendDynamicValue(lb, []interface{}{int64(3)}))
+	require.True(t, bl.add([]int32{4, 5}))
+	bl.flush(lb)
+
+	arr := lb.NewListArray()
+	defer arr.Release()
+	assert.Equal(t, `[[1 (null)] (null) [3] [4 5]]`, arr.String())
+}
+
+func BenchmarkBatchReaderLists(b *testing.B) {
+	const query = "SELECT [j FOR j IN range(i % 50)] AS ids FROM range(20000) t(i)"
+
+	for _, bulk := range []bool{false, true} {
+		name := "per element"
+		if bulk {
+			name = "bulk"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(b, query), zerolog.Nop())
+				require.NoError(b, err)
+				if !bulk {
+					reader.lists = nil
+				}
+				for reader.Next() {
+					reader.Record().Release()
+				}
+				require.NoError(b, reader.Err())
+				reader.Release()
+			}
+		})
+	}
+}
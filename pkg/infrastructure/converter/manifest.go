@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"cmp"
+	"math"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Manifest describes the data produced by a drained reader, for cataloging
+// exported datasets. It is meant to be serialized with encoding/json.
+type Manifest struct {
+	Fields  []ManifestField `json:"fields"`
+	Rows    int64           `json:"rows"`
+	Batches int             `json:"batches"`
+	// Columns holds the statistics collected with WithManifestStats, in
+	// schema order.
+	Columns []ColumnStats `json:"columns,omitempty"`
+}
+
+// ManifestField describes one field of the schema.
+type ManifestField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// ColumnStats summarizes the values of one column. Min and Max are set
+// for integer, floating point and string columns with at least one
+// non-null value, as an int64, uint64, float64 or string; NaN is ignored.
+type ColumnStats struct {
+	Name  string `json:"name"`
+	Nulls int64  `json:"nulls"`
+	Min   any    `json:"min,omitempty"`
+	Max   any    `json:"max,omitempty"`
+}
+
+// manifestState accumulates the manifest of a reader as batches are read.
+type manifestState struct {
+	rows    int64
+	batches int
+	stats   []ColumnStats // nil unless WithManifestStats is set
+	// distinct holds a sketch per column for ApproxDistinct, or nil.
+	distinct []*hyperLogLog
+	drained  bool
+}
+
+// observe adds rec to the manifest.
+func (m *manifestState) observe(rec arrow.Record) {
+	m.rows += rec.NumRows()
+	m.batches++
+	for i := range m.stats {
+		m.stats[i].observe(rec.Column(i))
+	}
+	for i, h := range m.distinct {
+		h.observe(rec.Column(i))
+	}
+}
+
+// Manifest returns the manifest of the data the reader produced. It fails
+// until Next has returned false without an error.
+func (r *BatchReader) Manifest() (*Manifest, error) {
+	if !r.manifest.drained {
+		return nil, errors.New(errors.CodeFailedPrecondition, "manifest is only available once the reader is drained")
+	}
+
+	schema := r.Schema()
+	m := &Manifest{
+		Fields:  make([]ManifestField, schema.NumFields()),
+		Rows:    r.manifest.rows,
+		Batches: r.manifest.batches,
+	}
+	for i, f := range schema.Fields() {
+		m.Fields[i] = ManifestField{Name: f.Name, Type: f.Type.String(), Nullable: f.Nullable}
+	}
+	if r.opts.manifestStats {
+		m.Columns = make([]ColumnStats, schema.NumFields())
+		copy(m.Columns, r.manifest.stats)
+		for i, f := range schema.Fields() {
+			m.Columns[i].Name = f.Name
+		}
+	}
+	return m, nil
+}
+
+// observe adds the values of arr to s.
+func (s *ColumnStats) observe(arr arrow.Array) {
+	s.Nulls += int64(arr.NullN())
+	switch a := arr.(type) {
+	case *array.Int8:
+		observeRange(s, a, func(i int) int64 { return int64(a.Value(i)) })
+	case *array.Int16:
+		observeRange(s, a, func(i int) int64 { return int64(a.Value(i)) })
+	case *array.Int32:
+		observeRange(s, a, func(i int) int64 { return int64(a.Value(i)) })
+	case *array.Int64:
+		observeRange(s, a, a.Value)
+	case *array.Uint8:
+		observeRange(s, a, func(i int) uint64 { return uint64(a.Value(i)) })
+	case *array.Uint16:
+		observeRange(s, a, func(i int) uint64 { return uint64(a.Value(i)) })
+	case *array.Uint32:
+		observeRange(s, a, func(i int) uint64 { return uint64(a.Value(i)) })
+	case *array.Uint64:
+		observeRange(s, a, a.Value)
+	case *array.Float32:
+		observeRange(s, a, func(i int) float64 { return float64(a.Value(i)) })
+	case *array.Float64:
+		observeRange(s, a, a.Value)
+	case *array.String:
+		observeRange(s, a, func(i int) string { return string([]byte(a.Value(i))) })
+	case *array.LargeString:
+		observeRange(s, a, func(i int) string { return string([]byte(a.Value(i))) })
+	case *array.StringView:
+		observeRange(s, a, func(i int) string { return string([]byte(a.Value(i))) })
+	}
+}
+
+// observeRange widens the range of s to the non-null values of arr, read
+// with value. Values are compared in their own type, so unsigned values
+// above math.MaxInt64 keep their order.
+func observeRange[T cmp.Ordered](s *ColumnStats, arr arrow.Array, value func(int) T) {
+	lo, hasLo := s.Min.(T)
+	hi, hasHi := s.Max.(T)
+	for i := 0; i < arr.Len(); i++ {
+		if arr.IsNull(i) {
+			continue
+		}
+		v := value(i)
+		if f, ok := any(v).(float64); ok && math.IsNaN(f) {
+			continue
+		}
+		if !hasLo || v < lo {
+			lo, hasLo = v, true
+		}
+		if !hasHi || v > hi {
+			hi, hasHi = v, true
+		}
+	}
+	if hasLo {
+		s.Min, s.Max = lo, hi
+	}
+}
@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestBatchReaderManifest(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = `SELECT * FROM (VALUES
+		(1, 'ant', 2.5::DOUBLE, 9223372036854775809::UBIGINT),
+		(2, NULL, -1.0, 1::UBIGINT),
+		(3, 'bee', NULL, 18446744073709551615::UBIGINT),
+		(4, 'cat', 0.0, NULL),
+		(5, 'ant', 7.25, 2::UBIGINT)) t(id, name, score, big)`
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+		WithBatchSize(2), WithManifestStats())
+	require.NoError(t, err)
+	defer reader.Release()
+
+	_, err = reader.Manifest()
+	assert.Equal(t, errors.CodeFailedPrecondition, errors.GetCode(err))
+	for reader.Next() {
+	}
+	require.NoError(t, reader.Err())
+
+	m, err := reader.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, &Manifest{
+		Fields: []ManifestField{
+			{Name: "id", Type: "int32", Nullable: true},
+			{Name: "name", Type: "utf8", Nullable: true},
+			{Name: "score", Type: "float64", Nullable: true},
+			{Name: "big", Type: "uint64", Nullable: true},
+		},
+		Rows:    5,
+		Batches: 3,
+		Columns: []ColumnStats{
+			{Name: "id", Min: int64(1), Max: int64(5)},
+			{Name: "name", Nulls: 1, Min: "ant", Max: "cat"},
+			{Name: "score", Nulls: 1, Min: float64(-1), Max: 7.25},
+			{Name: "big", Nulls: 1, Min: uint64(1), Max: uint64(18446744073709551615)},
+		},
+	}, m)
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"fields": [
+			{"name": "id", "type": "int32", "nullable": true},
+			{"name": "name", "type": "utf8", "nullable": true},
+			{"name": "score", "type": "float64", "nullable": true},
+			{"name": "big", "type": "uint64", "nullable": true}
+		],
+		"rows": 5,
+		"batches": 3,
+		"columns": [
+			{"name": "id", "nulls": 0, "min": 1, "max": 5},
+			{"name": "name", "nulls": 1, "min": "ant", "max": "cat"},
+			{"name": "score", "nulls": 1, "min": -1, "max": 7.25},
+			{"name": "big", "nulls": 1, "min": 1, "max": 18446744073709551615}
+		]
+	}`, string(data))
+}
+
+func TestBatchReaderManifestWithoutStats(t *testing.T) {
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS a WHERE false"), zerolog.Nop())
+	require.NoError(t, err)
+	defer reader.Release()
+	assert.False(t, reader.Next())
+
+	m, err := reader.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), m.Rows)
+	assert.Equal(t, 0, m.Batches)
+	assert.Len(t, m.Fields, 1)
+	assert.Nil(t, m.Columns)
+}
@@ -0,0 +1,226 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// MergeSorted merges two readers sorted ascending on keyCol into a single
+// sorted stream, as the first step of a merge join. Both readers must have
+// the same schema. less orders two non-null key values, as returned for the
+// column's type by RecordToRows; null keys sort last, and on equal keys
+// rows of left come first. Records hold up to left's batch size rows.
+// MergeSorted takes ownership of both readers; a mismatch between them is
+// reported by Err.
+func MergeSorted(left, right *BatchReader, keyCol string, less func(a, b any) bool) *BatchReader {
+	schema := left.Schema()
+	m := &merger{
+		left:  &mergeCursor{reader: left},
+		right: &mergeCursor{reader: right},
+		less:  less,
+		alloc: left.allocator,
+		size:  int64(left.batchSize),
+	}
+	r := newDerivedReader(schema, left.allocator, left.logger, recordSource{next: m.next, close: m.close})
+
+	if !schema.Equal(right.Schema()) {
+		r.err = errors.New(errors.CodeInvalidRequest, "cannot merge readers with different schemas")
+		return r
+	}
+	idx := schema.FieldIndices(keyCol)
+	if len(idx) != 1 {
+		r.err = errors.New(errors.CodeInvalidRequest, fmt.Sprintf("merge key %q must name exactly one column", keyCol))
+		return r
+	}
+	m.schema = schema
+	m.left.key, m.right.key = idx[0], idx[0]
+	return r
+}
+
+// merger produces the merged records of MergeSorted.
+type merger struct {
+	left, right *mergeCursor
+	less        func(a, b any) bool
+	schema      *arrow.Schema
+	alloc       memory.Allocator
+	size        int64
+}
+
+// mergeCursor tracks the current row of one merge input.
+type mergeCursor struct {
+	reader *BatchReader
+	rec    arrow.Record
+	row    int64
+	key    int
+	done   bool
+}
+
+// fill makes sure the cursor is on a row, reading the next record once the
+// current one is used up. It reports false at the end of the input.
+func (c *mergeCursor) fill() (bool, error) {
+	for c.rec == nil || c.row >= c.rec.NumRows() {
+		if c.rec != nil {
+			c.rec.Release()
+			c.rec = nil
+		}
+		if c.done {
+			return false, nil
+		}
+		if !c.reader.Next() {
+			c.done = true
+			return false, c.reader.Err()
+		}
+		rec, err := c.reader.TakeRecord()
+		if err != nil {
+			return false, err
+		}
+		c.rec, c.row = rec, 0
+	}
+	return true, nil
+}
+
+// keyAt returns the key of row i of the current record, or nil if it is null.
+func (c *mergeCursor) keyAt(i int64) (any, error) {
+	return arrowValue(c.rec.Column(c.key), int(i))
+}
+
+// keyLess orders keys with nulls last.
+func (m *merger) keyLess(a, b any) bool {
+	switch {
+	case a == nil:
+		return false
+	case b == nil:
+		return true
+	default:
+		return m.less(a, b)
+	}
+}
+
+// next returns the next merged record, or nil once both inputs are used up.
+func (m *merger) next() (arrow.Record, error) {
+	var (
+		parts []arrow.Record
+		rows  int64
+	)
+	defer func() {
+		for _, p := range parts {
+			p.Release()
+		}
+	}()
+
+	for rows < m.size {
+		lok, err := m.left.fill()
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to read left merge input")
+		}
+		rok, err := m.right.fill()
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to read right merge input")
+		}
+		if !lok && !rok {
+			break
+		}
+
+		run, err := m.takeRun(lok, rok, m.size-rows)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, run)
+		rows += run.NumRows()
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, nil
+	case 1:
+		rec := parts[0]
+		parts = nil
+		return rec, nil
+	}
+	return concatRecords(m.alloc, m.schema, parts, rows)
+}
+
+// takeRun returns the longest run of at most limit rows that can be taken
+// from one input before the other input's current row sorts first.
+func (m *merger) takeRun(lok, rok bool, limit int64) (arrow.Record, error) {
+	if !rok || !lok {
+		c := m.left
+		if !lok {
+			c = m.right
+		}
+		start := c.row
+		c.row = min(c.rec.NumRows(), start+limit)
+		return c.rec.NewSlice(start, c.row), nil
+	}
+
+	lkey, err := m.left.keyAt(m.left.row)
+	if err != nil {
+		return nil, err
+	}
+	rkey, err := m.right.keyAt(m.right.row)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ties go to the left input, so it wins unless the right key is smaller.
+	c, wins := m.left, func(k any) bool { return !m.keyLess(rkey, k) }
+	if m.keyLess(rkey, lkey) {
+		c, wins = m.right, func(k any) bool { return m.keyLess(k, lkey) }
+	}
+
+	start := c.row
+	c.row++
+	for c.row < c.rec.NumRows() && c.row-start < limit {
+		k, err := c.keyAt(c.row)
+		if err != nil {
+			return nil, err
+		}
+		if !wins(k) {
+			break
+		}
+		c.row++
+	}
+	return c.rec.NewSlice(start, c.row), nil
+}
+
+// close releases the inputs and any records they are positioned on.
+func (m *merger) close() {
+	for _, c := range []*mergeCursor{m.left, m.right} {
+		if c.rec != nil {
+			c.rec.Release()
+			c.rec = nil
+		}
+		c.reader.Release()
+	}
+}
+
+// concatRecords concatenates parts, which share schema, into one record of
+// rows rows. The caller keeps ownership of parts.
+func concatRecords(mem memory.Allocator, schema *arrow.Schema, parts []arrow.Record, rows int64) (arrow.Record, error) {
+	cols := make([]arrow.Array, schema.NumFields())
+	defer func() {
+		for _, c := range cols {
+			if c != nil {
+				c.Release()
+			}
+		}
+	}()
+
+	chunks := make([]arrow.Array, len(parts))
+	for i := range cols {
+		for j, p := range parts {
+			chunks[j] = p.Column(i)
+		}
+		col, err := array.Concatenate(chunks, mem)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to concatenate record batches")
+		}
+		cols[i] = col
+	}
+	return array.NewRecord(schema, cols, rows), nil
+}
@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lessInt64(a, b any) bool { return a.(int64) < b.(int64) }
+
+func TestMergeSorted(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	left, err := NewBatchReader(alloc, queryRows(t,
+		`SELECT k::BIGINT AS k, 'l' AS src FROM (VALUES (1), (3), (5), (NULL)) t(k) ORDER BY k NULLS LAST`),
+		logger, WithBatchSize(3))
+	require.NoError(t, err)
+	right, err := NewBatchReader(alloc, queryRows(t,
+		`SELECT k::BIGINT AS k, 'r' AS src FROM (VALUES (2), (3), (4), (6), (7), (8), (NULL)) t(k) ORDER BY k NULLS LAST`),
+		logger, WithBatchSize(2))
+	require.NoError(t, err)
+
+	merged := MergeSorted(left, right, "k", lessInt64)
+	defer merged.Release()
+	assert.True(t, merged.Schema().Equal(left.Schema()))
+
+	var (
+		keys []any
+		srcs string
+	)
+	for merged.Next() {
+		rec := merged.Record()
+		assert.LessOrEqual(t, rec.NumRows(), int64(3))
+		ks, ss := rec.Column(0).(*array.Int64), rec.Column(1).(*array.String)
+		for i := 0; i < ks.Len(); i++ {
+			if ks.IsNull(i) {
+				keys = append(keys, nil)
+			} else {
+				keys = append(keys, ks.Value(i))
+			}
+			srcs += ss.Value(i)
+		}
+		rec.Release()
+	}
+	require.NoError(t, merged.Err())
+
+	assert.Equal(t, []any{int64(1), int64(2), int64(3), int64(3), int64(4), int64(5),
+		int64(6), int64(7), int64(8), nil, nil}, keys)
+	assert.Equal(t, "lrlrrlrrrlr", srcs)
+}
+
+func TestMergeSortedMismatch(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	left, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1::BIGINT AS k"), logger)
+	require.NoError(t, err)
+	right, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 'a' AS k"), logger)
+	require.NoError(t, err)
+
+	merged := MergeSorted(left, right, "k", lessInt64)
+	defer merged.Release()
+	assert.False(t, merged.Next())
+	assert.ErrorContains(t, merged.Err(), "different schemas")
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// transformNames applies the WithNameTransform function to the names of
+// fields in place. Two columns whose names transform to the same name are
+// an error.
+func transformNames(fields []arrow.Field, transform func(string) string) error {
+	if transform == nil {
+		return nil
+	}
+	from := make(map[string]string, len(fields))
+	for i := range fields {
+		name := fields[i].Name
+		to := transform(name)
+		if prev, ok := from[to]; ok && prev != name {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("columns %q and %q both transform to %q", prev, name, to))
+		}
+		from[to] = name
+		fields[i].Name = to
+	}
+	return nil
+}
+
+// SnakeCase converts a name to snake_case, e.g. "OrderID" and "order id"
+// to "order_id". It is meant for use with WithNameTransform.
+func SnakeCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// CamelCase converts a name to camelCase, e.g. "order_id" and "Order ID"
+// to "orderId". It is meant for use with WithNameTransform.
+func CamelCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i > 0 {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+		words[i] = w
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits name into words at spaces, punctuation and case
+// changes. A run of capitals is one word, so "HTTPServer" is "HTTP" and
+// "Server".
+func splitWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words, word = append(words, string(word)), nil
+			}
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words, word = append(words, string(word)), nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestBatchReaderNameTransform(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = `SELECT 1 AS "OrderID", 'a' AS "customerName", 2 AS "Ship Date", 3 AS "HTTPStatus", 4 AS total`
+
+	names := func(t *testing.T, reader *BatchReader) []string {
+		var out []string
+		for _, f := range reader.Schema().Fields() {
+			out = append(out, f.Name)
+		}
+		return out
+	}
+
+	t.Run("snake case", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithNameTransform(SnakeCase))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, []string{"order_id", "customer_name", "ship_date", "http_status", "total"}, names(t, reader))
+
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, "a", rec.Column(1).(*array.String).Value(0))
+	})
+
+	t.Run("camel case after renames", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithColumnRename(map[string]string{"total": "grand_total"}), WithNameTransform(CamelCase),
+			WithRowNumberColumn("row_num"))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, []string{"orderId", "customerName", "shipDate", "httpStatus", "grandTotal", "row_num"}, names(t, reader))
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, `SELECT 1 AS "userId", 2 AS user_id`), logger,
+			WithNameTransform(SnakeCase))
+		require.Error(t, err)
+		assert.Nil(t, reader)
+		assert.Equal(t, errors.CodeInvalidRequest, errors.GetCode(err))
+		assert.Contains(t, err.Error(), `"userId" and "user_id" both transform to "user_id"`)
+	})
+}
@@ -0,0 +1,36 @@
+//go:build duckdb_arrow
+
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+// queryNativeArrow runs query through the DuckDB Arrow interface. It reports
+// false when conn is not backed by a DuckDB driver connection.
+func queryNativeArrow(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (array.RecordReader, bool, error) {
+	var (
+		reader    array.RecordReader
+		supported bool
+	)
+	err := conn.Raw(func(driverConn interface{}) error {
+		dc, ok := driverConn.(*duckdb.Conn)
+		if !ok {
+			return nil
+		}
+		supported = true
+
+		ar, err := duckdb.NewArrowFromConn(driver.Conn(dc))
+		if err != nil {
+			return err
+		}
+		reader, err = ar.QueryContext(ctx, query, args...)
+		return err
+	})
+	return reader, supported, err
+}
@@ -0,0 +1,16 @@
+//go:build !duckdb_arrow
+
+package converter
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// queryNativeArrow reports that native Arrow results are unavailable; build
+// with the duckdb_arrow tag to enable them.
+func queryNativeArrow(ctx context.Context, conn *sql.Conn, query string, args ...interface{}) (array.RecordReader, bool, error) {
+	return nil, false, nil
+}
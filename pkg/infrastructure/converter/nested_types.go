@@ -0,0 +1,387 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// parseStructType converts the member list of a DuckDB STRUCT type, e.g.
+// `"a" INTEGER, "b" VARCHAR[]`, into an Arrow struct type. Field names keep
+// their original case. If any member is unnamed, as produced by ROW(...), or
+// shares its name with another member, names cannot identify the children,
+// so every member is named positionally f0, f1, ... and values must be
+// member slices, which are mapped by position. Children are always
+// nullable. go-duckdb cannot scan unnamed members and fails in Next with
+// "empty name"; queries against DuckDB must name them, e.g. {'a': 1}
+// instead of ROW(1).
+func (tc *typeConverter) parseStructType(members string) (arrow.DataType, error) {
+	parts, err := splitTopLevel(members)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]arrow.Field, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	positional := false
+	for _, part := range parts {
+		name, typeName, err := splitStructMember(part)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" || seen[name] {
+			positional = true
+		}
+		seen[name] = true
+		childType, err := tc.DuckDBToArrowType(typeName)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, arrow.Field{Name: name, Type: childType, Nullable: true})
+	}
+	if positional {
+		for i := range fields {
+			fields[i].Name = fmt.Sprintf("f%d", i)
+		}
+	}
+	return arrow.StructOf(fields...), nil
+}
+
+// parseMapType converts the `KEY, VALUE` arguments of a DuckDB MAP type into
+// an Arrow map type whose key type matches the declared key type.
+func (tc *typeConverter) parseMapType(args string) (arrow.DataType, error) {
+	parts, err := splitTopLevel(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid map type arguments: %s", args)
+	}
+	keyType, err := tc.DuckDBToArrowType(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	valueType, err := tc.DuckDBToArrowType(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return arrow.MapOf(keyType, valueType), nil
+}
+
+// splitTopLevel splits s on commas that are not nested in parentheses or
+// double-quoted identifiers.
+func splitTopLevel(s string) ([]string, error) {
+	var (
+		parts   []string
+		depth   int
+		inQuote bool
+		start   int
+	)
+	for i, c := range s {
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in type: %s", s)
+			}
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || inQuote {
+		return nil, fmt.Errorf("unbalanced type definition: %s", s)
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts, nil
+}
+
+// splitStructMember splits a single `name TYPE` STRUCT member, unquoting the
+// name if necessary.
+func splitStructMember(member string) (string, string, error) {
+	if strings.HasPrefix(member, `"`) {
+		var name strings.Builder
+		for i := 1; i < len(member); i++ {
+			if member[i] != '"' {
+				name.WriteByte(member[i])
+				continue
+			}
+			// A doubled quote is an escaped quote inside the name
+			if i+1 < len(member) && member[i+1] == '"' {
+				name.WriteByte('"')
+				i++
+				continue
+			}
+			return name.String(), strings.TrimSpace(member[i+1:]), nil
+		}
+		return "", "", fmt.Errorf("unterminated struct field name: %s", member)
+	}
+
+	name, typeName, ok := strings.Cut(member, " ")
+	if !ok {
+		return "", "", fmt.Errorf("invalid struct member: %s", member)
+	}
+	return name, strings.TrimSpace(typeName), nil
+}
+
+// appendStructValue appends a struct value whose fields are keyed by name.
+// Missing or null members are appended as child nulls while the struct
+// itself stays valid. A key naming no field is an error: it is how values
+// of structs with positionally renamed members show up, and dropping them
+// would silently turn the members into nulls.
+func (r *BatchReader) appendStructValue(sb *array.StructBuilder, values map[string]interface{}) error {
+	st := sb.Type().(*arrow.StructType)
+	for name := range values {
+		if _, ok := st.FieldIdx(name); !ok {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("struct value member %q matches no field of %s", name, st))
+		}
+	}
+	sb.Append(true)
+	for i, field := range st.Fields() {
+		child := sb.FieldBuilder(i)
+		val, ok := values[field.Name]
+		if !ok || val == nil {
+			child.AppendNull()
+			continue
+		}
+		if err := r.appendDynamicValue(child, val); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "struct field %q", field.Name)
+		}
+	}
+	return nil
+}
+
+// appendStructPositional appends a struct value given as its children in
+// declaration order, as drivers that support anonymous structs report them.
+func (r *BatchReader) appendStructPositional(sb *array.StructBuilder, values []interface{}) error {
+	st := sb.Type().(*arrow.StructType)
+	if len(values) != st.NumFields() {
+		return errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("struct value has %d members, expected %d", len(values), st.NumFields()))
+	}
+	sb.Append(true)
+	for i, val := range values {
+		if err := r.appendDynamicValue(sb.FieldBuilder(i), val); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "struct member %d", i)
+		}
+	}
+	return nil
+}
+
+// appendReflectedValue appends nested driver values that are not in the plain
+// []interface{} or map[string]interface{} shapes, such as typed slices, arrays,
+// maps and Go structs. It reports false when value is not a nested shape the
+// builder accepts.
+func (r *BatchReader) appendReflectedValue(fb array.Builder, value interface{}) (bool, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			fb.AppendNull()
+			return true, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch b := fb.(type) {
+	case *array.ListBuilder, *array.LargeListBuilder, *array.FixedSizeListBuilder:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return false, nil
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			b.AppendNull()
+			return true, nil
+		}
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		return true, r.appendListValue(b.(array.ListLikeBuilder), elems)
+
+	case *array.StructBuilder:
+		st := b.Type().(*arrow.StructType)
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return false, nil
+			}
+			values := make(map[string]interface{}, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				values[iter.Key().String()] = iter.Value().Interface()
+			}
+			return true, r.appendStructValue(b, values)
+		case reflect.Struct:
+			return true, r.appendStructValue(b, goStructValues(rv, st))
+		case reflect.Slice, reflect.Array:
+			if rv.Kind() == reflect.Slice && rv.IsNil() {
+				b.AppendNull()
+				return true, nil
+			}
+			values := make([]interface{}, rv.Len())
+			for i := range values {
+				values[i] = rv.Index(i).Interface()
+			}
+			return true, r.appendStructPositional(b, values)
+		}
+
+	case *array.MapBuilder:
+		if keys, items, ok := parallelMapArrays(rv); ok {
+			return true, r.appendParallelMap(b, keys, items)
+		}
+		if rv.Kind() != reflect.Map {
+			return false, nil
+		}
+		if rv.IsNil() {
+			b.AppendNull()
+			return true, nil
+		}
+		return true, r.appendMapValue(b, rv)
+	}
+	return false, nil
+}
+
+// appendMapValue appends a Go map as a single map entry list. Keys are
+// coerced to the declared key type through the key builder and written in
+// sorted order so that output is deterministic.
+func (r *BatchReader) appendMapValue(mb *array.MapBuilder, rv reflect.Value) error {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return lessMapKey(keys[i], keys[j]) })
+
+	mb.Append(true)
+	kb, ib := mb.KeyBuilder(), mb.ItemBuilder()
+	for _, key := range keys {
+		k := key.Interface()
+		if k == nil {
+			return errors.New(errors.CodeInvalidRequest, "map keys cannot be null")
+		}
+		if err := r.appendDynamicValue(kb, k); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "map key %v", k)
+		}
+		if err := r.appendDynamicValue(ib, rv.MapIndex(key).Interface()); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "map value for key %v", k)
+		}
+	}
+	return nil
+}
+
+// parallelMapArrays returns the keys and values of a MAP value given as
+// parallel arrays, as some drivers return it: a [keys, values] pair of
+// lists. The shape is recognized by its Go kind alone, since a list is no
+// other valid form of a map; every Go map is read as entries, even one
+// whose only keys are "key" and "value", as a MAP(VARCHAR, ...) may have.
+func parallelMapArrays(rv reflect.Value) (keys, items reflect.Value, ok bool) {
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 2 {
+		return keys, items, false
+	}
+	keys, items = rv.Index(0), rv.Index(1)
+
+	isList := func(v reflect.Value) bool {
+		for v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	}
+	if !isList(keys) || !isList(items) {
+		return keys, items, false
+	}
+	for keys.Kind() == reflect.Interface {
+		keys = keys.Elem()
+	}
+	for items.Kind() == reflect.Interface {
+		items = items.Elem()
+	}
+	return keys, items, true
+}
+
+// appendParallelMap appends a map given as parallel key and value arrays,
+// keeping the entries in the order the driver returned them.
+func (r *BatchReader) appendParallelMap(mb *array.MapBuilder, keys, items reflect.Value) error {
+	if keys.Len() != items.Len() {
+		return errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("map value has %d keys but %d values", keys.Len(), items.Len()))
+	}
+
+	mb.Append(true)
+	kb, ib := mb.KeyBuilder(), mb.ItemBuilder()
+	for i := 0; i < keys.Len(); i++ {
+		k := keys.Index(i).Interface()
+		if k == nil {
+			return errors.New(errors.CodeInvalidRequest, "map keys cannot be null")
+		}
+		if err := r.appendDynamicValue(kb, k); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "map key %v", k)
+		}
+		if err := r.appendDynamicValue(ib, items.Index(i).Interface()); err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "map value for key %v", k)
+		}
+	}
+	return nil
+}
+
+// lessMapKey orders map keys numerically or lexically, falling back to their
+// formatted representation for mixed or other kinds.
+func lessMapKey(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	switch {
+	case a.CanInt() && b.CanInt():
+		return a.Int() < b.Int()
+	case a.CanUint() && b.CanUint():
+		return a.Uint() < b.Uint()
+	case a.CanFloat() && b.CanFloat():
+		return a.Float() < b.Float()
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return a.String() < b.String()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// goStructValues maps the exported fields of a Go struct onto the fields of
+// st, matching a `db` tag first, then the field name case-insensitively.
+func goStructValues(rv reflect.Value, st *arrow.StructType) map[string]interface{} {
+	values := make(map[string]interface{}, st.NumFields())
+	for _, field := range st.Fields() {
+		if i, ok := goStructField(rv.Type(), field.Name); ok {
+			values[field.Name] = rv.Field(i).Interface()
+		}
+	}
+	return values
+}
+
+// goStructField returns the index of the exported field of struct type rt
+// for name, matching a `db` tag first, then the field name
+// case-insensitively.
+func goStructField(rt reflect.Type, name string) (int, bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fieldName := sf.Tag.Get("db")
+		if fieldName == "" {
+			fieldName = sf.Name
+		}
+		if strings.EqualFold(fieldName, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,206 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/marcboeker/go-duckdb/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendNestedShapes(t *testing.T) {
+	pointType := arrow.StructOf(
+		arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		arrow.Field{Name: "label", Type: arrow.BinaryTypes.String, Nullable: true},
+	)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "point", Type: pointType, Nullable: true},
+		{Name: "ids", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+	}, nil)
+
+	type point struct {
+		X     int32 `db:"x"`
+		Label string
+	}
+	type row map[string]interface{}
+
+	tests := []struct {
+		name  string
+		point interface{}
+		ids   interface{}
+	}{
+		{
+			name:  "generic shapes",
+			point: map[string]interface{}{"x": int32(1), "label": "a"},
+			ids:   []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:  "typed map and slice",
+			point: row{"x": int32(1), "label": "a"},
+			ids:   []int64{1, 2},
+		},
+		{
+			name:  "go struct and array",
+			point: point{X: 1, Label: "a"},
+			ids:   [2]int64{1, 2},
+		},
+		{
+			name:  "struct pointer",
+			point: &point{X: 1, Label: "a"},
+			ids:   &[]int64{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+			defer builder.Release()
+			r := &BatchReader{builder: builder}
+
+			require.NoError(t, r.appendDynamicValue(builder.Field(0), tt.point))
+			require.NoError(t, r.appendDynamicValue(builder.Field(1), tt.ids))
+
+			rec := builder.NewRecord()
+			defer rec.Release()
+
+			st := rec.Column(0).(*array.Struct)
+			assert.Equal(t, int32(1), st.Field(0).(*array.Int32).Value(0))
+			assert.Equal(t, "a", st.Field(1).(*array.String).Value(0))
+
+			list := rec.Column(1).(*array.List)
+			assert.Equal(t, []int64{1, 2}, list.ListValues().(*array.Int64).Int64Values())
+		})
+	}
+}
+
+func TestAppendUnnamedStructChildren(t *testing.T) {
+	tc := New(zerolog.Nop())
+	dt, err := tc.DuckDBToArrowType(`STRUCT("" INTEGER, "" VARCHAR)[]`)
+	require.NoError(t, err)
+	st := dt.(*arrow.ListType).Elem().(*arrow.StructType)
+	assert.Equal(t, "f0", st.Field(0).Name)
+	assert.Equal(t, "f1", st.Field(1).Name)
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "rows", Type: dt, Nullable: true}}, nil)
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	r := &BatchReader{builder: builder}
+
+	// Children are matched by position since their names carry no meaning.
+	require.NoError(t, r.appendDynamicValue(builder.Field(0), []interface{}{
+		[]interface{}{int32(1), "a"},
+		[]interface{}{int32(2), nil},
+	}))
+
+	rec := builder.NewRecord()
+	defer rec.Release()
+	values := rec.Column(0).(*array.List).ListValues().(*array.Struct)
+	assert.Equal(t, []int32{1, 2}, values.Field(0).(*array.Int32).Int32Values())
+	assert.Equal(t, "a", values.Field(1).(*array.String).Value(0))
+	assert.True(t, values.Field(1).IsNull(1))
+
+	t.Run("keyed by name", func(t *testing.T) {
+		dt, err := tc.DuckDBToArrowType(`STRUCT("a" INTEGER, "a" VARCHAR)`)
+		require.NoError(t, err)
+		schema := arrow.NewSchema([]arrow.Field{{Name: "s", Type: dt, Nullable: true}}, nil)
+		builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+		defer builder.Release()
+		r := &BatchReader{builder: builder}
+
+		// A map cannot be matched to renamed children and must not become nulls
+		err = r.appendDynamicValue(builder.Field(0), map[string]interface{}{"a": int32(1)})
+		assert.ErrorContains(t, err, `"a"`)
+	})
+}
+
+func TestBatchReaderParallelMapArrays(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// open returns rows of a MAP(VARCHAR, INTEGER) column holding values,
+	// as a driver returning maps as parallel key and value arrays would.
+	open := func(values ...driver.Value) *sql.Rows {
+		result := &fakeResult{
+			columns:   []string{"m"},
+			typeNames: []string{"MAP(VARCHAR, INTEGER)"},
+			scanTypes: []reflect.Type{reflect.TypeOf((*interface{})(nil)).Elem()},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v})
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT m")
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("shapes", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc, open(
+			[]interface{}{[]interface{}{"b", "a"}, []interface{}{int32(2), nil}},
+			[]interface{}{[]string{"x"}, []int32{7}},
+			[]interface{}{[]interface{}{}, []interface{}{}},
+			nil,
+			duckdb.Map{"z": int32(1)},
+		), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.True(t, arrow.TypeEqual(arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32), reader.Schema().Field(0).Type))
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		// Parallel arrays keep the driver's entry order.
+		assert.Equal(t, `[{["b" "a"] [2 (null)]} {["x"] [7]} {[] []} (null) {["z"] [1]}]`, rec.Column(0).String())
+		assert.False(t, reader.Next())
+		require.NoError(t, reader.Err())
+	})
+
+	t.Run("map keyed key and value", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		// A MAP(VARCHAR, INTEGER[]) whose keys happen to be "key" and
+		// "value" looks like parallel arrays but must be read as entries.
+		result := &fakeResult{
+			columns:   []string{"m"},
+			typeNames: []string{"MAP(VARCHAR, INTEGER[])"},
+			scanTypes: []reflect.Type{reflect.TypeOf((*interface{})(nil)).Elem()},
+			rows: [][]driver.Value{
+				{map[string]interface{}{"key": []interface{}{int32(1)}, "value": []interface{}{int32(2), int32(3)}}},
+			},
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		defer db.Close()
+		rows, err := db.Query("SELECT m")
+		require.NoError(t, err)
+
+		reader, err := NewBatchReader(alloc, rows, logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, `[{["key" "value"] [[1] [2 3]]}]`, rec.Column(0).String())
+	})
+
+	t.Run("length mismatch", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(
+			[]interface{}{[]interface{}{"a", "b"}, []interface{}{int32(1)}},
+		), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "map value has 2 keys but 1 values")
+	})
+}
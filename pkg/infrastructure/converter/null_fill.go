@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// applyNullFills validates the configured fill values against fields and
+// marks the filled fields non-nullable. It returns the coerced fill value for
+// each column index, or nil where no fill is configured.
+func applyNullFills(fields []arrow.Field, fills map[string]interface{}) ([]interface{}, error) {
+	if len(fills) == 0 {
+		return nil, nil
+	}
+
+	out := make([]interface{}, len(fields))
+	for name, fill := range fills {
+		idx := -1
+		for i := range fields {
+			if fields[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot fill nulls of unknown column %q", name))
+		}
+
+		v, err := coerceFillValue(fill, fields[idx].Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, errors.CodeInvalidRequest, "invalid null fill for column %q", name)
+		}
+		out[idx] = v
+		fields[idx].Nullable = false
+	}
+	return out, nil
+}
+
+// coerceFillValue converts fill to a value appendDynamicValue accepts for dt.
+func coerceFillValue(fill interface{}, dt arrow.DataType) (interface{}, error) {
+	if fill == nil {
+		return nil, fmt.Errorf("fill value must not be nil")
+	}
+	rv := reflect.ValueOf(fill)
+
+	switch {
+	case arrow.IsSignedInteger(dt.ID()):
+		bits := dt.(arrow.FixedWidthDataType).BitWidth()
+		lo, hi := int64(math.MinInt64)>>(64-bits), int64(math.MaxInt64)>>(64-bits)
+		switch {
+		case rv.CanInt() && rv.Int() >= lo && rv.Int() <= hi:
+			return rv.Int(), nil
+		case rv.CanUint() && rv.Uint() <= uint64(hi):
+			return int64(rv.Uint()), nil
+		}
+	case arrow.IsUnsignedInteger(dt.ID()):
+		bits := dt.(arrow.FixedWidthDataType).BitWidth()
+		hi := uint64(math.MaxUint64) >> (64 - bits)
+		switch {
+		case rv.CanUint() && rv.Uint() <= hi:
+			return rv.Uint(), nil
+		case rv.CanInt() && rv.Int() >= 0 && uint64(rv.Int()) <= hi:
+			return uint64(rv.Int()), nil
+		}
+	case dt.ID() == arrow.FLOAT32 && (rv.CanFloat() || rv.CanInt()):
+		return float32(rv.Convert(reflect.TypeOf(float64(0))).Float()), nil
+	case dt.ID() == arrow.FLOAT64 && (rv.CanFloat() || rv.CanInt()):
+		return rv.Convert(reflect.TypeOf(float64(0))).Float(), nil
+	case dt.ID() == arrow.BOOL:
+		if b, ok := fill.(bool); ok {
+			return b, nil
+		}
+	case dt.ID() == arrow.STRING:
+		if s, ok := fill.(string); ok {
+			return s, nil
+		}
+	case isJSONType(dt):
+		// Filled in as JSON text, like the values of the column
+		if s, ok := fill.(string); ok && json.Valid([]byte(s)) {
+			return s, nil
+		}
+	case dt.ID() == arrow.BINARY:
+		if b, ok := fill.([]byte); ok {
+			return b, nil
+		}
+	case dt.ID() == arrow.DATE32, dt.ID() == arrow.DATE64, dt.ID() == arrow.TIME32,
+		dt.ID() == arrow.TIME64, dt.ID() == arrow.TIMESTAMP:
+		if t, ok := fill.(time.Time); ok {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("fill value %v (%T) does not match column type %s", fill, fill, dt)
+}
+
+// isNullScan reports whether a scanned destination holds SQL NULL.
+func isNullScan(value interface{}) bool {
+	switch v := value.(type) {
+	case *interface{}:
+		return v == nil || *v == nil
+	case *[]byte:
+		return v == nil || *v == nil
+	case **uint8:
+		return v == nil || *v == nil
+	case **uint16:
+		return v == nil || *v == nil
+	case *timeOrString:
+		return !v.Valid
+	case *scanBuffer:
+		return !v.valid
+	case *jsonDocument:
+		return v == nil || v.raw == nil
+	case sql.Scanner:
+		// sql.NullXxx and sql.Null[T] all carry a Valid flag
+		valid := reflect.ValueOf(v).Elem().FieldByName("Valid")
+		return valid.IsValid() && valid.Kind() == reflect.Bool && !valid.Bool()
+	default:
+		return false
+	}
+}
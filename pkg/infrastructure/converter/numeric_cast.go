@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// NumberFormat describes how numbers are written in a text column.
+type NumberFormat struct {
+	// DecimalSeparator separates the integer and fractional parts; it
+	// defaults to '.'.
+	DecimalSeparator rune
+	// GroupSeparator separates thousands, e.g. '.' in "1.234,56"; zero
+	// means numbers are not grouped.
+	GroupSeparator rune
+}
+
+// numericCast is a string-to-numeric conversion registered with
+// WithNumericCast.
+type numericCast struct {
+	typ    arrow.DataType
+	format NumberFormat
+}
+
+// resolveNumericCasts validates the configured casts against fields and sets
+// the cast fields to their numeric types. It returns the cast for each column
+// index, or nil if none are configured.
+func resolveNumericCasts(fields []arrow.Field, casts map[string]numericCast) ([]*numericCast, error) {
+	if len(casts) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*numericCast, len(fields))
+	for name, c := range casts {
+		idx := -1
+		for i := range fields {
+			if fields[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot cast unknown column %q", name))
+		}
+		if fields[idx].Type.ID() != arrow.STRING {
+			return nil, errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("cannot cast column %q of type %s: only string columns can be cast", name, fields[idx].Type))
+		}
+		if c.typ == nil || !(arrow.IsInteger(c.typ.ID()) || arrow.IsFloating(c.typ.ID())) || c.typ.ID() == arrow.FLOAT16 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot cast column %q to %v", name, c.typ))
+		}
+		if c.format.DecimalSeparator == 0 {
+			c.format.DecimalSeparator = '.'
+		}
+		if c.format.DecimalSeparator == c.format.GroupSeparator {
+			return nil, errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("number format of column %q uses %q as both decimal and group separator", name, c.format.DecimalSeparator))
+		}
+		fields[idx].Type = c.typ
+		out[idx] = &c
+	}
+	return out, nil
+}
+
+// append parses the scanned string value and appends it to fb.
+func (c *numericCast) append(fb array.Builder, value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case *sql.NullString:
+		if !v.Valid {
+			fb.AppendNull()
+			return nil
+		}
+		s = v.String
+	case *string:
+		s = *v
+	case *scanBuffer:
+		if !v.valid {
+			fb.AppendNull()
+			return nil
+		}
+		s = string(v.buf)
+	default:
+		return errors.New(errors.CodeInternal, fmt.Sprintf("unexpected scan destination %T for numeric cast", value))
+	}
+
+	n, err := c.parse(s)
+	if err != nil {
+		return errors.Wrapf(err, errors.CodeInvalidRequest, "cannot parse %q as %s", s, c.typ)
+	}
+	switch v := n.(type) {
+	case float32:
+		fb.(*array.Float32Builder).Append(v)
+	case float64:
+		fb.(*array.Float64Builder).Append(v)
+	default:
+		return appendDynamicInteger(fb, v)
+	}
+	return nil
+}
+
+// parse converts s, written in the cast's number format, to a Go value of
+// the cast type's width. Values out of range for the type are errors.
+func (c *numericCast) parse(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if c.format.GroupSeparator != 0 {
+		s = strings.ReplaceAll(s, string(c.format.GroupSeparator), "")
+	}
+	if c.format.DecimalSeparator != '.' {
+		if strings.ContainsRune(s, '.') {
+			return nil, fmt.Errorf("unexpected '.' in number")
+		}
+		s = strings.ReplaceAll(s, string(c.format.DecimalSeparator), ".")
+	}
+
+	bits := c.typ.(arrow.FixedWidthDataType).BitWidth()
+	switch {
+	case arrow.IsSignedInteger(c.typ.ID()):
+		return strconv.ParseInt(s, 10, bits)
+	case arrow.IsUnsignedInteger(c.typ.ID()):
+		return strconv.ParseUint(s, 10, bits)
+	case bits == 32:
+		f, err := strconv.ParseFloat(s, 32)
+		return float32(f), err
+	default:
+		return strconv.ParseFloat(s, 64)
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderNumericCast(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	european := NumberFormat{DecimalSeparator: ',', GroupSeparator: '.'}
+
+	t.Run("european float", func(t *testing.T) {
+		rows := queryRows(t, `SELECT * FROM (VALUES ('1.234,56', '1.000.000'), ('-0,5', '42'), (NULL, NULL)) t(amount, qty)`)
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithNumericCast("amount", arrow.PrimitiveTypes.Float64, european),
+			WithNumericCast("qty", arrow.PrimitiveTypes.Int32, european))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.Equal(t, arrow.PrimitiveTypes.Float64, reader.Schema().Field(0).Type)
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, reader.Schema().Field(1).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		amounts := rec.Column(0).(*array.Float64)
+		assert.InDelta(t, 1234.56, amounts.Value(0), 1e-9)
+		assert.Equal(t, -0.5, amounts.Value(1))
+		assert.True(t, amounts.IsNull(2))
+		qty := rec.Column(1).(*array.Int32)
+		assert.Equal(t, []int32{1000000, 42}, qty.Int32Values()[:2])
+		assert.True(t, qty.IsNull(2))
+	})
+
+	t.Run("unparseable value", func(t *testing.T) {
+		rows := queryRows(t, "SELECT '1.5' AS amount")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithNumericCast("amount", arrow.PrimitiveTypes.Float64, NumberFormat{DecimalSeparator: ','}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), `cannot parse "1.5"`)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		rows := queryRows(t, "SELECT '300' AS n")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithNumericCast("n", arrow.PrimitiveTypes.Int8, NumberFormat{}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.Error(t, reader.Err())
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		for _, opt := range []Option{
+			WithNumericCast("missing", arrow.PrimitiveTypes.Float64, NumberFormat{}),
+			WithNumericCast("i", arrow.PrimitiveTypes.Float64, NumberFormat{}),
+			WithNumericCast("s", arrow.BinaryTypes.String, NumberFormat{}),
+			WithNumericCast("s", arrow.PrimitiveTypes.Float64, NumberFormat{DecimalSeparator: ',', GroupSeparator: ','}),
+		} {
+			_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS i, '1' AS s"), logger, opt)
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// Observer receives BatchReader events, letting servers export metrics
+// without tying this package to a particular metrics library.
+type Observer interface {
+	// OnBatch is called after each batch is built with its row count,
+	// buffer size in bytes and build duration.
+	OnBatch(rows int, bytes int64, dur time.Duration)
+	// OnError is called once when reading fails.
+	OnError(err error)
+}
+
+// recordBytes returns the total size of the buffers backing rec.
+func recordBytes(rec arrow.Record) int64 {
+	var total int64
+	for _, col := range rec.Columns() {
+		total += arrayDataBytes(col.Data())
+	}
+	return total
+}
+
+func arrayDataBytes(data arrow.ArrayData) int64 {
+	var total int64
+	for _, buf := range data.Buffers() {
+		if buf != nil {
+			total += int64(buf.Len())
+		}
+	}
+	for _, child := range data.Children() {
+		total += arrayDataBytes(child)
+	}
+	return total
+}
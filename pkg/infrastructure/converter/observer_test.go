@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchEvent struct {
+	rows  int
+	bytes int64
+	dur   time.Duration
+}
+
+type fakeObserver struct {
+	mu      sync.Mutex
+	batches []batchEvent
+	errs    []error
+}
+
+func (o *fakeObserver) OnBatch(rows int, bytes int64, dur time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.batches = append(o.batches, batchEvent{rows: rows, bytes: bytes, dur: dur})
+}
+
+func (o *fakeObserver) OnError(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errs = append(o.errs, err)
+}
+
+func TestBatchReaderMetricsObserver(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("batches", func(t *testing.T) {
+		obs := &fakeObserver{}
+		rows := queryRows(t, "SELECT i FROM range(5) t(i)")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithMetricsObserver(obs))
+		require.NoError(t, err)
+		defer reader.Release()
+		reader.SetBatchSize(2)
+
+		for reader.Next() {
+		}
+		require.NoError(t, reader.Err())
+
+		require.Len(t, obs.batches, 3)
+		assert.Equal(t, []int{2, 2, 1}, []int{obs.batches[0].rows, obs.batches[1].rows, obs.batches[2].rows})
+		for _, b := range obs.batches {
+			// At least the int64 values themselves
+			assert.GreaterOrEqual(t, b.bytes, int64(b.rows*8))
+			assert.Greater(t, b.dur, time.Duration(0))
+		}
+		assert.Empty(t, obs.errs)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		obs := &fakeObserver{}
+		rows := queryRows(t, "SELECT 18446744073709551615::UBIGINT AS h")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger,
+			WithUnifyIntegers(), WithMetricsObserver(obs))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		assert.False(t, reader.Next())
+		assert.False(t, reader.Next())
+		require.Len(t, obs.errs, 1)
+		assert.Equal(t, reader.Err(), obs.errs[0])
+		assert.Empty(t, obs.batches)
+	})
+}
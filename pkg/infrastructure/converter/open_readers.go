@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+var (
+	// maxOpenReaders is the SetMaxOpenReaders limit, or 0 for none.
+	maxOpenReaders atomic.Int64
+	// openReaders counts the BatchReaders over SQL rows not yet cleaned up.
+	openReaders atomic.Int64
+)
+
+// SetMaxOpenReaders limits how many BatchReaders over SQL rows may be open
+// at once, process-wide, as a guardrail against readers that are never
+// released and so keep their driver cursors open. Creating a reader beyond
+// the limit fails with CodeResourceExhausted until another reader is
+// released. Values of n <= 0 remove the limit, which is the default.
+// Readers that are already open are not affected by a lower limit.
+func SetMaxOpenReaders(n int) {
+	maxOpenReaders.Store(int64(max(n, 0)))
+}
+
+// OpenReaders returns the number of BatchReaders over SQL rows that have
+// not been released yet.
+func OpenReaders() int {
+	return int(openReaders.Load())
+}
+
+// acquireReaderSlot counts a new reader against the SetMaxOpenReaders limit.
+func acquireReaderSlot() error {
+	for {
+		open, limit := openReaders.Load(), maxOpenReaders.Load()
+		if limit > 0 && open >= limit {
+			return errors.New(errors.CodeResourceExhausted,
+				fmt.Sprintf("too many open readers (limit %d); release unused readers", limit))
+		}
+		if openReaders.CompareAndSwap(open, open+1) {
+			return nil
+		}
+	}
+}
+
+// releaseReaderSlot frees the slot of a cleaned up reader.
+func releaseReaderSlot() {
+	openReaders.Add(-1)
+}
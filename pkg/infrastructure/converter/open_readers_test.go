@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestSetMaxOpenReaders(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	base := OpenReaders()
+	SetMaxOpenReaders(base + 2)
+	t.Cleanup(func() { SetMaxOpenReaders(0) })
+
+	first, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS n"), logger)
+	require.NoError(t, err)
+	second, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 2 AS n"), logger)
+	require.NoError(t, err)
+	defer second.Release()
+	assert.Equal(t, base+2, OpenReaders())
+
+	_, err = NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 3 AS n"), logger)
+	require.Error(t, err)
+	assert.Equal(t, errors.CodeResourceExhausted, errors.GetCode(err))
+
+	// Releasing a reader frees its slot
+	first.Release()
+	assert.Equal(t, base+1, OpenReaders())
+	third, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 3 AS n"), logger)
+	require.NoError(t, err)
+	third.Release()
+	assert.Equal(t, base+1, OpenReaders())
+}
@@ -0,0 +1,616 @@
+package converter
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Option configures optional BatchReader behaviour.
+type Option func(*readerOptions)
+
+// readerOptions holds the optional settings applied to a BatchReader.
+type readerOptions struct {
+	leakCheck         bool
+	renames           map[string]string
+	unifyIntegers     bool
+	timeLayouts       []string
+	nullFills         map[string]interface{}
+	averageValueWidth int
+	observer          Observer
+	sessionTimeZone   string
+	skipRows          int64
+	limitRows         int64
+	dictionaryColumns []string
+	recycleBuffers    bool
+	columnZones       map[string]string
+	rowNumberColumn   string
+	scanDests         map[string]scanDest
+	booleanAsInt8     bool
+	channelBuffer     int
+	channelBufferSet  bool
+	batchSize         int
+	strictOffsets     bool
+	numericCasts      map[string]numericCast
+	bufferPool        bool
+	maxRecordRows     int64
+	unknownTypes      UnknownTypeMode
+	maxErrors         int
+	uhugeint          UHugeIntMode
+	computed          []computedColumn
+	narrowDecimals    bool
+	dayTimeIntervals  bool
+	columnComments    *columnCommentSource
+	stringDedup       bool
+	schemaCallback    func(*arrow.Schema) error
+	manifestStats     bool
+	nameTransform     func(string) string
+	floatRounding     FloatRoundingMode
+	schemaEvolution   bool
+	timestampEpoch    *time.Time
+	approxDistinct    bool
+	strictBooleans    bool
+	sortedBy          []string
+	decimalAsFloat64  bool
+	allResultSets     bool
+	builderFactory    BuilderFactory
+	decimalRounding   DecimalRoundingMode
+	singleBatch       bool
+	dateAsInt32       bool
+}
+
+// newReaderOptions applies the given options over the defaults.
+func newReaderOptions(opts []Option) readerOptions {
+	var o readerOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithLeakCheck tracks the record slices handed out by Record() and reports
+// any that are still retained when the reader is cleaned up.
+func WithLeakCheck() Option {
+	return func(o *readerOptions) {
+		o.leakCheck = true
+	}
+}
+
+// WithColumnRename renames columns of the inferred schema, mapping SQL column
+// names to the Arrow field names to emit. Types and metadata are preserved.
+// Renaming a column that is not part of the result is an error.
+func WithColumnRename(renames map[string]string) Option {
+	return func(o *readerOptions) {
+		o.renames = renames
+	}
+}
+
+// WithNameTransform applies transform, such as SnakeCase or CamelCase, to
+// the name of every column of the inferred schema, after WithColumnRename.
+// Computed and row number columns keep the names they are given. Two
+// columns whose names transform to the same name are an error.
+func WithNameTransform(transform func(string) string) Option {
+	return func(o *readerOptions) {
+		o.nameTransform = transform
+	}
+}
+
+// FloatRoundingMode selects how float64 values are narrowed when they are
+// appended to Float32 columns.
+type FloatRoundingMode int
+
+const (
+	// FloatRoundNearest rounds to the nearest float32, silently losing
+	// precision. This is the default.
+	FloatRoundNearest FloatRoundingMode = iota
+	// FloatRoundError fails on values that float32 cannot represent
+	// exactly, NaN excepted.
+	FloatRoundError
+)
+
+// WithFloatRounding sets how float64 values are narrowed into Float32
+// columns, such as FLOAT columns scanned through sql.NullFloat64 or DOUBLE
+// columns read with a Float32 field of a predefined schema.
+func WithFloatRounding(mode FloatRoundingMode) Option {
+	return func(o *readerOptions) {
+		o.floatRounding = mode
+	}
+}
+
+// WithUnifyIntegers maps every signed and unsigned integer column to Int64,
+// widening values on append. UBIGINT values above math.MaxInt64 are
+// reported as errors.
+func WithUnifyIntegers() Option {
+	return func(o *readerOptions) {
+		o.unifyIntegers = true
+	}
+}
+
+// WithBooleanAsInt8 emits top-level BOOLEAN columns as Int8, with true
+// and false written as 1 and 0, for engines with poor Arrow boolean
+// support. Nulls stay null.
+func WithBooleanAsInt8() Option {
+	return func(o *readerOptions) {
+		o.booleanAsInt8 = true
+	}
+}
+
+// WithNarrowDecimals emits top-level DECIMAL columns as the narrowest
+// Arrow decimal type fitting their precision: Decimal32 up to 9 digits,
+// Decimal64 up to 18 and Decimal128 beyond.
+func WithNarrowDecimals() Option {
+	return func(o *readerOptions) {
+		o.narrowDecimals = true
+	}
+}
+
+// WithDayTimeInterval emits top-level INTERVAL columns as Arrow DayTime
+// intervals instead of MonthDayNano, for consumers that only handle day and
+// time components. Values with a month component, or with time parts finer
+// than a millisecond, are errors.
+func WithDayTimeInterval() Option {
+	return func(o *readerOptions) {
+		o.dayTimeIntervals = true
+	}
+}
+
+// WithUnknownTypeMode selects whether columns whose type cannot be mapped
+// are read as strings (UnknownTypeString, the default) or rejected
+// (UnknownTypeError) when the reader is created.
+func WithUnknownTypeMode(mode UnknownTypeMode) Option {
+	return func(o *readerOptions) {
+		o.unknownTypes = mode
+	}
+}
+
+// WithErrorCollection keeps reading past values that fail to convert,
+// appending null in their place, for best-effort loads of dirty data. Up to
+// limit errors are collected with their row and column in the error context
+// and returned by CollectedErrors; the error after that fails the reader as
+// usual. Values of nested columns that fail part way through cannot be
+// replaced and always fail the reader.
+func WithErrorCollection(limit int) Option {
+	return func(o *readerOptions) {
+		o.maxErrors = limit
+	}
+}
+
+// WithUHugeIntMode selects whether UHUGEINT columns are read as
+// Decimal128 (UHugeIntDecimal, the default) or as strings (UHugeIntString),
+// which also hold values beyond the decimal range. The go-duckdb driver
+// used here cannot read UHUGEINT values: the first Next fails with
+// "unsupported data type: UHUGEINT", so the option only takes effect with
+// drivers that return such columns, e.g. as *big.Int.
+func WithUHugeIntMode(mode UHugeIntMode) Option {
+	return func(o *readerOptions) {
+		o.uhugeint = mode
+	}
+}
+
+// WithTimeLayouts sets the layouts, in order of preference, used to parse
+// temporal values that the driver returns as strings. It replaces the
+// default layouts covering DuckDB's text output.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *readerOptions) {
+		o.timeLayouts = layouts
+	}
+}
+
+// WithNullFill replaces nulls in the named columns with the given values and
+// emits those fields as non-nullable, for downstream systems that cannot
+// represent null. Columns are named as returned by the query, before any
+// WithColumnRename. A fill value must be convertible to the column type
+// without loss.
+func WithNullFill(fills map[string]interface{}) Option {
+	return func(o *readerOptions) {
+		o.nullFills = fills
+	}
+}
+
+// WithAverageValueWidth sets the average size in bytes assumed for
+// variable-width values by EstimateBatchBytes.
+func WithAverageValueWidth(width int) Option {
+	return func(o *readerOptions) {
+		o.averageValueWidth = width
+	}
+}
+
+// WithBatchSize sets the number of rows read per batch, as SetBatchSize
+// does, before the reader checks its configuration.
+func WithBatchSize(n int) Option {
+	return func(o *readerOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithStrictOffsetCheck makes NewBatchReader fail, instead of logging a
+// warning, when a full batch of variable-width values is estimated to
+// overflow the 32-bit offsets of a String or Binary column.
+func WithStrictOffsetCheck() Option {
+	return func(o *readerOptions) {
+		o.strictOffsets = true
+	}
+}
+
+// WithMetricsObserver reports batch and error events to obs from Next.
+func WithMetricsObserver(obs Observer) Option {
+	return func(o *readerOptions) {
+		o.observer = obs
+	}
+}
+
+// WithSessionTimeZone sets the IANA time zone attached to TIMESTAMPTZ
+// columns, "UTC" by default, so that consumers never see them as naive
+// timestamps; values are always stored as UTC instants. Plain TIMESTAMP
+// columns are tagged "UTC" whatever the session zone.
+func WithSessionTimeZone(timeZone string) Option {
+	return func(o *readerOptions) {
+		o.sessionTimeZone = timeZone
+	}
+}
+
+// WithDefaultTimestampZone sets the zone attached to TIMESTAMPTZ columns in
+// place of the default "UTC". It is the same option as WithSessionTimeZone.
+func WithDefaultTimestampZone(timeZone string) Option {
+	return WithSessionTimeZone(timeZone)
+}
+
+// WithMaxRecordRows caps the number of rows in each record returned by the
+// reader, independently of the batch size. Larger batches are handed out as
+// consecutive zero-copy slices that share the batch's buffers. Values of
+// n <= 0 mean no cap.
+func WithMaxRecordRows(n int64) Option {
+	return func(o *readerOptions) {
+		o.maxRecordRows = n
+	}
+}
+
+// WithSkipRows discards the first n rows of the result before any batch is
+// built, for offset-based pagination. Skipping past the end of the result
+// yields no records.
+func WithSkipRows(n int64) Option {
+	return func(o *readerOptions) {
+		o.skipRows = n
+	}
+}
+
+// WithLimitRows stops the reader after n rows have been emitted, closing
+// the underlying rows once the limit is reached. The last batch is cut
+// short if needed. Values of n <= 0 mean no limit.
+func WithLimitRows(n int64) Option {
+	return func(o *readerOptions) {
+		o.limitRows = n
+	}
+}
+
+// WithNumericCast emits the named string column as the integer or
+// floating-point type dt, parsing each value written in the given number
+// format, so that "1.234,56" reads as 1234.56 with NumberFormat{
+// DecimalSeparator: ',', GroupSeparator: '.'}. Values that do not parse or
+// do not fit dt are errors. Columns are named as returned by the query;
+// the option may be repeated for several columns.
+func WithNumericCast(column string, dt arrow.DataType, format NumberFormat) Option {
+	return func(o *readerOptions) {
+		if o.numericCasts == nil {
+			o.numericCasts = make(map[string]numericCast)
+		}
+		o.numericCasts[column] = numericCast{typ: dt, format: format}
+	}
+}
+
+// WithDictionaryColumns dictionary-encodes the named string columns. A
+// dotted path such as "s.category" selects a string field nested inside a
+// struct; lists along the path are traversed, so "tags" on a VARCHAR[]
+// column encodes its elements. Columns are named as returned by the query.
+func WithDictionaryColumns(paths ...string) Option {
+	return func(o *readerOptions) {
+		o.dictionaryColumns = paths
+	}
+}
+
+// WithBufferPool scans string and binary columns into byte buffers drawn
+// from a shared pool and reused for every row, instead of allocating a new
+// value per row. Values are copied into the Arrow builders before a buffer
+// is reused, and the buffers return to the pool when the reader is released.
+func WithBufferPool() Option {
+	return func(o *readerOptions) {
+		o.bufferPool = true
+	}
+}
+
+// WithRecyclingAllocator wraps the reader's allocator so that buffers freed
+// by released records are cached and reused for later batches, reducing
+// allocation churn on long streams.
+func WithRecyclingAllocator() Option {
+	return func(o *readerOptions) {
+		o.recycleBuffers = true
+	}
+}
+
+// WithColumnTimezone converts timestamps appended to the named DATE column
+// to civil dates in the given IANA time zone rather than UTC, so 23:00 UTC
+// in a +02 zone lands on the next day. Columns the database reports as DATE
+// carry no time of day and are left unchanged. Columns are named as returned
+// by the query; the option may be repeated for several columns.
+func WithColumnTimezone(column, timeZone string) Option {
+	return func(o *readerOptions) {
+		if o.columnZones == nil {
+			o.columnZones = make(map[string]string)
+		}
+		o.columnZones[column] = timeZone
+	}
+}
+
+// WithRowNumberColumn appends a non-nullable Int64 column with the given
+// name holding each row's position in the stream, counted from zero across
+// batches (after any WithSkipRows). The count never resets: a reader over
+// SQL rows cannot be rewound, so each number is used once for the life of
+// the reader.
+func WithRowNumberColumn(name string) Option {
+	return func(o *readerOptions) {
+		o.rowNumberColumn = name
+	}
+}
+
+// WithComputedColumn adds a nullable column with the given name whose value
+// is computed by expr from each scanned row, for derived columns without
+// post-processing. expr returns the value, or nil for null, and the
+// column's Arrow type, which must be the same for every row. It is called
+// once while the reader is created, with a row of nulls, to learn the type.
+// Computed columns follow the result columns, in the order the options are
+// given, and are only supported by NewBatchReader.
+func WithComputedColumn(name string, expr func(row RowView) (any, arrow.DataType)) Option {
+	return func(o *readerOptions) {
+		o.computed = append(o.computed, computedColumn{name: name, expr: expr})
+	}
+}
+
+// WithColumnComments copies the comments set with COMMENT ON COLUMN in
+// db's catalog to the ColumnCommentKey metadata of the matching fields.
+// Result columns are matched by name, as returned by the query, against the
+// columns of the given tables, or of every table if none are given; a name
+// commented differently in several tables gets no comment. This costs a
+// catalog query each time a reader is created.
+func WithColumnComments(db *sql.DB, tables ...string) Option {
+	return func(o *readerOptions) {
+		o.columnComments = &columnCommentSource{db: db, tables: tables}
+	}
+}
+
+// WithStringDedup switches string columns to string views (utf8_view) so
+// that repeated values can be stored once per batch. It changes the schema
+// and is opt-in for that reason: the string layout cannot share bytes
+// between values, as its offsets may never decrease. In the views, values
+// longer than 12 bytes point into a data buffer holding each distinct value
+// of the batch once; shorter values are inlined either way. Each batch is
+// built as strings and then copied with a hash lookup per value, so reading
+// allocates more, while the records handed out are smaller when repeated
+// values are long: BenchmarkBatchReaderStringDedup shows 78% less record
+// memory for 70-byte values, but only 7% for a mix of short ones, since a
+// view takes 16 bytes per row against 4 for an offset. WithDictionaryColumns
+// suits low-cardinality columns better when consumers accept dictionaries.
+// Consumers must support string views.
+func WithStringDedup() Option {
+	return func(o *readerOptions) {
+		o.stringDedup = true
+	}
+}
+
+// WithSchemaCallback has NewBatchReader pass the inferred schema, with all
+// options applied, to fn before any row is read. An error returned by fn
+// aborts the construction of the reader and is returned by NewBatchReader.
+func WithSchemaCallback(fn func(*arrow.Schema) error) Option {
+	return func(o *readerOptions) {
+		o.schemaCallback = fn
+	}
+}
+
+// WithManifestStats collects the null count and the range of values of
+// each column as batches are read, for the Columns of Manifest. This
+// visits every value once more.
+func WithManifestStats() Option {
+	return func(o *readerOptions) {
+		o.manifestStats = true
+	}
+}
+
+// WithSchemaEvolution lets NewBatchReader widen an integer column whose
+// values outgrow the inferred type, as can happen when a driver reports one
+// type for a UNION ALL of differently typed inputs. The batch being read
+// ends before the first row that does not fit; the column then becomes
+// Int64 (Uint64 for unsigned columns) and the following records carry the
+// new schema, which Schema also reports from then on and which is passed
+// again to the WithSchemaCallback function, if any.
+//
+// Consumers must therefore compare each record's schema with the previous
+// one instead of relying on the schema read up front: Arrow IPC and Flight
+// streams, for instance, need a new stream or a cast when it changes.
+// Records already returned keep the narrower type.
+func WithSchemaEvolution() Option {
+	return func(o *readerOptions) {
+		o.schemaEvolution = true
+	}
+}
+
+// WithTimestampEpoch stores timestamp values as the number of units since
+// epoch rather than since the Unix epoch, for sinks that count from another
+// origin such as 1900-01-01. The column types are unchanged, so the values
+// are only meaningful to a consumer that knows the epoch. Dates and times
+// of day are not affected.
+func WithTimestampEpoch(epoch time.Time) Option {
+	return func(o *readerOptions) {
+		o.timestampEpoch = &epoch
+	}
+}
+
+// WithApproxDistinct estimates the number of distinct values of each
+// column with a HyperLogLog sketch as batches are read, for ApproxDistinct.
+// Each column costs 4 KiB and one hash per value; estimates are typically
+// within a few percent.
+func WithApproxDistinct() Option {
+	return func(o *readerOptions) {
+		o.approxDistinct = true
+	}
+}
+
+// WithStrictBooleans makes a boolean the driver returned as a string an
+// error unless it is a recognized spelling such as "t", "false", "yes" or
+// "off". By default such values are appended as null, with a warning
+// logged for the first in each column, except in non-nullable columns,
+// where they are always an error.
+func WithStrictBooleans() Option {
+	return func(o *readerOptions) {
+		o.strictBooleans = true
+	}
+}
+
+// WithSortedBy records in the schema metadata, under SortedByKey, that the
+// rows are sorted by columns, in order, as by the ORDER BY of the query.
+// The reader does not check the order; it only passes the hint on to
+// consumers. Each column must be in the result, named as after renames.
+func WithSortedBy(columns []string) Option {
+	return func(o *readerOptions) {
+		o.sortedBy = slices.Clone(columns)
+	}
+}
+
+// WithDecimalAsFloat64 maps decimal columns to Float64, each value being
+// the nearest float64 to the decimal, for sinks without decimal support.
+// Digits beyond float64's precision, about 15 significant ones, are lost.
+// It takes precedence over WithNarrowDecimals.
+func WithDecimalAsFloat64() Option {
+	return func(o *readerOptions) {
+		o.decimalAsFloat64 = true
+	}
+}
+
+// WithAllResultSets reads every result set of a multi-statement query in
+// turn, advancing with NextResultSet once one is exhausted, instead of the
+// first only. Every result set must have the columns of the first, by name
+// and database type; a mismatch ends the reader with an error.
+func WithAllResultSets() Option {
+	return func(o *readerOptions) {
+		o.allResultSets = true
+	}
+}
+
+// WithBuilderFactory has the builders of top-level extension-typed fields,
+// such as JSON, UUID or geometry columns, created by factory, so that
+// callers can plug in builders for their own extension types. The
+// records keep the fields' types. A builder implementing ValueAppender
+// receives the scanned values directly; others are appended to like the
+// default ones.
+func WithBuilderFactory(factory BuilderFactory) Option {
+	return func(o *readerOptions) {
+		o.builderFactory = factory
+	}
+}
+
+// DecimalRoundingMode selects what happens to the fractional digits of a
+// decimal beyond the scale of its column, as when the schema given to
+// NewBatchReaderWithSchema has a smaller scale than the query's DECIMAL.
+type DecimalRoundingMode int
+
+const (
+	// DecimalRoundError fails on values that would lose digits. This is
+	// the default.
+	DecimalRoundError DecimalRoundingMode = iota
+	// DecimalRoundTruncate drops the extra digits, rounding toward zero.
+	DecimalRoundTruncate
+	// DecimalRoundHalfUp rounds to the nearest value, ties away from zero.
+	DecimalRoundHalfUp
+	// DecimalRoundHalfEven rounds to the nearest value, ties to the even
+	// last digit.
+	DecimalRoundHalfEven
+)
+
+// WithDecimalRounding sets how decimal values are fitted to a column of
+// smaller scale. Values that still exceed the column's precision once
+// rounded are errors.
+func WithDecimalRounding(mode DecimalRoundingMode) Option {
+	return func(o *readerOptions) {
+		o.decimalRounding = mode
+	}
+}
+
+// WithSingleBatch reads the whole result into one record, whatever the
+// batch size, for consumers of small queries. Results of more than 2^20
+// rows are errors with CodeResourceExhausted rather than a risk of running
+// out of memory. WithMaxRecordRows and WithLimitRows still apply.
+func WithSingleBatch() Option {
+	return func(o *readerOptions) {
+		o.singleBatch = true
+	}
+}
+
+// WithDateAsInt32 emits top-level DATE columns as plain Int32 holding the
+// days since the Unix epoch, the Date32 value without its logical type,
+// for storage-oriented sinks. WithColumnTimezone still picks the day.
+func WithDateAsInt32() Option {
+	return func(o *readerOptions) {
+		o.dateAsInt32 = true
+	}
+}
+
+// WithScanDest scans the named column into the value returned by factory,
+// typically a pointer to a type implementing sql.Scanner, and hands it to
+// appendFn together with the column's builder for each row. The factory is
+// called once; its destination is reused for every row. Columns are named
+// as returned by the query; the option may be repeated for several columns.
+func WithScanDest(column string, factory func() any, appendFn func(any, array.Builder) error) Option {
+	return func(o *readerOptions) {
+		if o.scanDests == nil {
+			o.scanDests = make(map[string]scanDest)
+		}
+		o.scanDests[column] = scanDest{factory: factory, append: appendFn}
+	}
+}
+
+// WithChannelBuffer sets how many records Channel buffers ahead of a slow
+// consumer before the producer blocks. Zero makes the channel unbuffered.
+// The default is 8.
+func WithChannelBuffer(n int) Option {
+	return func(o *readerOptions) {
+		o.channelBuffer = max(n, 0)
+		o.channelBufferSet = true
+	}
+}
+
+// appendRowNumberField adds the WithRowNumberColumn field to fields, if one
+// is configured.
+func appendRowNumberField(fields []arrow.Field, name string) ([]arrow.Field, error) {
+	if name == "" {
+		return fields, nil
+	}
+	for _, f := range fields {
+		if f.Name == name {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("row number column %q clashes with a result column", name))
+		}
+	}
+	return append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int64}), nil
+}
+
+// renameFields applies the configured renames to fields in place.
+func renameFields(fields []arrow.Field, renames map[string]string) error {
+	matched := make(map[string]bool, len(renames))
+	for i := range fields {
+		if to, ok := renames[fields[i].Name]; ok {
+			matched[fields[i].Name] = true
+			fields[i].Name = to
+		}
+	}
+	for from := range renames {
+		if !matched[from] {
+			return errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot rename unknown column %q", from))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// PartitionStream splits the stream of reader into n partitions that can be
+// consumed independently and concurrently, for instance to serve each one
+// from its own Flight DoGet ticket. Record batches are dealt out round-robin
+// as they are read, so partition i gets batches i, i+n, i+2n and so on, and
+// together the partitions hold every row of reader exactly once. The row
+// order within a partition follows reader, but no order holds across
+// partitions. A value of n below 1 is treated as 1.
+//
+// Batches are not read ahead: a partition needing a batch reads reader
+// until it gets one, and keeps the batches meant for the other partitions
+// buffered until they are read. Partitions consumed at the same pace thus
+// buffer at most about one batch each, but a partition left behind makes
+// the others buffer every batch it has yet to read, up to the whole result
+// if it is never read. Release partitions that won't be read: batches
+// dealt to a released partition are dropped. An error reading reader is
+// reported by every partition once it has read its buffered batches.
+// PartitionStream takes ownership of reader, which is released along with
+// the last partition.
+func PartitionStream(reader *BatchReader, n int) []*BatchReader {
+	if n < 1 {
+		n = 1
+	}
+	d := &partitionDealer{input: reader, queues: make([][]arrow.Record, n), open: make([]bool, n), live: n}
+	parts := make([]*BatchReader, n)
+	for i := range parts {
+		d.open[i] = true
+		p := i
+		parts[i] = newDerivedReader(reader.Schema(), reader.allocator, reader.logger, recordSource{
+			next:  func() (arrow.Record, error) { return d.next(p) },
+			close: func() { d.close(p) },
+		})
+	}
+	return parts
+}
+
+// partitionDealer deals the batches of the PartitionStream input out to
+// the partitions.
+type partitionDealer struct {
+	mu     sync.Mutex
+	input  *BatchReader
+	queues [][]arrow.Record // batches read for each partition, not yet handed out
+	open   []bool           // partitions not yet released
+	live   int              // number of open partitions
+	turn   int              // partition getting the next batch
+	done   bool             // the input is exhausted
+	err    error
+}
+
+// next returns the next batch of partition p, or nil at the end of its
+// share of the stream.
+func (d *partitionDealer) next(p int) (arrow.Record, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for len(d.queues[p]) == 0 {
+		if d.done {
+			return nil, d.err
+		}
+		d.deal()
+	}
+	rec := d.queues[p][0]
+	d.queues[p][0] = nil
+	d.queues[p] = d.queues[p][1:]
+	return rec, nil
+}
+
+// deal reads the next batch of the input and queues it for the partition
+// whose turn it is.
+func (d *partitionDealer) deal() {
+	if !d.input.Next() {
+		d.done = true
+		if err := d.input.Err(); err != nil {
+			d.err = errors.Wrap(err, errors.CodeInternal, "failed to read partitioned input")
+		}
+		return
+	}
+	rec, err := d.input.TakeRecord()
+	if err != nil {
+		d.done = true
+		d.err = err
+		return
+	}
+	p := d.turn
+	d.turn = (d.turn + 1) % len(d.queues)
+	if !d.open[p] {
+		rec.Release()
+		return
+	}
+	d.queues[p] = append(d.queues[p], rec)
+}
+
+// close drops the buffered batches of partition p, and releases the input
+// once every partition is closed.
+func (d *partitionDealer) close(p int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, rec := range d.queues[p] {
+		rec.Release()
+	}
+	d.queues[p] = nil
+	d.open[p] = false
+	d.live--
+	if d.live == 0 {
+		d.input.Release()
+	}
+}
@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionStream(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// ids reads the id column of reader.
+	ids := func(t *testing.T, reader *BatchReader) []int64 {
+		var out []int64
+		for reader.Next() {
+			rec := reader.Record()
+			out = append(out, rec.Column(0).(*array.Int64).Int64Values()...)
+			rec.Release()
+		}
+		assert.NoError(t, reader.Err())
+		return out
+	}
+
+	t.Run("union equals original", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc, queryRows(t, "SELECT i AS id FROM range(100) t(i)"), logger, WithBatchSize(8))
+		require.NoError(t, err)
+		parts := PartitionStream(reader, 3)
+		require.Len(t, parts, 3)
+
+		got := make([][]int64, len(parts))
+		var wg sync.WaitGroup
+		for i, part := range parts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer part.Release()
+				got[i] = ids(t, part)
+			}()
+		}
+		wg.Wait()
+
+		var all []int64
+		for i, part := range got {
+			assert.True(t, sort.SliceIsSorted(part, func(a, b int) bool { return part[a] < part[b] }))
+			// Batches of eight are dealt round-robin
+			assert.Equal(t, int64(8*i), part[0])
+			all = append(all, part...)
+		}
+		sort.Slice(all, func(a, b int) bool { return all[a] < all[b] })
+		want := make([]int64, 100)
+		for i := range want {
+			want[i] = int64(i)
+		}
+		assert.Equal(t, want, all)
+	})
+
+	t.Run("released partition", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc, queryRows(t, "SELECT i AS id FROM range(40) t(i)"), logger, WithBatchSize(10))
+		require.NoError(t, err)
+		parts := PartitionStream(reader, 2)
+		parts[1].Release()
+		defer parts[0].Release()
+
+		assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29}, ids(t, parts[0]))
+	})
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Both readers are interchangeable wherever an array.RecordReader is expected.
+var (
+	_ array.RecordReader = (*BatchReader)(nil)
+	_ array.RecordReader = (*ArrowPassthroughReader)(nil)
+)
+
+// ArrowPassthroughReader forwards record batches from a native Arrow result
+// without row-by-row conversion. Like BatchReader, the record returned by
+// Record() is owned by the caller and must be released.
+type ArrowPassthroughReader struct {
+	refCount atomic.Int64
+	source   array.RecordReader
+}
+
+// NewArrowPassthroughReader wraps a native Arrow result, taking ownership of it.
+func NewArrowPassthroughReader(source array.RecordReader) *ArrowPassthroughReader {
+	r := &ArrowPassthroughReader{source: source}
+	r.refCount.Store(1)
+	return r
+}
+
+// Schema returns the Arrow schema.
+func (r *ArrowPassthroughReader) Schema() *arrow.Schema {
+	return r.source.Schema()
+}
+
+// Retain increases the reference count.
+func (r *ArrowPassthroughReader) Retain() {
+	r.refCount.Add(1)
+}
+
+// Release decreases the reference count and releases the source when it reaches 0.
+func (r *ArrowPassthroughReader) Release() {
+	if r.refCount.Add(-1) == 0 {
+		r.source.Release()
+	}
+}
+
+// Next advances to the next record batch.
+func (r *ArrowPassthroughReader) Next() bool {
+	return r.source.Next()
+}
+
+// Record returns the current record batch.
+func (r *ArrowPassthroughReader) Record() arrow.Record {
+	rec := r.source.Record()
+	if rec == nil {
+		return nil
+	}
+	rec.Retain()
+	return rec
+}
+
+// Err returns any error that occurred during reading.
+func (r *ArrowPassthroughReader) Err() error {
+	return r.source.Err()
+}
+
+// NewRecordReader executes query on conn and returns a reader over its result.
+// When the driver can return Arrow natively the batches are passed through
+// unchanged; otherwise the rows are converted by a BatchReader.
+func NewRecordReader(ctx context.Context, conn *sql.Conn, allocator memory.Allocator, logger zerolog.Logger, query string, args ...interface{}) (array.RecordReader, error) {
+	native, ok, err := queryNativeArrow(ctx, conn, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to execute arrow query")
+	}
+	if ok {
+		logger.Debug().Msg("NewRecordReader: using native arrow passthrough")
+		return NewArrowPassthroughReader(native), nil
+	}
+
+	return queryBatchReader(ctx, conn, allocator, logger, query, args...)
+}
+
+// Query executes query on db and returns a BatchReader over its result. The
+// query runs under ctx: cancelling it interrupts the query in the driver and
+// makes the reader close its rows before the next batch.
+func Query(ctx context.Context, db *sql.DB, allocator memory.Allocator, logger zerolog.Logger, query string, args ...interface{}) (*BatchReader, error) {
+	return queryBatchReader(ctx, db, allocator, logger, query, args...)
+}
+
+// QueryTx executes query inside tx and returns a BatchReader over its result.
+// Readers created in the same transaction see the same snapshot of the
+// database, so several queries can be read consistently. The caller must
+// release each reader before committing or rolling back tx.
+func QueryTx(ctx context.Context, tx *sql.Tx, allocator memory.Allocator, logger zerolog.Logger, query string, args ...interface{}) (*BatchReader, error) {
+	return queryBatchReader(ctx, tx, allocator, logger, query, args...)
+}
+
+// queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryBatchReader executes query on q and converts its rows.
+func queryBatchReader(ctx context.Context, q queryer, allocator memory.Allocator, logger zerolog.Logger, query string, args ...interface{}) (*BatchReader, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to execute query")
+	}
+	return NewBatchReaderContext(ctx, allocator, rows, logger)
+}
@@ -0,0 +1,98 @@
+//go:build duckdb_arrow
+
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concatColumns joins each column of recs into a single array.
+func concatColumns(t *testing.T, schema *arrow.Schema, recs []arrow.Record) []arrow.Array {
+	t.Helper()
+
+	cols := make([]arrow.Array, schema.NumFields())
+	for c := range cols {
+		chunks := make([]arrow.Array, len(recs))
+		for i, rec := range recs {
+			chunks[i] = rec.Column(c)
+		}
+		col, err := array.Concatenate(chunks, memory.NewGoAllocator())
+		require.NoError(t, err)
+		cols[c] = col
+	}
+	return cols
+}
+
+func TestNewRecordReaderNativeMatchesRows(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewGoAllocator()
+	ctx := context.Background()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	const query = `SELECT
+		i::INTEGER AS id,
+		i * 1000000000 AS big,
+		i / 4 AS ratio,
+		i % 2 = 0 AS even,
+		CASE WHEN i % 3 = 0 THEN NULL ELSE 'row ' || i END AS label,
+		DATE '2024-01-01' + i::INTEGER AS day,
+		TIMESTAMP '2024-01-01 12:00:00' + to_seconds(i) AS ts
+		FROM range(5000) t(i) ORDER BY i`
+
+	reader, err := NewRecordReader(ctx, conn, alloc, logger, query)
+	require.NoError(t, err)
+	defer reader.Release()
+	_, native := reader.(*ArrowPassthroughReader)
+	require.True(t, native, "got %T", reader)
+	got := collectRecords(t, reader)
+
+	rows, err := conn.QueryContext(ctx, query)
+	require.NoError(t, err)
+	rowReader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	want := collectRecords(t, rowReader)
+	rowReader.Release()
+
+	// The paths batch differently, so whole columns are compared.
+	require.Equal(t, rowReader.Schema().NumFields(), reader.Schema().NumFields())
+	wantCols := concatColumns(t, rowReader.Schema(), want)
+	gotCols := concatColumns(t, reader.Schema(), got)
+	for c, field := range rowReader.Schema().Fields() {
+		assert.Equal(t, field.Name, reader.Schema().Field(c).Name)
+		if ts, ok := wantCols[c].(*array.Timestamp); ok {
+			// The row path tags TIMESTAMP columns with UTC, as
+			// FixedWidthTypes.Timestamp_us does, while DuckDB leaves them
+			// without a zone; the instants must still agree.
+			native, ok := gotCols[c].(*array.Timestamp)
+			require.True(t, ok, "column %s is %s", field.Name, gotCols[c].DataType())
+			assert.Equal(t, ts.DataType().(*arrow.TimestampType).Unit, native.DataType().(*arrow.TimestampType).Unit)
+			assert.Equal(t, ts.TimestampValues(), native.TimestampValues(), "column %s", field.Name)
+			continue
+		}
+		assert.True(t, arrow.TypeEqual(field.Type, gotCols[c].DataType()),
+			"column %s: rows %s, native %s", field.Name, field.Type, gotCols[c].DataType())
+		assert.True(t, array.Equal(wantCols[c], gotCols[c]), "column %s", field.Name)
+	}
+
+	for _, arr := range append(wantCols, gotCols...) {
+		arr.Release()
+	}
+	for _, rec := range append(want, got...) {
+		rec.Release()
+	}
+}
@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const passthroughQuery = "SELECT i::INTEGER AS id, 'row ' || i AS label FROM range(5) t(i)"
+
+// collectRecords drains reader, returning the records it produced.
+func collectRecords(t *testing.T, reader array.RecordReader) []arrow.Record {
+	t.Helper()
+
+	var recs []arrow.Record
+	for reader.Next() {
+		recs = append(recs, reader.Record())
+	}
+	require.NoError(t, reader.Err())
+	return recs
+}
+
+// assertSameColumns compares the column data of two record sequences.
+func assertSameColumns(t *testing.T, want, got []arrow.Record) {
+	t.Helper()
+
+	require.Len(t, got, len(want))
+	for i := range want {
+		require.Equal(t, want[i].NumCols(), got[i].NumCols())
+		for c := 0; c < int(want[i].NumCols()); c++ {
+			assert.True(t, array.Equal(want[i].Column(c), got[i].Column(c)), "record %d column %d", i, c)
+		}
+	}
+}
+
+func TestArrowPassthroughReader(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewGoAllocator()
+
+	rowReader, err := NewBatchReader(alloc, queryRows(t, passthroughQuery), logger)
+	require.NoError(t, err)
+	rowReader.SetBatchSize(2)
+	want := collectRecords(t, rowReader)
+	rowReader.Release()
+
+	native, err := array.NewRecordReader(want[0].Schema(), want)
+	require.NoError(t, err)
+	passthrough := NewArrowPassthroughReader(native)
+	defer passthrough.Release()
+
+	assert.True(t, want[0].Schema().Equal(passthrough.Schema()))
+	got := collectRecords(t, passthrough)
+	for i := range want {
+		assert.True(t, array.RecordEqual(want[i], got[i]), "record %d", i)
+	}
+
+	for _, rec := range append(want, got...) {
+		rec.Release()
+	}
+}
+
+func TestNewRecordReader(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewGoAllocator()
+	ctx := context.Background()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, passthroughQuery)
+	require.NoError(t, err)
+	rowReader, err := NewBatchReader(alloc, rows, logger)
+	require.NoError(t, err)
+	want := collectRecords(t, rowReader)
+	rowReader.Release()
+
+	reader, err := NewRecordReader(ctx, conn, alloc, logger, passthroughQuery)
+	require.NoError(t, err)
+	defer reader.Release()
+	got := collectRecords(t, reader)
+
+	assertSameColumns(t, want, got)
+	for _, rec := range append(want, got...) {
+		rec.Release()
+	}
+}
+
+func TestQueryTxSnapshot(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	ctx := context.Background()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t AS SELECT i FROM range(3) t(i)")
+	require.NoError(t, err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// count reads SELECT count(*) FROM t within the transaction.
+	count := func() int64 {
+		reader, err := QueryTx(ctx, tx, alloc, logger, "SELECT count(*) AS n FROM t")
+		require.NoError(t, err)
+		defer reader.Release()
+		recs := collectRecords(t, reader)
+		require.Len(t, recs, 1)
+		defer recs[0].Release()
+		return recs[0].Column(0).(*array.Int64).Value(0)
+	}
+
+	assert.EqualValues(t, 3, count())
+	// A write committed outside the transaction is not visible inside it.
+	_, err = db.Exec("INSERT INTO t VALUES (3)")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count())
+	require.NoError(t, tx.Commit())
+
+	var n int64
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM t").Scan(&n))
+	assert.EqualValues(t, 4, n)
+}
@@ -0,0 +1,44 @@
+package converter
+
+import "github.com/apache/arrow-go/v18/arrow"
+
+// Pipeline chains the reader wrappers of this package without nesting
+// calls:
+//
+//	reader := NewPipeline(input).Filter(pred).Map(fn).Coalesce(4096).Build()
+//
+// Each stage takes ownership of the reader built so far, as the wrapper it
+// stands for does, so only the reader returned by Build is to be released.
+type Pipeline struct {
+	reader *BatchReader
+}
+
+// NewPipeline starts a pipeline reading reader, taking ownership of it.
+func NewPipeline(reader *BatchReader) *Pipeline {
+	return &Pipeline{reader: reader}
+}
+
+// Filter keeps the rows for which predicate returns true, as Filter does.
+func (p *Pipeline) Filter(predicate func(RowView) bool) *Pipeline {
+	p.reader = Filter(p.reader, predicate)
+	return p
+}
+
+// Map transforms each record with fn, as MapRecords does.
+func (p *Pipeline) Map(fn func(arrow.Record) (arrow.Record, error)) *Pipeline {
+	p.reader = MapRecords(p.reader, fn)
+	return p
+}
+
+// Coalesce combines records until they hold at least rows rows, as
+// Coalesce does.
+func (p *Pipeline) Coalesce(rows int64) *Pipeline {
+	p.reader = Coalesce(p.reader, rows)
+	return p
+}
+
+// Build returns the reader over the output of the last stage. The caller
+// owns it and must release it.
+func (p *Pipeline) Build() *BatchReader {
+	return p.reader
+}
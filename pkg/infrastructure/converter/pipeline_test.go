@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc,
+		queryRows(t, "SELECT i AS id, 'row ' || i AS label FROM range(100) t(i)"),
+		logger, WithBatchSize(10))
+	require.NoError(t, err)
+
+	// negate replaces the ids of rec by their opposites.
+	negate := func(rec arrow.Record) (arrow.Record, error) {
+		b := array.NewInt64Builder(alloc)
+		defer b.Release()
+		for _, id := range rec.Column(0).(*array.Int64).Int64Values() {
+			b.Append(-id)
+		}
+		ids := b.NewArray()
+		defer ids.Release()
+		return array.NewRecord(rec.Schema(), []arrow.Array{ids, rec.Column(1)}, rec.NumRows()), nil
+	}
+
+	out := NewPipeline(reader).
+		Filter(func(row RowView) bool { return row.Value(0).(int64)%2 == 0 }).
+		Map(negate).
+		Coalesce(12).
+		Build()
+	defer out.Release()
+	assert.True(t, reader.Schema().Equal(out.Schema()))
+
+	var (
+		sizes []int64
+		next  int64
+	)
+	for out.Next() {
+		rec := out.Record()
+		sizes = append(sizes, rec.NumRows())
+		labels := rec.Column(1).(*array.String)
+		for i, id := range rec.Column(0).(*array.Int64).Int64Values() {
+			assert.Equal(t, -next, id)
+			assert.Equal(t, fmt.Sprintf("row %d", next), labels.Value(i))
+			next += 2
+		}
+		rec.Release()
+	}
+	require.NoError(t, out.Err())
+	// Batches of ten rows keep five each, coalesced three at a time.
+	assert.Equal(t, []int64{15, 15, 15, 5}, sizes)
+}
+
+func TestCoalesceNonPositiveRows(t *testing.T) {
+	for _, rows := range []int64{0, -1} {
+		t.Run(fmt.Sprint(rows), func(t *testing.T) {
+			logger := zerolog.New(zerolog.NewTestWriter(t))
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+
+			reader, err := NewBatchReader(alloc, queryRows(t, "SELECT i FROM range(10) t(i)"), logger, WithBatchSize(4))
+			require.NoError(t, err)
+
+			out := NewPipeline(reader).Coalesce(rows).Build()
+			defer out.Release()
+
+			var sizes []int64
+			for out.Next() {
+				rec := out.Record()
+				sizes = append(sizes, rec.NumRows())
+				rec.Release()
+			}
+			require.NoError(t, out.Err())
+			// Records are passed on unchanged rather than dropped.
+			assert.Equal(t, []int64{4, 4, 2}, sizes)
+		})
+	}
+}
+
+func TestMapRecordsSchemaMismatch(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc, queryRows(t, "SELECT i AS id, 'x' AS label FROM range(3) t(i)"), logger)
+	require.NoError(t, err)
+
+	out := MapRecords(reader, func(rec arrow.Record) (arrow.Record, error) {
+		// Dropping the label column changes the schema.
+		schema := arrow.NewSchema([]arrow.Field{rec.Schema().Field(0)}, nil)
+		return array.NewRecord(schema, []arrow.Array{rec.Column(0)}, rec.NumRows()), nil
+	})
+	defer out.Release()
+	assert.False(t, out.Next())
+	assert.ErrorContains(t, out.Err(), "does not match the reader's schema")
+}
@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// PreparedReader is a query validated by PrepareReader, opened as many
+// times as needed. It must be closed when no longer used.
+type PreparedReader struct {
+	stmt   *sql.Stmt
+	args   []interface{}
+	schema *arrow.Schema
+	logger zerolog.Logger
+}
+
+// PrepareReader prepares query on db without reading its result, so a
+// server can reject a query and describe its result before paying for its
+// execution. Statements that do not compile are errors with
+// CodeInvalidRequest. The schema is inferred by planning the query with
+// LIMIT 0 and args, so only queries usable as a subquery can be prepared.
+func PrepareReader(ctx context.Context, db *sql.DB, logger zerolog.Logger, query string, args ...interface{}) (*PreparedReader, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx.Err())
+		}
+		return nil, errors.Wrap(err, errors.CodeInvalidRequest, "failed to prepare query")
+	}
+
+	schema, err := describeQuery(ctx, db, logger, query, args)
+	if err != nil {
+		stmt.Close()
+		return nil, err
+	}
+	return &PreparedReader{stmt: stmt, args: args, schema: schema, logger: logger}, nil
+}
+
+// describeQuery returns the schema a reader without options would have
+// for query, executing it with LIMIT 0 so that no row is produced.
+func describeQuery(ctx context.Context, db *sql.DB, logger zerolog.Logger, query string, args []interface{}) (*arrow.Schema, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	rows, err := db.QueryContext(ctx, "SELECT * FROM ("+query+") LIMIT 0", args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx.Err())
+		}
+		return nil, errors.Wrap(err, errors.CodeInvalidRequest, "failed to describe query result")
+	}
+
+	reader, err := NewBatchReader(memory.DefaultAllocator, rows, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+	return reader.Schema(), nil
+}
+
+// Schema returns the schema of the query's result as read without
+// options. Options given to Open may change it.
+func (p *PreparedReader) Schema() *arrow.Schema {
+	return p.schema
+}
+
+// Open executes the prepared query and returns a BatchReader over its
+// result, as Query does.
+func (p *PreparedReader) Open(ctx context.Context, allocator memory.Allocator, opts ...Option) (*BatchReader, error) {
+	rows, err := p.stmt.QueryContext(ctx, p.args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeQueryFailed, "failed to execute query")
+	}
+	return NewBatchReaderContext(ctx, allocator, rows, p.logger, opts...)
+}
+
+// Close releases the prepared statement. Readers already opened are not
+// affected.
+func (p *PreparedReader) Close() error {
+	return p.stmt.Close()
+}
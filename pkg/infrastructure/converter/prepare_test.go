@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestPrepareReader(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	ctx := context.Background()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := PrepareReader(ctx, db, logger, "SELEC 1")
+		require.Error(t, err)
+		assert.Equal(t, errors.CodeInvalidRequest, errors.GetCode(err))
+
+		_, err = PrepareReader(ctx, db, logger, "SELECT missing FROM range(3)")
+		assert.Equal(t, errors.CodeInvalidRequest, errors.GetCode(err))
+	})
+
+	t.Run("schema and open", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		prepared, err := PrepareReader(ctx, db, logger,
+			"SELECT range AS id, 'n' || range AS name FROM range(?);", 3)
+		require.NoError(t, err)
+		defer prepared.Close()
+
+		schema := prepared.Schema()
+		require.Equal(t, 2, schema.NumFields())
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, schema.Field(0).Type)
+		assert.Equal(t, arrow.BinaryTypes.String, schema.Field(1).Type)
+
+		// The statement runs again on each Open.
+		for i := 0; i < 2; i++ {
+			reader, err := prepared.Open(ctx, alloc, WithColumnRename(map[string]string{"id": "key"}))
+			require.NoError(t, err)
+			assert.Equal(t, "key", reader.Schema().Field(0).Name)
+
+			var n int64
+			for reader.Next() {
+				rec := reader.Record()
+				n += rec.NumRows()
+				rec.Release()
+			}
+			require.NoError(t, reader.Err())
+			assert.Equal(t, int64(3), n)
+			reader.Release()
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// RecordToRows converts rec into rows of driver-friendly values, suitable as
+// arguments to a prepared INSERT. It inverts the DuckDB to Arrow mapping:
+// timestamps and dates become time.Time, times and decimals become strings
+// DuckDB casts implicitly, lists become []interface{} and structs
+// map[string]interface{}. Nulls are returned as nil.
+func RecordToRows(rec arrow.Record) ([][]interface{}, error) {
+	numRows := int(rec.NumRows())
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, rec.NumCols())
+	}
+
+	for colIdx, col := range rec.Columns() {
+		for rowIdx := 0; rowIdx < numRows; rowIdx++ {
+			v, err := arrowValue(col, rowIdx)
+			if err != nil {
+				return nil, errors.Wrapf(err, errors.CodeInvalidRequest,
+					"cannot convert column %q at row %d", rec.ColumnName(colIdx), rowIdx)
+			}
+			rows[rowIdx][colIdx] = v
+		}
+	}
+	return rows, nil
+}
+
+// arrowValue returns the value at index i of arr as a driver value.
+func arrowValue(arr arrow.Array, i int) (interface{}, error) {
+	if arr.IsNull(i) {
+		return nil, nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return a.Value(i), nil
+	case *array.Int8:
+		return a.Value(i), nil
+	case *array.Int16:
+		return a.Value(i), nil
+	case *array.Int32:
+		return a.Value(i), nil
+	case *array.Int64:
+		return a.Value(i), nil
+	case *array.Uint8:
+		return a.Value(i), nil
+	case *array.Uint16:
+		return a.Value(i), nil
+	case *array.Uint32:
+		return a.Value(i), nil
+	case *array.Uint64:
+		return a.Value(i), nil
+	case *array.Float32:
+		return a.Value(i), nil
+	case *array.Float64:
+		return a.Value(i), nil
+	case *array.String:
+		return a.Value(i), nil
+	case *array.LargeString:
+		return a.Value(i), nil
+	case *array.Binary:
+		// Copy out of the Arrow buffer, which may be released before use.
+		return bytes.Clone(a.Value(i)), nil
+	case *array.LargeBinary:
+		return bytes.Clone(a.Value(i)), nil
+	case *array.Timestamp:
+		return a.Value(i).ToTime(a.DataType().(*arrow.TimestampType).Unit), nil
+	case *array.Date32:
+		return a.Value(i).ToTime(), nil
+	case *array.Date64:
+		return a.Value(i).ToTime(), nil
+	case *array.Time32:
+		return a.Value(i).FormattedString(a.DataType().(*arrow.Time32Type).Unit), nil
+	case *array.Time64:
+		return a.Value(i).FormattedString(a.DataType().(*arrow.Time64Type).Unit), nil
+	case *array.Decimal32:
+		return a.Value(i).ToString(a.DataType().(*arrow.Decimal32Type).Scale), nil
+	case *array.Decimal64:
+		return a.Value(i).ToString(a.DataType().(*arrow.Decimal64Type).Scale), nil
+	case *array.Decimal128:
+		return a.Value(i).ToString(a.DataType().(*arrow.Decimal128Type).Scale), nil
+	case *array.Dictionary:
+		return arrowValue(a.Dictionary(), a.GetValueIndex(i))
+	case *array.List:
+		start, end := a.ValueOffsets(i)
+		values := make([]interface{}, 0, end-start)
+		for j := start; j < end; j++ {
+			v, err := arrowValue(a.ListValues(), int(j))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case *array.Struct:
+		st := a.DataType().(*arrow.StructType)
+		values := make(map[string]interface{}, st.NumFields())
+		for f := 0; f < st.NumFields(); f++ {
+			v, err := arrowValue(a.Field(f), i)
+			if err != nil {
+				return nil, err
+			}
+			values[st.Field(f).Name] = v
+		}
+		return values, nil
+	case array.ExtensionArray:
+		return arrowValue(a.Storage(), i)
+	default:
+		return nil, fmt.Errorf("unsupported array type %s", arr.DataType())
+	}
+}
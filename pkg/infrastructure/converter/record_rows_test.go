@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordToRowsRoundTrip(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	mem := memory.NewGoAllocator()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+	}, nil)
+
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 250000000, time.UTC)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	b.Field(2).(*array.BooleanBuilder).AppendValues([]bool{true, false}, nil)
+	b.Field(3).(*array.Float64Builder).AppendValues([]float64{1.5, 0}, []bool{true, false})
+	b.Field(4).(*array.TimestampBuilder).AppendValues(
+		[]arrow.Timestamp{arrow.Timestamp(ts.UnixMicro()), 0}, []bool{true, false})
+	b.Field(5).(*array.Date32Builder).AppendValues(
+		[]arrow.Date32{arrow.Date32FromTime(ts), 0}, []bool{true, false})
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	rows, err := RecordToRows(rec)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, []interface{}{int32(1), "a", true, 1.5, ts, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, rows[0])
+	assert.Equal(t, []interface{}{int32(2), nil, false, nil, nil, nil}, rows[1])
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER, name VARCHAR,
+		ok BOOLEAN, score DOUBLE, ts TIMESTAMP, day DATE)`)
+	require.NoError(t, err)
+	stmt, err := db.Prepare("INSERT INTO t VALUES (?, ?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+	defer stmt.Close()
+	for _, row := range rows {
+		_, err := stmt.Exec(row...)
+		require.NoError(t, err)
+	}
+
+	result, err := db.Query("SELECT * FROM t ORDER BY id")
+	require.NoError(t, err)
+	reader, err := NewBatchReaderWithSchema(mem, schema, result, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	got := reader.Record()
+	defer got.Release()
+	assert.True(t, array.RecordEqual(rec, got), "got %v", got)
+}
+
+func TestRecordToRowsNestedAndDecimal(t *testing.T) {
+	mem := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+		{Name: "s", Type: arrow.StructOf(arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int64, Nullable: true}), Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	b.Field(0).(*array.Decimal128Builder).Append(decimal128.FromI64(-12345))
+	lb := b.Field(1).(*array.ListBuilder)
+	lb.Append(true)
+	lb.ValueBuilder().(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	sb := b.Field(2).(*array.StructBuilder)
+	sb.Append(true)
+	sb.FieldBuilder(0).(*array.Int64Builder).Append(7)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	rows, err := RecordToRows(rec)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{
+		"-123.45",
+		[]interface{}{"a", "b"},
+		map[string]interface{}{"x": int64(7)},
+	}}, rows)
+}
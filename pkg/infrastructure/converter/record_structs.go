@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// RecordToStructs converts rec into one T per row. Columns are matched to
+// the exported fields of T by `db` tag, then by field name
+// case-insensitively; columns without a field are ignored. Values are
+// those of RecordToRows, converted to the field's type where Go allows it
+// without loss: integers and floats between numeric fields, lists into
+// slices and structs into nested structs. A null leaves the field at its
+// zero value, so pointer fields tell nulls apart.
+func RecordToStructs[T any](rec arrow.Record) ([]T, error) {
+	rt := reflect.TypeFor[T]()
+	if rt.Kind() != reflect.Struct {
+		return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot convert a record to %s, a struct type is required", rt))
+	}
+
+	numRows := int(rec.NumRows())
+	out := make([]T, numRows)
+	for colIdx, col := range rec.Columns() {
+		fieldIdx, ok := goStructField(rt, rec.ColumnName(colIdx))
+		if !ok {
+			continue
+		}
+		for rowIdx := 0; rowIdx < numRows; rowIdx++ {
+			v, err := arrowValue(col, rowIdx)
+			if err == nil {
+				err = assignGoValue(reflect.ValueOf(&out[rowIdx]).Elem().Field(fieldIdx), v)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, errors.CodeInvalidRequest,
+					"cannot convert column %q at row %d", rec.ColumnName(colIdx), rowIdx)
+			}
+		}
+	}
+	return out, nil
+}
+
+// assignGoValue sets dst to v, a value returned by arrowValue. A nil v
+// leaves dst unchanged.
+func assignGoValue(dst reflect.Value, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignGoValue(elem.Elem(), v); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+
+	switch val := v.(type) {
+	case []interface{}:
+		if dst.Kind() != reflect.Slice {
+			break
+		}
+		s := reflect.MakeSlice(dst.Type(), len(val), len(val))
+		for i, elem := range val {
+			if err := assignGoValue(s.Index(i), elem); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(s)
+		return nil
+	case map[string]interface{}:
+		if dst.Kind() != reflect.Struct {
+			break
+		}
+		for name, fv := range val {
+			if i, ok := goStructField(dst.Type(), name); ok {
+				if err := assignGoValue(dst.Field(i), fv); err != nil {
+					return fmt.Errorf("field %q: %w", name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	switch {
+	case rv.CanInt() && isGoNumber(dst):
+		n := rv.Int()
+		if dst.CanInt() && !dst.OverflowInt(n) ||
+			dst.CanUint() && n >= 0 && !dst.OverflowUint(uint64(n)) ||
+			dst.CanFloat() {
+			dst.Set(rv.Convert(dst.Type()))
+			return nil
+		}
+		return fmt.Errorf("value %d overflows %s", n, dst.Type())
+	case rv.CanUint() && isGoNumber(dst):
+		u := rv.Uint()
+		if dst.CanInt() && u <= uint64(1<<63-1) && !dst.OverflowInt(int64(u)) ||
+			dst.CanUint() && !dst.OverflowUint(u) ||
+			dst.CanFloat() {
+			dst.Set(rv.Convert(dst.Type()))
+			return nil
+		}
+		return fmt.Errorf("value %d overflows %s", u, dst.Type())
+	case rv.CanFloat() && dst.CanFloat():
+		if dst.OverflowFloat(rv.Float()) {
+			return fmt.Errorf("value %v overflows %s", v, dst.Type())
+		}
+		dst.Set(rv.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot assign %T to a field of type %s", v, dst.Type())
+}
+
+// isGoNumber reports whether v holds an integer or floating-point kind.
+func isGoNumber(v reflect.Value) bool {
+	return v.CanInt() || v.CanUint() || v.CanFloat()
+}
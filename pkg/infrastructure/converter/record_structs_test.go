@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordToStructs(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+		{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "unmapped", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	b := array.NewRecordBuilder(alloc, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	b.Field(1).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	b.Field(2).(*array.Float32Builder).AppendValues([]float32{1.5, 0}, []bool{true, false})
+	tags := b.Field(3).(*array.ListBuilder)
+	tags.Append(true)
+	tags.ValueBuilder().(*array.StringBuilder).AppendValues([]string{"x", "y"}, nil)
+	tags.AppendNull()
+	b.Field(4).(*array.TimestampBuilder).AppendValues(
+		[]arrow.Timestamp{arrow.Timestamp(ts.UnixMicro()), arrow.Timestamp(ts.UnixMicro())}, nil)
+	b.Field(5).(*array.Int64Builder).AppendValues([]int64{7, 8}, nil)
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	type MyRow struct {
+		ID      int64
+		Name    *string
+		Score   float64
+		Tags    []string
+		Created time.Time `db:"created_at"`
+		Note    string
+	}
+
+	rows, err := RecordToStructs[MyRow](rec)
+	require.NoError(t, err)
+	name := "a"
+	assert.Equal(t, []MyRow{
+		{ID: 1, Name: &name, Score: 1.5, Tags: []string{"x", "y"}, Created: ts},
+		{ID: 2, Created: ts},
+	}, rows)
+
+	t.Run("conversions", func(t *testing.T) {
+		type unsigned struct{ Unmapped uint8 }
+		rows, err := RecordToStructs[unsigned](rec)
+		require.NoError(t, err)
+		assert.Equal(t, []unsigned{{7}, {8}}, rows)
+
+		type narrow struct{ Unmapped int8 }
+		big := array.NewRecordBuilder(alloc, arrow.NewSchema([]arrow.Field{{Name: "unmapped", Type: arrow.PrimitiveTypes.Int64}}, nil))
+		defer big.Release()
+		big.Field(0).(*array.Int64Builder).Append(300)
+		bigRec := big.NewRecord()
+		defer bigRec.Release()
+		_, err = RecordToStructs[narrow](bigRec)
+		assert.ErrorContains(t, err, "value 300 overflows int8")
+
+		type wrong struct{ Name int }
+		_, err = RecordToStructs[wrong](rec)
+		assert.ErrorContains(t, err, `cannot convert column "name" at row 0`)
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := RecordToStructs[int](rec)
+		assert.Error(t, err)
+	})
+}
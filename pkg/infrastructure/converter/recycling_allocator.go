@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+const (
+	// minRecycledClass and maxRecycledClass bound the power-of-two buffer
+	// sizes, 64 B to 4 MiB, that are cached for reuse.
+	minRecycledClass = 6
+	maxRecycledClass = 22
+	// maxRecycledPerClass caps the number of cached buffers of each size.
+	maxRecycledPerClass = 16
+)
+
+// recyclingAllocator wraps an allocator and caches freed buffers of
+// power-of-two sizes, handing them back zeroed on later allocations. Buffers
+// of other sizes go straight to the wrapped allocator.
+type recyclingAllocator struct {
+	mem    memory.Allocator
+	mu     sync.Mutex
+	free   [maxRecycledClass + 1][][]byte
+	closed bool
+}
+
+// newRecyclingAllocator returns a recycling wrapper around mem.
+func newRecyclingAllocator(mem memory.Allocator) *recyclingAllocator {
+	return &recyclingAllocator{mem: mem}
+}
+
+// sizeClass returns the power-of-two class that holds size bytes, or -1 if
+// such buffers are not recycled.
+func sizeClass(size int) int {
+	if size <= 0 {
+		return -1
+	}
+	class := bits.Len(uint(size - 1))
+	if class < minRecycledClass {
+		class = minRecycledClass
+	}
+	if class > maxRecycledClass {
+		return -1
+	}
+	return class
+}
+
+// Allocate returns a zeroed buffer of size bytes.
+func (a *recyclingAllocator) Allocate(size int) []byte {
+	class := sizeClass(size)
+	if class < 0 {
+		return a.mem.Allocate(size)
+	}
+
+	a.mu.Lock()
+	if n := len(a.free[class]); n > 0 {
+		buf := a.free[class][n-1]
+		a.free[class] = a.free[class][:n-1]
+		a.mu.Unlock()
+		// Arrow relies on fresh buffers being zeroed, e.g. for validity
+		// bitmaps and offsets, so clear whatever the last owner left.
+		clear(buf)
+		return buf[:size]
+	}
+	a.mu.Unlock()
+
+	return a.mem.Allocate(1 << class)[:size]
+}
+
+// Reallocate resizes b to size bytes, zeroing any newly exposed bytes.
+func (a *recyclingAllocator) Reallocate(size int, b []byte) []byte {
+	if size <= cap(b) && sizeClass(cap(b)) == sizeClass(size) && sizeClass(size) >= 0 {
+		old := len(b)
+		b = b[:size]
+		if size > old {
+			clear(b[old:])
+		}
+		return b
+	}
+
+	out := a.Allocate(size)
+	copy(out, b)
+	a.Free(b)
+	return out
+}
+
+// Free caches b for reuse if it is a recyclable size, otherwise it returns
+// b to the wrapped allocator.
+func (a *recyclingAllocator) Free(b []byte) {
+	b = b[:cap(b)]
+	class := sizeClass(len(b))
+	if class < 0 || len(b) != 1<<class {
+		a.mem.Free(b)
+		return
+	}
+
+	a.mu.Lock()
+	if !a.closed && len(a.free[class]) < maxRecycledPerClass {
+		a.free[class] = append(a.free[class], b)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	a.mem.Free(b)
+}
+
+// release returns all cached buffers to the wrapped allocator. Buffers freed
+// afterwards, e.g. by records that outlive the reader, are not cached.
+func (a *recyclingAllocator) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	for class := range a.free {
+		for _, buf := range a.free[class] {
+			a.mem.Free(buf)
+		}
+		a.free[class] = nil
+	}
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecyclingAllocator(t *testing.T) {
+	t.Run("reused buffers are zeroed", func(t *testing.T) {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer mem.AssertSize(t, 0)
+		alloc := newRecyclingAllocator(mem)
+		defer alloc.release()
+
+		buf := alloc.Allocate(100)
+		require.Len(t, buf, 100)
+		for i := range buf {
+			buf[i] = 0xff
+		}
+		alloc.Free(buf)
+
+		again := alloc.Allocate(120)
+		require.Len(t, again, 120)
+		assert.Same(t, &buf[0], &again[0], "expected the freed buffer to be reused")
+		assert.Equal(t, make([]byte, 120), again)
+		alloc.Free(again)
+	})
+
+	t.Run("reallocate zeroes grown bytes", func(t *testing.T) {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer mem.AssertSize(t, 0)
+		alloc := newRecyclingAllocator(mem)
+		defer alloc.release()
+
+		buf := alloc.Allocate(100)
+		for i := range buf {
+			buf[i] = 0xff
+		}
+		buf = alloc.Reallocate(10, buf)
+		buf = alloc.Reallocate(80, buf)
+		assert.Equal(t, []byte{0xff, 0xff}, buf[8:10])
+		assert.Equal(t, make([]byte, 70), buf[10:])
+
+		buf = alloc.Reallocate(1000, buf)
+		require.Len(t, buf, 1000)
+		assert.Equal(t, byte(0xff), buf[0])
+		alloc.Free(buf)
+	})
+
+	t.Run("odd sizes pass through", func(t *testing.T) {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer mem.AssertSize(t, 0)
+		alloc := newRecyclingAllocator(mem)
+		defer alloc.release()
+
+		buf := alloc.Allocate(8 << 20)
+		assert.Equal(t, 8<<20, mem.CurrentAlloc())
+		alloc.Free(buf)
+		assert.Equal(t, 0, mem.CurrentAlloc())
+	})
+
+	t.Run("frees after release are not cached", func(t *testing.T) {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer mem.AssertSize(t, 0)
+		alloc := newRecyclingAllocator(mem)
+
+		buf := alloc.Allocate(64)
+		alloc.release()
+		alloc.Free(buf)
+	})
+}
+
+func TestBatchReaderRecyclingAllocator(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rows := queryRows(t, "SELECT i, CASE WHEN i % 3 = 0 THEN NULL ELSE 'v' || i END AS s FROM range(1000) t(i) ORDER BY i")
+	reader, err := NewBatchReader(mem, rows, logger, WithRecyclingAllocator())
+	require.NoError(t, err)
+	reader.SetBatchSize(100)
+
+	var next int64
+	for reader.Next() {
+		rec := reader.Record()
+		ids := rec.Column(0).(*array.Int64)
+		strs := rec.Column(1).(*array.String)
+		for i := 0; i < ids.Len(); i++ {
+			require.Equal(t, next, ids.Value(i))
+			if next%3 == 0 {
+				require.True(t, strs.IsNull(i), "row %d", next)
+			} else {
+				require.True(t, strs.IsValid(i), "row %d", next)
+			}
+			next++
+		}
+		rec.Release()
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, int64(1000), next)
+	reader.Release()
+}
+
+func BenchmarkBatchReaderRecycling(b *testing.B) {
+	const query = "SELECT i, 'value ' || i AS s FROM range(100000) t(i)"
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "recycling", opts: []Option{WithRecyclingAllocator()}},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(b, query), zerolog.Nop(), tt.opts...)
+				require.NoError(b, err)
+				for reader.Next() {
+					reader.Record().Release()
+				}
+				require.NoError(b, reader.Err())
+				reader.Release()
+			}
+		})
+	}
+}
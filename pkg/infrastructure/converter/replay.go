@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// NewReplayReader returns a BatchReader handing out records in order, so
+// that consumers of BatchReader can be tested without a database. The
+// reader retains the records until it is released, so it can be rewound
+// with Rewind; the caller keeps its own references. Records must have the
+// given schema; a mismatch is reported by Err.
+func NewReplayReader(schema *arrow.Schema, records []arrow.Record) *BatchReader {
+	held := slices.Clone(records)
+	for _, rec := range held {
+		rec.Retain()
+	}
+	pos := 0
+	src := recordSource{
+		next: func() (arrow.Record, error) {
+			if pos == len(held) {
+				return nil, nil
+			}
+			// The reader releases its current record when it moves on
+			rec := held[pos]
+			rec.Retain()
+			pos++
+			return rec, nil
+		},
+		close: func() {
+			for _, rec := range held {
+				rec.Release()
+			}
+			held = nil
+		},
+		rewind: func() error {
+			pos = 0
+			return nil
+		},
+	}
+	r := newDerivedReader(schema, memory.DefaultAllocator, zerolog.Nop(), src)
+
+	for i, rec := range records {
+		if !schema.Equal(rec.Schema()) {
+			r.err = errors.New(errors.CodeInvalidRequest, fmt.Sprintf("replayed record %d does not match the schema", i))
+			break
+		}
+	}
+	return r
+}
@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+	"github.com/TFMV/porter/pkg/infrastructure/export"
+)
+
+func TestReplayReader(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	record := func(alloc memory.Allocator, ids []int64, names []string, valid []bool) arrow.Record {
+		b := array.NewRecordBuilder(alloc, schema)
+		defer b.Release()
+		b.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+		b.Field(1).(*array.StringBuilder).AppendValues(names, valid)
+		return b.NewRecord()
+	}
+	// replay returns the records replayed by each test, built with alloc,
+	// and a function releasing the test's references.
+	replay := func(alloc memory.Allocator) ([]arrow.Record, func()) {
+		records := []arrow.Record{
+			record(alloc, []int64{1, 2}, []string{"ant", ""}, []bool{true, false}),
+			record(alloc, []int64{3}, []string{"cat"}, nil),
+		}
+		return records, func() {
+			for _, rec := range records {
+				rec.Release()
+			}
+		}
+	}
+
+	t.Run("export", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+		records, release := replay(alloc)
+		defer release()
+		reader := NewReplayReader(schema, records)
+		defer reader.Release()
+
+		var buf bytes.Buffer
+		n, err := export.ExportCSV(context.Background(), reader, &buf, export.CSVOptions{NullValue: "NULL"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, "id,name\n1,ant\n2,NULL\n3,cat\n", buf.String())
+	})
+
+	t.Run("released early", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+		records, release := replay(alloc)
+		defer release()
+
+		reader := NewReplayReader(schema, records)
+		require.True(t, reader.Next())
+		rec := reader.Record()
+		assert.Equal(t, int64(2), rec.NumRows())
+		rec.Release()
+		// The unread record is released with the reader
+		reader.Release()
+	})
+
+	t.Run("rewind", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+		records, release := replay(alloc)
+		defer release()
+
+		reader := NewReplayReader(schema, records)
+		defer reader.Release()
+
+		// read returns the CSV of the records left in reader.
+		read := func() string {
+			var buf bytes.Buffer
+			for reader.Next() {
+				rec := reader.Record()
+				rows, err := RecordToRows(rec)
+				require.NoError(t, err)
+				for _, row := range rows {
+					fmt.Fprintln(&buf, row...)
+				}
+				rec.Release()
+			}
+			require.NoError(t, reader.Err())
+			return buf.String()
+		}
+
+		first := read()
+		assert.Equal(t, "1 ant\n2 <nil>\n3 cat\n", first)
+		require.NoError(t, reader.Rewind())
+		assert.Equal(t, first, read())
+
+		// Rewinding part way through restarts too
+		require.NoError(t, reader.Rewind())
+		require.True(t, reader.Next())
+		require.NoError(t, reader.Rewind())
+		assert.Equal(t, first, read())
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+		records, release := replay(alloc)
+		defer release()
+
+		other := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, nil)
+		reader := NewReplayReader(other, records)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.Equal(t, errors.CodeInvalidRequest, errors.GetCode(reader.Err()))
+		assert.Equal(t, errors.CodeFailedPrecondition, errors.GetCode(reader.Rewind()))
+	})
+}
+
+func TestBatchReaderRewindRows(t *testing.T) {
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS a"), zerolog.Nop())
+	require.NoError(t, err)
+	defer reader.Release()
+
+	err = reader.Rewind()
+	require.Error(t, err)
+	assert.Equal(t, errors.CodeFailedPrecondition, errors.GetCode(err))
+}
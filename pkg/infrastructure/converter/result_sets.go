@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// resultSignature describes the columns of a result set by name and
+// database type, for WithAllResultSets to check that later result sets
+// match the first.
+func resultSignature(cols []*sql.ColumnType) []string {
+	sig := make([]string, len(cols))
+	for i, col := range cols {
+		sig[i] = col.Name() + " " + strings.ToUpper(col.DatabaseTypeName())
+	}
+	return sig
+}
+
+// nextRow advances to the next row, moving on to the next result set
+// with WithAllResultSets when the current one is exhausted.
+func (r *BatchReader) nextRow() bool {
+	for !r.rows.Next() {
+		if !r.nextResultSet() {
+			return false
+		}
+	}
+	return true
+}
+
+// nextResultSet advances to the next result set once the current one is
+// exhausted, with WithAllResultSets. It reports false when there is none
+// or it does not match the first; the mismatch is then returned by
+// rowsErr.
+func (r *BatchReader) nextResultSet() bool {
+	if !r.opts.allResultSets || r.rows.Err() != nil || !r.rows.NextResultSet() {
+		return false
+	}
+	r.resultSet++
+
+	cols, err := r.rows.ColumnTypes()
+	if err != nil {
+		r.resultErr = errors.Wrapf(err, errors.CodeInternal, "failed to get column types of result set %d", r.resultSet)
+		return false
+	}
+	sig := resultSignature(cols)
+	if len(sig) != len(r.resultSig) {
+		r.resultErr = errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("result set %d has %d columns, the first has %d", r.resultSet, len(sig), len(r.resultSig)))
+		return false
+	}
+	for i := range sig {
+		if sig[i] != r.resultSig[i] {
+			r.resultErr = errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("column %d of result set %d is %q, the first result set has %q", i, r.resultSet, sig[i], r.resultSig[i]))
+			return false
+		}
+	}
+	r.logger.Debug().Int("result_set", r.resultSet).Msg("BatchReader.Next: advanced to the next result set")
+	return true
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderAllResultSets(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// Two statements, as in "SELECT ... FROM a; SELECT ... FROM b", with
+	// an empty result set in between.
+	resultSet := func(typeName string, values ...driver.Value) *fakeResult {
+		result := &fakeResult{
+			columns:   []string{"id"},
+			typeNames: []string{typeName},
+			scanTypes: []reflect.Type{reflect.TypeOf(int64(0))},
+			notNull:   []bool{true},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v})
+		}
+		return result
+	}
+	open := func(first *fakeResult) *sql.Rows {
+		db := sql.OpenDB(fakeConnector{first})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT id FROM a; SELECT id FROM b")
+		require.NoError(t, err)
+		return rows
+	}
+	read := func(reader *BatchReader) []string {
+		var batches []string
+		for reader.Next() {
+			rec := reader.Record()
+			batches = append(batches, rec.Column(0).String())
+			rec.Release()
+		}
+		return batches
+	}
+
+	t.Run("all", func(t *testing.T) {
+		first := resultSet("BIGINT", int64(1), int64(2))
+		first.following = resultSet("BIGINT")
+		first.following.following = resultSet("BIGINT", int64(3), int64(4), int64(5))
+
+		for _, opts := range [][]Option{
+			{WithAllResultSets()},
+			{WithAllResultSets(), WithBatchSize(2)},
+		} {
+			reader, err := NewBatchReader(memory.NewGoAllocator(), open(first), logger, opts...)
+			require.NoError(t, err)
+			batches := read(reader)
+			require.NoError(t, reader.Err())
+			assert.Equal(t, arrow.PrimitiveTypes.Int64, reader.Schema().Field(0).Type)
+			if len(opts) == 1 {
+				assert.Equal(t, []string{"[1 2 3 4 5]"}, batches)
+			} else {
+				assert.Equal(t, []string{"[1 2]", "[3 4]", "[5]"}, batches)
+			}
+			reader.Release()
+		}
+	})
+
+	t.Run("skip rows", func(t *testing.T) {
+		first := resultSet("BIGINT", int64(1), int64(2))
+		first.following = resultSet("BIGINT")
+		first.following.following = resultSet("BIGINT", int64(3), int64(4), int64(5))
+
+		// Skipping continues past the end of the first result set
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(first), logger, WithAllResultSets(), WithSkipRows(3))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, []string{"[4 5]"}, read(reader))
+		require.NoError(t, reader.Err())
+	})
+
+	t.Run("first only by default", func(t *testing.T) {
+		first := resultSet("BIGINT", int64(1))
+		first.following = resultSet("BIGINT", int64(2))
+
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(first), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, []string{"[1]"}, read(reader))
+		require.NoError(t, reader.Err())
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		first := resultSet("BIGINT", int64(1))
+		first.following = resultSet("VARCHAR", "x")
+
+		schema := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, nil)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, open(first), logger,
+			WithAllResultSets(), WithBatchSize(1))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, []string{"[1]"}, read(reader))
+		require.Error(t, reader.Err())
+		assert.Contains(t, reader.Err().Error(), `column 0 of result set 1 is "id VARCHAR", the first result set has "id BIGINT"`)
+	})
+}
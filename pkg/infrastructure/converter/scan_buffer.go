@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// maxPooledScanBuffer is the largest buffer returned to scanBufferPool, so
+// that one huge value does not pin its memory for the life of the process.
+const maxPooledScanBuffer = 64 << 10
+
+// scanBufferPool holds the scan destinations used by WithBufferPool.
+var scanBufferPool = sync.Pool{New: func() any { return new(scanBuffer) }}
+
+// scanBuffer is a reusable scan destination for string and binary columns.
+// Each scanned value is copied into buf, which the reader owns, so no value
+// aliases memory held by the driver.
+type scanBuffer struct {
+	buf   []byte
+	valid bool
+}
+
+// Scan implements sql.Scanner.
+func (b *scanBuffer) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		b.valid = false
+	case string:
+		b.buf, b.valid = append(b.buf[:0], v...), true
+	case []byte:
+		b.buf, b.valid = append(b.buf[:0], v...), true
+	default:
+		return fmt.Errorf("cannot scan %T into a string or binary column", src)
+	}
+	return nil
+}
+
+// usePooledBuffers replaces the destinations of string and binary columns in
+// rowDest with buffers taken from scanBufferPool and returns them.
+func usePooledBuffers(rowDest []interface{}, fields []arrow.Field) []*scanBuffer {
+	var bufs []*scanBuffer
+	for i, f := range fields {
+		if id := f.Type.ID(); id != arrow.STRING && id != arrow.BINARY {
+			continue
+		}
+		b := scanBufferPool.Get().(*scanBuffer)
+		rowDest[i] = b
+		bufs = append(bufs, b)
+	}
+	return bufs
+}
+
+// releaseScanBuffers returns bufs to scanBufferPool.
+func releaseScanBuffers(bufs []*scanBuffer) {
+	for _, b := range bufs {
+		if cap(b.buf) > maxPooledScanBuffer {
+			continue
+		}
+		b.buf, b.valid = b.buf[:0], false
+		scanBufferPool.Put(b)
+	}
+}
+
+// appendBytesValue appends the bytes of a string or binary value, copying
+// them into the builder.
+func appendBytesValue(fb array.Builder, v []byte) error {
+	switch b := fb.(type) {
+	case *array.StringBuilder:
+		b.BinaryBuilder.Append(v)
+	case *array.LargeStringBuilder:
+		b.BinaryBuilder.Append(v)
+	case *array.BinaryBuilder:
+		b.Append(v)
+	case *array.StringViewBuilder:
+		b.BinaryViewBuilder.Append(v)
+	case *array.BinaryViewBuilder:
+		b.Append(v)
+	case *array.FixedSizeBinaryBuilder:
+		if width := b.Type().(*arrow.FixedSizeBinaryType).ByteWidth; len(v) != width {
+			return errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("value of %d bytes does not fit %s", len(v), b.Type()))
+		}
+		b.Append(v)
+	case *array.BinaryDictionaryBuilder:
+		return b.Append(v)
+	case storageBuilder:
+		// Binary-backed extension types such as UUID
+		return appendBytesValue(b.StorageBuilder(), v)
+	default:
+		return appendStringValue(fb, string(v))
+	}
+	return nil
+}
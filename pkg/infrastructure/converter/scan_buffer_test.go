@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderBufferPool(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	// Values shrink and grow from row to row, so a buffer shared with the
+	// builder would show up as corrupted earlier values.
+	const query = `SELECT CASE WHEN i % 5 = 0 THEN NULL ELSE repeat(chr(97 + (i % 26)::INTEGER), (i * 7) % 50) END AS s,
+		CASE WHEN i % 7 = 0 THEN NULL ELSE encode(repeat('x', (i * 3) % 20) || i::VARCHAR) END AS b
+		FROM range(300) t(i) ORDER BY i`
+
+	read := func(opts ...Option) []arrow.Record {
+		reader, err := NewBatchReader(alloc, queryRows(t, query), logger, opts...)
+		require.NoError(t, err)
+		defer reader.Release()
+		reader.SetBatchSize(64)
+
+		var recs []arrow.Record
+		for reader.Next() {
+			recs = append(recs, reader.Record())
+		}
+		require.NoError(t, reader.Err())
+		return recs
+	}
+
+	want := read()
+	got := read(WithBufferPool())
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, array.RecordEqual(want[i], got[i]), "batch %d:\nwant %v\ngot  %v", i, want[i], got[i])
+		assert.Equal(t, arrow.BINARY, got[i].Column(1).DataType().ID())
+		want[i].Release()
+		got[i].Release()
+	}
+}
+
+func BenchmarkBatchReaderBufferPool(b *testing.B) {
+	const query = "SELECT repeat('porter', 1 + i % 20) AS s, encode(repeat('x', 1 + i % 40)) AS b FROM range(100000) t(i)"
+
+	for _, pooled := range []bool{false, true} {
+		name := "default"
+		var opts []Option
+		if pooled {
+			name = "pooled"
+			opts = append(opts, WithBufferPool())
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(b, query), zerolog.Nop(), opts...)
+				require.NoError(b, err)
+				for reader.Next() {
+					reader.Record().Release()
+				}
+				require.NoError(b, reader.Err())
+				reader.Release()
+			}
+		})
+	}
+}
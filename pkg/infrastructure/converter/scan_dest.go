@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// scanDest is a caller-supplied scan destination registered with
+// WithScanDest.
+type scanDest struct {
+	factory func() any
+	append  func(any, array.Builder) error
+}
+
+// resolveScanDests matches the configured scan destinations to fields,
+// returning one entry per field, or nil if none are configured.
+func resolveScanDests(fields []arrow.Field, dests map[string]scanDest) ([]*scanDest, error) {
+	if len(dests) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*scanDest, len(fields))
+	for name, d := range dests {
+		idx := -1
+		for i := range fields {
+			if fields[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot set scan destination of unknown column %q", name))
+		}
+		if d.factory == nil || d.append == nil {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("scan destination of column %q needs a factory and an append function", name))
+		}
+		out[idx] = &d
+	}
+	return out, nil
+}
+
+// useScanDests replaces the destinations in rowDest with those created by
+// the configured factories.
+func useScanDests(rowDest []interface{}, dests []*scanDest) {
+	for i, d := range dests {
+		if d != nil {
+			rowDest[i] = d.factory()
+		}
+	}
+}
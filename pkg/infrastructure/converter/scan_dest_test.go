@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// level is an enum scanned from its text label.
+type level struct {
+	value int8
+	valid bool
+}
+
+func (l *level) Scan(src any) error {
+	l.valid = src != nil
+	switch src {
+	case nil:
+	case "low":
+		l.value = 0
+	case "medium":
+		l.value = 1
+	case "high":
+		l.value = 2
+	default:
+		return fmt.Errorf("unknown level %v", src)
+	}
+	return nil
+}
+
+func appendLevel(v any, b array.Builder) error {
+	l := v.(*level)
+	if !l.valid {
+		b.AppendNull()
+		return nil
+	}
+	b.(*array.Int8Builder).Append(l.value)
+	return nil
+}
+
+func TestBatchReaderScanDest(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "level", Type: arrow.PrimitiveTypes.Int8, Nullable: true},
+	}, nil)
+	rows := queryRows(t, "SELECT * FROM (VALUES (1, 'high'), (2, NULL), (3, 'low'), (4, 'medium')) t(id, level)")
+	reader, err := NewBatchReaderWithSchema(mem, schema, rows, logger,
+		WithScanDest("level", func() any { return new(level) }, appendLevel))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	assert.Equal(t, []int64{1, 2, 3, 4}, rec.Column(0).(*array.Int64).Int64Values())
+	levels := rec.Column(1).(*array.Int8)
+	assert.Equal(t, []int8{2, 0, 0, 1}, levels.Int8Values())
+	assert.True(t, levels.IsNull(1))
+	rec.Release()
+	assert.False(t, reader.Next())
+	require.NoError(t, reader.Err())
+}
+
+func TestBatchReaderScanDestErrors(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	factory := func() any { return new(level) }
+
+	t.Run("unknown column", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 'low' AS level")
+		_, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithScanDest("missing", factory, appendLevel))
+		assert.ErrorContains(t, err, `unknown column "missing"`)
+	})
+
+	t.Run("scan failure", func(t *testing.T) {
+		rows := queryRows(t, "SELECT 'extreme' AS level")
+		reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger, WithScanDest("level", factory, appendLevel))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "unknown level extreme")
+	})
+}
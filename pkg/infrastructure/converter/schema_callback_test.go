@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+func TestBatchReaderSchemaCallback(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// expect accepts only schemas with an integer id column.
+	errUnexpected := errors.New(errors.CodeInvalidRequest, "unexpected schema")
+	var seen *arrow.Schema
+	expect := func(schema *arrow.Schema) error {
+		seen = schema
+		idx := schema.FieldIndices("id")
+		if len(idx) != 1 || schema.Field(idx[0]).Type.ID() != arrow.INT32 {
+			return errUnexpected
+		}
+		return nil
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 1 AS id, 'a' AS name"), logger,
+			WithSchemaCallback(expect), WithColumnRename(map[string]string{"name": "label"}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Same(t, reader.Schema(), seen)
+		assert.Equal(t, "label", seen.Field(1).Name)
+		require.True(t, reader.Next())
+		reader.Record().Release()
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		before := OpenReaders()
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, "SELECT 'x' AS id"), logger,
+			WithSchemaCallback(expect))
+		require.Error(t, err)
+		assert.Nil(t, reader)
+		assert.ErrorIs(t, err, errUnexpected)
+		assert.Equal(t, errors.CodeFailedPrecondition, errors.GetCode(err))
+		assert.Equal(t, before, OpenReaders())
+	})
+}
@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// evolvedType returns the type an integer column of type dt is widened to
+// under WithSchemaEvolution, or nil if it cannot be widened.
+func evolvedType(dt arrow.DataType) arrow.DataType {
+	switch dt.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32:
+		return arrow.PrimitiveTypes.Int64
+	case arrow.UINT8, arrow.UINT16, arrow.UINT32:
+		return arrow.PrimitiveTypes.Uint64
+	}
+	return nil
+}
+
+// canEvolve reports whether the value scanned for colIdx, which failed to
+// append, fits the column once widened.
+func (r *BatchReader) canEvolve(colIdx int) bool {
+	if !r.opts.schemaEvolution || colIdx >= len(r.rowDest) {
+		return false
+	}
+	if r.casts != nil && r.casts[colIdx] != nil {
+		return false
+	}
+	wider := evolvedType(r.schema.Field(colIdx).Type)
+	v, ok := r.rowDest[colIdx].(*interface{})
+	if wider == nil || !ok || *v == nil {
+		return false
+	}
+
+	b := array.NewBuilder(r.allocator, wider)
+	defer b.Release()
+	return r.appendDynamicValue(b, *v) == nil
+}
+
+// evolveColumn widens column colIdx and replaces the builder, dropping
+// anything appended to it. Records built from then on carry the new schema.
+func (r *BatchReader) evolveColumn(colIdx int) error {
+	fields := r.schema.Fields()
+	from := fields[colIdx].Type
+	fields[colIdx].Type = evolvedType(from)
+	md := r.schema.Metadata()
+	r.schema = arrow.NewSchema(fields, &md)
+
+	r.builder.Release()
+	r.builder = r.newRecordBuilder()
+	for _, bl := range r.lists {
+		if bl != nil {
+			bl.reset()
+		}
+	}
+	if r.dedup != nil {
+		r.dedup = newStringDedup(r.schema)
+	}
+	r.logger.Debug().
+		Str("column", fields[colIdx].Name).
+		Stringer("from", from).
+		Stringer("to", fields[colIdx].Type).
+		Msg("BatchReader.Next: widened column, schema evolved")
+
+	if r.opts.schemaCallback != nil {
+		if err := r.opts.schemaCallback(r.Schema()); err != nil {
+			return errors.Wrap(err, errors.CodeFailedPrecondition, "schema rejected by callback")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderSchemaEvolution(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// The driver reports INTEGER, but a later value needs 64 bits, as a
+	// UNION ALL with an implicit cast could produce.
+	open := func(values ...driver.Value) *sql.Rows {
+		result := &fakeResult{
+			columns:   []string{"id", "name"},
+			typeNames: []string{"INTEGER", "VARCHAR"},
+			scanTypes: []reflect.Type{reflect.TypeOf(int32(0)), reflect.TypeOf("")},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v, "row"})
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT id, name")
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("widens mid-stream", func(t *testing.T) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		var schemas []*arrow.Schema
+		reader, err := NewBatchReader(alloc, open(int64(1), int64(2), int64(3_000_000_000), int64(4), nil), logger,
+			WithSchemaEvolution(),
+			WithSchemaCallback(func(s *arrow.Schema) error {
+				schemas = append(schemas, s)
+				return nil
+			}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, rec.Schema().Field(0).Type)
+		assert.Equal(t, "[1 2]", rec.Column(0).String())
+		rec.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec = reader.Record()
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, rec.Schema().Field(0).Type)
+		assert.Equal(t, "[3000000000 4 (null)]", rec.Column(0).String())
+		assert.Equal(t, `["row" "row" "row"]`, rec.Column(1).String())
+		rec.Release()
+
+		assert.False(t, reader.Next())
+		require.NoError(t, reader.Err())
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, reader.Schema().Field(0).Type)
+
+		require.Len(t, schemas, 2)
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, schemas[0].Field(0).Type)
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, schemas[1].Field(0).Type)
+	})
+
+	t.Run("first row", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(int64(-3_000_000_000), int64(1)), logger, WithSchemaEvolution())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, rec.Schema().Field(0).Type)
+		assert.Equal(t, "[-3000000000 1]", rec.Column(0).String())
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(int64(1), "x"), logger, WithSchemaEvolution())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, rec.Schema().Field(0).Type)
+		rec.Release()
+		assert.False(t, reader.Next())
+		assert.Error(t, reader.Err())
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, reader.Schema().Field(0).Type)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(int64(1), int64(3_000_000_000)), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		for reader.Next() {
+			reader.Record().Release()
+		}
+		assert.Error(t, reader.Err())
+	})
+}
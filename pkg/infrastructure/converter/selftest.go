@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/marcboeker/go-duckdb/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// selfTestCase is a value as a driver returns it, appended to a column of
+// type dt, and the text Arrow must render it as.
+type selfTestCase struct {
+	dt    arrow.DataType
+	value interface{}
+	want  string
+}
+
+// selfTestCases covers every Arrow type the reader can build.
+func selfTestCases() []selfTestCase {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 1, 12, 30, 15, 250000000, time.UTC)
+	clock := time.Date(1, 1, 1, 12, 30, 15, 250000000, time.UTC)
+	decimal := func(width, scale uint8) duckdb.Decimal {
+		return duckdb.Decimal{Width: width, Scale: scale, Value: big.NewInt(-125)}
+	}
+
+	return []selfTestCase{
+		{arrow.FixedWidthTypes.Boolean, true, "true"},
+		{arrow.PrimitiveTypes.Int8, int8(math.MinInt8), "-128"},
+		{arrow.PrimitiveTypes.Int16, int16(math.MinInt16), "-32768"},
+		{arrow.PrimitiveTypes.Int32, int32(math.MinInt32), "-2147483648"},
+		{arrow.PrimitiveTypes.Int64, int64(math.MinInt64), "-9223372036854775808"},
+		{arrow.PrimitiveTypes.Uint8, uint8(math.MaxUint8), "255"},
+		{arrow.PrimitiveTypes.Uint16, uint16(math.MaxUint16), "65535"},
+		{arrow.PrimitiveTypes.Uint32, uint32(math.MaxUint32), "4294967295"},
+		{arrow.PrimitiveTypes.Uint64, uint64(math.MaxUint64), "18446744073709551615"},
+		{arrow.FixedWidthTypes.Float16, float32(1.5), "1.5"},
+		{arrow.PrimitiveTypes.Float32, float32(1.5), "1.5"},
+		{arrow.PrimitiveTypes.Float64, -2.5, "-2.5"},
+		{arrow.BinaryTypes.String, "snow ☃", "snow ☃"},
+		{arrow.BinaryTypes.LargeString, "snow ☃", "snow ☃"},
+		{arrow.BinaryTypes.StringView, "a string longer than twelve bytes", "a string longer than twelve bytes"},
+		{arrow.BinaryTypes.Binary, []byte{0, 1}, "AAE="},
+		{arrow.BinaryTypes.LargeBinary, []byte{0, 1}, "AAE="},
+		{arrow.BinaryTypes.BinaryView, []byte{0, 1}, "AAE="},
+		{&arrow.FixedSizeBinaryType{ByteWidth: 2}, []byte{0, 1}, "AAE="},
+		{arrow.FixedWidthTypes.Date32, day, "2024-03-01"},
+		{arrow.FixedWidthTypes.Date64, day, "2024-03-01"},
+		{arrow.FixedWidthTypes.Time32s, clock, "12:30:15"},
+		{arrow.FixedWidthTypes.Time32ms, clock, "12:30:15.250"},
+		{arrow.FixedWidthTypes.Time64us, clock, "12:30:15.250000"},
+		{arrow.FixedWidthTypes.Time64ns, clock, "12:30:15.250000000"},
+		{arrow.FixedWidthTypes.Timestamp_us, at, "2024-03-01 12:30:15.25Z"},
+		{arrow.FixedWidthTypes.Duration_us, 1500 * time.Millisecond, "1500000us"},
+		{arrow.FixedWidthTypes.MonthInterval, duckdb.Interval{Months: 14}, "14"},
+		{arrow.FixedWidthTypes.DayTimeInterval, duckdb.Interval{Days: 2, Micros: 3000}, `{"days":2,"milliseconds":3}`},
+		{arrow.FixedWidthTypes.MonthDayNanoInterval, duckdb.Interval{Months: 1, Days: 2, Micros: 3}, `{"months":1,"days":2,"nanoseconds":3000}`},
+		{&arrow.Decimal32Type{Precision: 5, Scale: 2}, decimal(5, 2), "-1.25"},
+		{&arrow.Decimal64Type{Precision: 12, Scale: 2}, decimal(12, 2), "-1.25"},
+		{&arrow.Decimal128Type{Precision: 20, Scale: 2}, decimal(20, 2), "-1.25"},
+		{&arrow.Decimal256Type{Precision: 40, Scale: 2}, decimal(40, 2), "-1.25"},
+		{arrow.ListOf(arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.LargeListOf(arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Int32), []interface{}{int32(1), nil}, "[1,null]"},
+		{arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true}),
+			map[string]interface{}{"a": int32(1)}, `{"a":1}`},
+		{arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32), duckdb.Map{"k": int32(1)}, `[{"key":"k","value":1}]`},
+		{&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}, "s", "s"},
+		{extensions.NewUUIDType(), []byte("0123456789abcdef"), "30313233-3435-3637-3839-616263646566"},
+	}
+}
+
+// SelfTest checks that every Arrow type the reader builds round-trips:
+// for each, a value shaped as the driver returns it and a null are
+// appended through the reader's conversion, and the record built is read
+// back and compared. It needs no database, so that CI and release
+// validation can run it anywhere. All mismatches are reported in one
+// error with CodeInternal.
+func SelfTest(allocator memory.Allocator) error {
+	var failures []string
+	for _, tc := range selfTestCases() {
+		if err := selfTestType(allocator, tc); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", tc.dt, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(errors.CodeInternal, "converter self-test failed: "+strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// selfTestType round-trips the value of tc and a null through a column of
+// type tc.dt.
+func selfTestType(allocator memory.Allocator, tc selfTestCase) error {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: tc.dt, Nullable: true}}, nil)
+	r := &BatchReader{
+		schema:    schema,
+		builder:   array.NewRecordBuilder(allocator, schema),
+		allocator: allocator,
+		logger:    zerolog.Nop(),
+		column:    -1,
+	}
+	defer r.builder.Release()
+
+	for _, v := range []interface{}{tc.value, nil} {
+		if err := r.appendDynamicValue(r.builder.Field(0), v); err != nil {
+			return err
+		}
+	}
+	rec := r.builder.NewRecord()
+	defer rec.Release()
+
+	col := rec.Column(0)
+	switch {
+	case !arrow.TypeEqual(col.DataType(), tc.dt):
+		return fmt.Errorf("built a column of type %s", col.DataType())
+	case col.Len() != 2:
+		return fmt.Errorf("built %d values, want 2", col.Len())
+	case col.IsNull(0) || !col.IsNull(1):
+		return fmt.Errorf("got validity [%t %t], want [true false]", col.IsValid(0), col.IsValid(1))
+	case col.ValueStr(0) != tc.want:
+		return fmt.Errorf("read back %q, want %q", col.ValueStr(0), tc.want)
+	}
+	return nil
+}
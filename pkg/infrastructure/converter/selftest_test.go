@@ -0,0 +1,39 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	require.NoError(t, SelfTest(alloc))
+
+	// Null columns hold no value to check; unions, run-end encoding and
+	// list views are never built.
+	skipped := map[arrow.Type]bool{
+		arrow.NULL: true, arrow.SPARSE_UNION: true, arrow.DENSE_UNION: true,
+		arrow.RUN_END_ENCODED: true, arrow.LIST_VIEW: true, arrow.LARGE_LIST_VIEW: true,
+	}
+	covered := make(map[arrow.Type]bool)
+	for _, tc := range selfTestCases() {
+		covered[tc.dt.ID()] = true
+	}
+	for id := arrow.NULL; id <= arrow.DECIMAL64; id++ {
+		assert.True(t, covered[id] || skipped[id], "type %s is not covered", id)
+	}
+
+	t.Run("reports mismatches", func(t *testing.T) {
+		err := selfTestType(alloc, selfTestCase{arrow.PrimitiveTypes.Int32, int32(1), "2"})
+		assert.EqualError(t, err, `read back "1", want "2"`)
+
+		err = selfTestType(alloc, selfTestCase{arrow.PrimitiveTypes.Int32, "x", "x"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,46 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// SortedByKey is the Arrow schema metadata key listing, as a JSON array of
+// column names, the columns the rows are sorted by. It is added by
+// WithSortedBy.
+const SortedByKey = "duckdb:sorted_by"
+
+// withSortedBy returns schema with the SortedByKey metadata for columns,
+// each of which must be a field of schema. It returns schema unchanged if
+// columns is empty.
+func withSortedBy(schema *arrow.Schema, columns []string) (*arrow.Schema, error) {
+	if len(columns) == 0 {
+		return schema, nil
+	}
+	for _, name := range columns {
+		if !schema.HasField(name) {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("sort column %q is not in the result", name))
+		}
+	}
+
+	value, err := json.Marshal(columns)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to encode sort columns")
+	}
+	md := schema.Metadata()
+	keys, values := md.Keys(), md.Values()
+	if i := slices.Index(keys, SortedByKey); i >= 0 {
+		values = slices.Clone(values)
+		values[i] = string(value)
+	} else {
+		keys = append(slices.Clone(keys), SortedByKey)
+		values = append(slices.Clone(values), string(value))
+	}
+	md = arrow.NewMetadata(keys, values)
+	return arrow.NewSchema(schema.Fields(), &md), nil
+}
@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReaderSortedBy(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	const query = "SELECT i % 2 AS a, i AS b FROM range(4) t(i) ORDER BY a, b DESC"
+
+	sortedBy := func(schema *arrow.Schema) string {
+		t.Helper()
+		i := schema.Metadata().FindKey(SortedByKey)
+		require.GreaterOrEqual(t, i, 0, "no %s metadata", SortedByKey)
+		return schema.Metadata().Values()[i]
+	}
+
+	t.Run("inferred schema", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger,
+			WithColumnRename(map[string]string{"b": "id"}), WithSortedBy([]string{"a", "id"}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, `["a","id"]`, sortedBy(reader.Schema()))
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, `["a","id"]`, sortedBy(rec.Schema()))
+	})
+
+	t.Run("predefined schema", func(t *testing.T) {
+		md := arrow.NewMetadata([]string{"origin"}, []string{"test"})
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "b", Type: arrow.PrimitiveTypes.Int64},
+		}, &md)
+		reader, err := NewBatchReaderWithSchema(memory.NewGoAllocator(), schema, queryRows(t, query), logger,
+			WithSortedBy([]string{"a"}))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, `["a"]`, sortedBy(reader.Schema()))
+		assert.Equal(t, "test", reader.Schema().Metadata().Values()[0])
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, query), logger, WithSortedBy([]string{"c"}))
+		assert.ErrorContains(t, err, `sort column "c" is not in the result`)
+	})
+}
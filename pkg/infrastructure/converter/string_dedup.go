@@ -0,0 +1,113 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/bitutil"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// stringDedup turns the plain string columns of the records built by a
+// reader into string views in which repeated values share their bytes, for
+// WithStringDedup.
+type stringDedup struct {
+	schema *arrow.Schema // the reader's schema as seen by callers
+	cols   []int         // indices of the deduplicated columns
+}
+
+// newStringDedup returns the deduplication of the string columns of
+// schema, or nil if it has none.
+func newStringDedup(schema *arrow.Schema) *stringDedup {
+	fields := schema.Fields()
+	d := &stringDedup{}
+	for i, f := range fields {
+		if f.Type.ID() == arrow.STRING {
+			fields[i].Type = arrow.BinaryTypes.StringView
+			d.cols = append(d.cols, i)
+		}
+	}
+	if d.cols == nil {
+		return nil
+	}
+	md := schema.Metadata()
+	d.schema = arrow.NewSchema(fields, &md)
+	return d
+}
+
+// apply returns rec with its string columns deduplicated, releasing rec.
+func (d *stringDedup) apply(mem memory.Allocator, rec arrow.Record) arrow.Record {
+	defer rec.Release()
+
+	cols := make([]arrow.Array, rec.NumCols())
+	for i := range cols {
+		cols[i] = rec.Column(i)
+		cols[i].Retain()
+	}
+	for _, i := range d.cols {
+		view := dedupStrings(mem, cols[i].(*array.String))
+		cols[i].Release()
+		cols[i] = view
+	}
+	out := array.NewRecord(d.schema, cols, rec.NumRows())
+	for _, c := range cols {
+		c.Release()
+	}
+	return out
+}
+
+// dedupStrings copies arr to a string view array storing each distinct
+// value that is too long to be inlined in a view only once.
+func dedupStrings(mem memory.Allocator, arr *array.String) arrow.Array {
+	n := arr.Len()
+
+	views := memory.NewResizableBuffer(mem)
+	views.Resize(arrow.ViewHeaderTraits.BytesRequired(n))
+	headers := arrow.ViewHeaderTraits.CastFromBytes(views.Bytes())
+
+	var validity *memory.Buffer
+	if arr.NullN() > 0 {
+		validity = memory.NewResizableBuffer(mem)
+		validity.Resize(int(bitutil.BytesForBits(int64(n))))
+		memory.Set(validity.Bytes(), 0)
+	}
+
+	var (
+		data    []byte
+		offsets = make(map[string]int32)
+	)
+	for i := 0; i < n; i++ {
+		headers[i] = arrow.ViewHeader{}
+		if arr.IsNull(i) {
+			continue
+		}
+		if validity != nil {
+			bitutil.SetBit(validity.Bytes(), i)
+		}
+		v := arr.Value(i)
+		headers[i].SetString(v)
+		if headers[i].IsInline() {
+			continue
+		}
+		off, ok := offsets[v]
+		if !ok {
+			off = int32(len(data))
+			offsets[v] = off
+			data = append(data, v...)
+		}
+		headers[i].SetIndexOffset(0, off)
+	}
+
+	values := memory.NewResizableBuffer(mem)
+	values.Resize(len(data))
+	copy(values.Bytes(), data)
+
+	out := array.NewData(arrow.BinaryTypes.StringView, n,
+		[]*memory.Buffer{validity, views, values}, nil, arr.NullN(), 0)
+	defer out.Release()
+	for _, b := range []*memory.Buffer{validity, views, values} {
+		if b != nil {
+			b.Release()
+		}
+	}
+	return array.MakeFromData(out)
+}
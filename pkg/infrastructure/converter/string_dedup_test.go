@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repetitiveStrings has a string column cycling through a few long values,
+// with nulls and short values mixed in.
+const repetitiveStrings = `SELECT i AS id,
+	CASE WHEN i % 7 = 0 THEN NULL WHEN i % 5 = 0 THEN 'short' ELSE 'customer segment ' || (i % 3) END AS segment
+	FROM range(1000) t(i)`
+
+func TestBatchReaderStringDedup(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	// read returns the rows of each record as strings, and the records'
+	// total buffer size.
+	read := func(t *testing.T, opts ...Option) ([][]string, int) {
+		alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer alloc.AssertSize(t, 0)
+
+		reader, err := NewBatchReader(alloc, queryRows(t, repetitiveStrings), logger, append(opts, WithBatchSize(256))...)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		var (
+			values [][]string
+			size   int
+		)
+		for reader.Next() {
+			rec := reader.Record()
+			require.True(t, reader.Schema().Equal(rec.Schema()))
+			col := rec.Column(1)
+			batch := make([]string, col.Len())
+			for i := range batch {
+				batch[i] = col.ValueStr(i)
+			}
+			values = append(values, batch)
+			for _, buf := range col.Data().Buffers() {
+				if buf != nil {
+					size += buf.Len()
+				}
+			}
+			rec.Release()
+		}
+		require.NoError(t, reader.Err())
+		return values, size
+	}
+
+	want, plainSize := read(t)
+	got, dedupSize := read(t, WithStringDedup())
+	assert.Equal(t, want, got)
+	assert.Less(t, dedupSize, plainSize)
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(t, repetitiveStrings), logger, WithStringDedup())
+	require.NoError(t, err)
+	defer reader.Release()
+	assert.Equal(t, arrow.PrimitiveTypes.Int64, reader.Schema().Field(0).Type)
+	assert.Equal(t, arrow.BinaryTypes.StringView, reader.Schema().Field(1).Type)
+	empty := reader.EmptyRecord()
+	defer empty.Release()
+	assert.True(t, reader.Schema().Equal(empty.Schema()))
+}
+
+func TestDedupStrings(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	b := array.NewStringBuilder(alloc)
+	defer b.Release()
+	b.AppendValues([]string{"a long repeated value", "tiny", "a long repeated value", "", "another long value!"},
+		[]bool{true, true, true, false, true})
+	arr := b.NewStringArray()
+	defer arr.Release()
+
+	out := dedupStrings(alloc, arr)
+	defer out.Release()
+	views := out.(*array.StringView)
+	assert.Equal(t, []string{"a long repeated value", "tiny", "a long repeated value", "(null)", "another long value!"},
+		[]string{views.Value(0), views.Value(1), views.Value(2), views.ValueStr(3), views.Value(4)})
+	assert.Equal(t, 1, views.NullN())
+	// Each long value is stored once
+	assert.Equal(t, len("a long repeated value")+len("another long value!"), out.Data().Buffers()[2].Len())
+}
+
+func BenchmarkBatchReaderStringDedup(b *testing.B) {
+	queries := []struct{ name, query string }{
+		{"mixed", repetitiveStrings},
+		// Long values are where sharing pays for the 16-byte views
+		{"long", `SELECT i AS id, repeat('customer segment ', 4) || (i % 3) AS segment FROM range(1000) t(i)`},
+	}
+	for _, q := range queries {
+		for _, dedup := range []bool{false, true} {
+			name := q.name + "/default"
+			var opts []Option
+			if dedup {
+				name = q.name + "/dedup"
+				opts = append(opts, WithStringDedup())
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				// The records' buffer size is what deduplication saves, paid
+				// for with the extra copy counted in B/op.
+				var held int
+				for i := 0; i < b.N; i++ {
+					reader, err := NewBatchReader(memory.NewGoAllocator(), queryRows(b, q.query), zerolog.Nop(), opts...)
+					require.NoError(b, err)
+					for reader.Next() {
+						rec := reader.Record()
+						for _, buf := range rec.Column(1).Data().Buffers() {
+							if buf != nil {
+								held += buf.Len()
+							}
+						}
+						rec.Release()
+					}
+					require.NoError(b, reader.Err())
+					reader.Release()
+				}
+				b.ReportMetric(float64(held)/float64(b.N), "record-B/op")
+			})
+		}
+	}
+}
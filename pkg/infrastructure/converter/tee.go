@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// Tee reads every record from reader and delivers it to both sinks, each
+// running on its own goroutine, so that for example one sink can write
+// Parquet while the other sends over Flight. Delivery waits for the slower
+// sink. Tee takes each record from reader and releases it once both sinks
+// are done with it; a record is valid only for the duration of a sink call
+// and a sink that keeps it must retain it. After a sink fails, no further
+// records are read and the first error is returned. The caller still owns
+// reader.
+func Tee(reader *BatchReader, first, second func(arrow.Record) error) error {
+	sinks := []func(arrow.Record) error{first, second}
+	chans := make([]chan arrow.Record, len(sinks))
+	errs := make([]error, len(sinks))
+	failed := make(chan struct{})
+	var (
+		failOnce sync.Once
+		wg       sync.WaitGroup
+	)
+
+	for i, sink := range sinks {
+		chans[i] = make(chan arrow.Record)
+		wg.Add(1)
+		go func(i int, sink func(arrow.Record) error, in <-chan arrow.Record) {
+			defer wg.Done()
+			for rec := range in {
+				// Records already queued after a failure are only released
+				if errs[i] == nil {
+					if err := sink(rec); err != nil {
+						errs[i] = err
+						failOnce.Do(func() { close(failed) })
+					}
+				}
+				rec.Release()
+			}
+		}(i, sink, chans[i])
+	}
+
+	// deliver hands rec to every sink, reporting false once one has failed.
+	deliver := func(rec arrow.Record) bool {
+		for _, ch := range chans {
+			rec.Retain()
+			select {
+			case ch <- rec:
+			case <-failed:
+				rec.Release()
+				return false
+			}
+		}
+		return true
+	}
+
+	var readErr error
+	for reader.Next() {
+		rec, err := reader.TakeRecord()
+		if err != nil {
+			readErr = err
+			break
+		}
+		ok := deliver(rec)
+		rec.Release()
+		if !ok {
+			break
+		}
+	}
+	for _, ch := range chans {
+		close(ch)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrapf(err, errors.CodeInternal, "tee sink %d failed", i+1)
+		}
+	}
+	if readErr == nil {
+		readErr = reader.Err()
+	}
+	if readErr != nil {
+		return errors.Wrap(readErr, errors.CodeInternal, "failed to read records")
+	}
+	return nil
+}
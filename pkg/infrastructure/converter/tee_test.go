@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTee(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc, queryRows(t, passthroughQuery), logger, WithBatchSize(2))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	// collect returns a sink keeping every record, optionally slowly.
+	collect := func(recs *[]arrow.Record, delay time.Duration) func(arrow.Record) error {
+		return func(rec arrow.Record) error {
+			time.Sleep(delay)
+			rec.Retain()
+			*recs = append(*recs, rec)
+			return nil
+		}
+	}
+	var fast, slow []arrow.Record
+	require.NoError(t, Tee(reader, collect(&fast, 0), collect(&slow, 5*time.Millisecond)))
+
+	require.Len(t, fast, 3)
+	assertSameColumns(t, fast, slow)
+	for i := range fast {
+		assert.True(t, array.RecordEqual(fast[i], slow[i]))
+		fast[i].Release()
+		slow[i].Release()
+	}
+}
+
+func TestTeeSinkError(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+
+	reader, err := NewBatchReader(alloc, queryRows(t, "SELECT i FROM range(100) t(i)"), logger, WithBatchSize(1))
+	require.NoError(t, err)
+	defer reader.Release()
+
+	var (
+		mu   sync.Mutex
+		seen int
+	)
+	err = Tee(reader,
+		func(arrow.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen++
+			return nil
+		},
+		func(rec arrow.Record) error {
+			if v := rec.Column(0).(*array.Int64).Value(0); v == 2 {
+				return fmt.Errorf("rejected %d", v)
+			}
+			return nil
+		})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected 2")
+	assert.Less(t, seen, 100)
+
+	// Tee stops reading on failure; drain so the reader's last batch is freed
+	for reader.Next() {
+	}
+}
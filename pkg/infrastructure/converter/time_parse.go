@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// defaultTimeLayouts are the layouts tried when a temporal column is returned
+// by the driver as a string. They cover DuckDB's text output for TIMESTAMP,
+// TIMESTAMPTZ, DATE and TIME with optional fractional seconds.
+var defaultTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// timeOrString is a scan destination for temporal columns that accepts either
+// a time.Time or its string representation from the driver.
+type timeOrString struct {
+	Time     time.Time
+	String   string
+	IsString bool
+	Valid    bool
+}
+
+// Scan implements sql.Scanner.
+func (t *timeOrString) Scan(src interface{}) error {
+	*t = timeOrString{}
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		t.Time = v
+	case string:
+		t.String, t.IsString = v, true
+	case []byte:
+		t.String, t.IsString = string(v), true
+	default:
+		return fmt.Errorf("cannot scan %T into a temporal column", src)
+	}
+	t.Valid = true
+	return nil
+}
+
+// isTemporalBuilder reports whether fb builds a date, time or timestamp column.
+func isTemporalBuilder(fb array.Builder) bool {
+	switch fb.(type) {
+	case *array.Date32Builder, *array.Date64Builder, *array.Time32Builder,
+		*array.Time64Builder, *array.TimestampBuilder:
+		return true
+	default:
+		return false
+	}
+}
+
+// appendTimeString parses s with the configured layouts and appends it to a
+// temporal builder.
+func (r *BatchReader) appendTimeString(fb array.Builder, s string) error {
+	layouts := r.opts.timeLayouts
+	if len(layouts) == 0 {
+		layouts = defaultTimeLayouts
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return r.appendTime(fb, t)
+		}
+	}
+	return errors.New(errors.CodeInternal, fmt.Sprintf("cannot parse %q as a temporal value", s))
+}
+
+// resolveColumnZones loads the zones configured with WithColumnTimezone,
+// returning one location per field, or nil if none are configured. Columns
+// whose database type in cols is DATE keep a nil location: their values
+// arrive as midnight UTC and carry no time of day to convert.
+func resolveColumnZones(fields []arrow.Field, cols []*sql.ColumnType, zones map[string]string) ([]*time.Location, error) {
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	locs := make([]*time.Location, len(fields))
+	for name, tz := range zones {
+		idx := -1
+		for i := range fields {
+			if fields[i].Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("cannot set time zone of unknown column %q", name))
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, errors.Wrapf(err, errors.CodeInvalidRequest, "invalid time zone %q for column %q", tz, name)
+		}
+		if idx < len(cols) && strings.EqualFold(cols[idx].DatabaseTypeName(), "DATE") {
+			continue
+		}
+		locs[idx] = loc
+	}
+	return locs, nil
+}
+
+// appendColumnTime appends t to the builder of column colIdx. Dates are
+// taken from the civil day of t in the column's zone, if one is set.
+func (r *BatchReader) appendColumnTime(colIdx int, fb array.Builder, t time.Time) error {
+	if colIdx < len(r.zones) && r.zones[colIdx] != nil {
+		switch fb.(type) {
+		case *array.Date32Builder, *array.Date64Builder, *array.Int32Builder:
+			y, m, d := t.In(r.zones[colIdx]).Date()
+			t = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return r.appendTime(fb, t)
+}
@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// MapRecords returns a reader over the records of reader transformed by
+// fn. fn borrows each record and returns a new record of the same schema,
+// which the reader takes ownership of; it may return its argument after
+// retaining it, or nil to drop the batch. An error from fn, or a record of
+// another schema, fails the read. MapRecords takes ownership of reader.
+func MapRecords(reader *BatchReader, fn func(arrow.Record) (arrow.Record, error)) *BatchReader {
+	m := &recordMapper{input: reader, fn: fn}
+	return newDerivedReader(reader.Schema(), reader.allocator, reader.logger,
+		recordSource{next: m.next, close: reader.Release})
+}
+
+// recordMapper produces the transformed records of MapRecords.
+type recordMapper struct {
+	input *BatchReader
+	fn    func(arrow.Record) (arrow.Record, error)
+}
+
+// next returns the next transformed record, or nil at the end of the
+// input.
+func (m *recordMapper) next() (arrow.Record, error) {
+	for m.input.Next() {
+		out, err := m.fn(m.input.record)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "failed to transform record")
+		}
+		if out == nil {
+			continue
+		}
+		if !out.Schema().Equal(m.input.Schema()) {
+			out.Release()
+			return nil, errors.New(errors.CodeInvalidRequest, "transformed record does not match the reader's schema")
+		}
+		return out, nil
+	}
+	if err := m.input.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to read transform input")
+	}
+	return nil, nil
+}
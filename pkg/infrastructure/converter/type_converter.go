@@ -8,8 +8,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
 	"github.com/rs/zerolog"
 
 	"github.com/TFMV/porter/pkg/errors"
@@ -38,18 +40,42 @@ type typeConverter struct {
 	reverseMap map[arrow.Type]string
 	sqlMap     map[string]int32
 	logger     zerolog.Logger
+
+	// sessionTimeZone is attached to TIMESTAMPTZ columns so clients render
+	// the UTC instants in the session's zone.
+	sessionTimeZone string
+
+	unknownTypes UnknownTypeMode
+	uhugeint     UHugeIntMode
 }
 
+// defaultSessionTimeZone is used for TIMESTAMPTZ columns unless configured.
+const defaultSessionTimeZone = "UTC"
+
 // New creates a new type converter.
 func New(logger zerolog.Logger) TypeConverter {
-	tc := &typeConverter{
-		typeMap:    initializeTypeMap(),
-		reverseMap: initializeReverseMap(),
-		sqlMap:     initializeSQLMap(),
-		logger:     logger,
+	return newTypeConverter(logger)
+}
+
+// NewWithSessionTimeZone creates a type converter that tags TIMESTAMPTZ
+// columns with the given IANA time zone instead of UTC.
+func NewWithSessionTimeZone(logger zerolog.Logger, timeZone string) (TypeConverter, error) {
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, errors.Wrapf(err, errors.CodeInvalidRequest, "invalid session time zone %q", timeZone)
 	}
+	tc := newTypeConverter(logger)
+	tc.sessionTimeZone = timeZone
+	return tc, nil
+}
 
-	return tc
+func newTypeConverter(logger zerolog.Logger) *typeConverter {
+	return &typeConverter{
+		typeMap:         initializeTypeMap(),
+		reverseMap:      initializeReverseMap(),
+		sqlMap:          initializeSQLMap(),
+		logger:          logger,
+		sessionTimeZone: defaultSessionTimeZone,
+	}
 }
 
 // ConvertDuckDBTypeToArrow converts a DuckDB type string to an Apache Arrow DataType.
@@ -108,7 +134,7 @@ func (tc *typeConverter) ArrowToDuckDBType(arrowType arrow.DataType) (string, er
 
 	// Handle special cases
 	switch arrowType.ID() {
-	case arrow.DECIMAL, arrow.DECIMAL256:
+	case arrow.DECIMAL32, arrow.DECIMAL64, arrow.DECIMAL, arrow.DECIMAL256:
 		decimalType := arrowType.(arrow.DecimalType)
 		return fmt.Sprintf("DECIMAL(%d,%d)", decimalType.GetPrecision(), decimalType.GetScale()), nil
 	case arrow.FIXED_SIZE_BINARY:
@@ -123,6 +149,15 @@ func (tc *typeConverter) ArrowToDuckDBType(arrowType arrow.DataType) (string, er
 	case arrow.STRUCT:
 		// Handle struct types
 		return "STRUCT", nil
+	case arrow.EXTENSION:
+		switch ext := arrowType.(arrow.ExtensionType); ext.ExtensionName() {
+		case "arrow.uuid":
+			return "UUID", nil
+		case jsonType.ExtensionName():
+			return "JSON", nil
+		default:
+			return tc.ArrowToDuckDBType(ext.StorageType())
+		}
 	default:
 		return "", errors.New(errors.CodeInternal, fmt.Sprintf("unsupported Arrow type: %s", arrowType))
 	}
@@ -140,19 +175,60 @@ func (tc *typeConverter) GetSQLType(duckdbType string) int32 {
 	return int32(java_sql_Types_VARCHAR)
 }
 
+// UnknownTypeMode selects how the reader handles columns whose type cannot
+// be mapped to Arrow.
+type UnknownTypeMode int
+
+const (
+	// UnknownTypeString resolves column types through a fallback chain:
+	// the DatabaseTypeName reported by the driver, then the driver's Go
+	// ScanType, and finally a String column holding each value formatted
+	// as text. This is the default.
+	UnknownTypeString UnknownTypeMode = iota
+	// UnknownTypeError fails when a column's DatabaseTypeName is empty or
+	// not recognized.
+	UnknownTypeError
+)
+
+// UHugeIntMode selects the Arrow type of DuckDB UHUGEINT (unsigned 128-bit
+// integer) columns, for drivers able to return them; go-duckdb is not.
+type UHugeIntMode int
+
+const (
+	// UHugeIntDecimal maps UHUGEINT to Decimal128 with precision 38 and
+	// scale 0. Values of 10^38 and above do not fit and are reported as
+	// conversion errors. This is the default.
+	UHugeIntDecimal UHugeIntMode = iota
+	// UHugeIntString maps UHUGEINT to String holding the decimal digits,
+	// covering the full range up to 2^128-1.
+	UHugeIntString
+)
+
 // GetArrowFieldFromColumn converts a SQL column to an Arrow field.
 func (tc *typeConverter) GetArrowFieldFromColumn(col *sql.ColumnType) (arrow.Field, error) {
+	field, _, err := tc.fieldFromColumn(col)
+	return field, err
+}
+
+// fieldFromColumn converts a SQL column to an Arrow field. It reports
+// whether the column's values must be formatted as strings because its type
+// could not be determined.
+func (tc *typeConverter) fieldFromColumn(col *sql.ColumnType) (arrow.Field, bool, error) {
 	// Get Arrow type
-	arrowType, err := tc.getArrowTypeFromColumnType(col)
+	arrowType, asString, err := tc.getArrowTypeFromColumnType(col)
 	if err != nil {
-		return arrow.Field{}, err
+		return arrow.Field{}, false, err
 	}
 
 	// Build metadata
 	metadata := tc.buildColumnMetadata(col)
 
-	// Get nullability
-	nullable, _ := col.Nullable()
+	// Get nullability; drivers that cannot tell (DuckDB among them) must be
+	// treated as nullable or NULL values would fail to scan
+	nullable, ok := col.Nullable()
+	if !ok {
+		nullable = true
+	}
 
 	// Create field
 	field := arrow.Field{
@@ -162,7 +238,7 @@ func (tc *typeConverter) GetArrowFieldFromColumn(col *sql.ColumnType) (arrow.Fie
 		Metadata: metadata,
 	}
 
-	return field, nil
+	return field, asString, nil
 }
 
 // ConvertToArrowSchema converts SQL column types to an Arrow schema.
@@ -180,56 +256,77 @@ func (tc *typeConverter) ConvertToArrowSchema(cols []*sql.ColumnType) (*arrow.Sc
 	return arrow.NewSchema(fields, nil), nil
 }
 
-// getArrowTypeFromColumnType determines Arrow type from SQL column type.
-func (tc *typeConverter) getArrowTypeFromColumnType(col *sql.ColumnType) (arrow.DataType, error) {
+// getArrowTypeFromColumnType determines Arrow type from SQL column type,
+// following the chain described by UnknownTypeString. It reports whether the
+// type fell back to strings because neither the type name nor the scan type
+// identified it.
+func (tc *typeConverter) getArrowTypeFromColumnType(col *sql.ColumnType) (arrow.DataType, bool, error) {
 	// First try database type name
 	dbType := col.DatabaseTypeName()
 	if dbType != "" {
-		return tc.DuckDBToArrowType(dbType)
+		dt, err := tc.DuckDBToArrowType(dbType)
+		if err == nil {
+			return dt, false, nil
+		}
+		if tc.unknownTypes == UnknownTypeError {
+			return nil, false, err
+		}
+		tc.logger.Warn().
+			Str("column", col.Name()).
+			Str("type_name", dbType).
+			Msg("unrecognized database type name, falling back to scan type")
+	} else if tc.unknownTypes == UnknownTypeError {
+		return nil, false, errors.New(errors.CodeInvalidRequest,
+			fmt.Sprintf("driver reported no type name for column %q", col.Name()))
 	}
 
 	// Fall back to scan type
 	scanType := col.ScanType()
 	if scanType == nil {
 		// Default to string if we can't determine type
-		return arrow.BinaryTypes.String, nil
+		return arrow.BinaryTypes.String, true, nil
+	}
+
+	// time.Duration is an int64 kind, but its values are durations
+	if scanType == reflect.TypeOf(time.Duration(0)) {
+		return arrow.FixedWidthTypes.Duration_ns, false, nil
 	}
 
 	// Map Go types to Arrow types
 	switch scanType.Kind() {
 	case reflect.Bool:
-		return arrow.FixedWidthTypes.Boolean, nil
+		return arrow.FixedWidthTypes.Boolean, false, nil
 	case reflect.Int8:
-		return arrow.PrimitiveTypes.Int8, nil
+		return arrow.PrimitiveTypes.Int8, false, nil
 	case reflect.Int16:
-		return arrow.PrimitiveTypes.Int16, nil
+		return arrow.PrimitiveTypes.Int16, false, nil
 	case reflect.Int32:
-		return arrow.PrimitiveTypes.Int32, nil
+		return arrow.PrimitiveTypes.Int32, false, nil
 	case reflect.Int, reflect.Int64:
-		return arrow.PrimitiveTypes.Int64, nil
+		return arrow.PrimitiveTypes.Int64, false, nil
 	case reflect.Uint8:
-		return arrow.PrimitiveTypes.Uint8, nil
+		return arrow.PrimitiveTypes.Uint8, false, nil
 	case reflect.Uint16:
-		return arrow.PrimitiveTypes.Uint16, nil
+		return arrow.PrimitiveTypes.Uint16, false, nil
 	case reflect.Uint32:
-		return arrow.PrimitiveTypes.Uint32, nil
+		return arrow.PrimitiveTypes.Uint32, false, nil
 	case reflect.Uint, reflect.Uint64:
-		return arrow.PrimitiveTypes.Uint64, nil
+		return arrow.PrimitiveTypes.Uint64, false, nil
 	case reflect.Float32:
-		return arrow.PrimitiveTypes.Float32, nil
+		return arrow.PrimitiveTypes.Float32, false, nil
 	case reflect.Float64:
-		return arrow.PrimitiveTypes.Float64, nil
+		return arrow.PrimitiveTypes.Float64, false, nil
 	case reflect.String:
-		return arrow.BinaryTypes.String, nil
+		return arrow.BinaryTypes.String, false, nil
 	case reflect.Slice:
 		if scanType.Elem().Kind() == reflect.Uint8 {
-			return arrow.BinaryTypes.Binary, nil
+			return arrow.BinaryTypes.Binary, false, nil
 		}
 		// Default to string for other slices
-		return arrow.BinaryTypes.String, nil
+		return arrow.BinaryTypes.String, true, nil
 	default:
 		// Default to string for unknown types
-		return arrow.BinaryTypes.String, nil
+		return arrow.BinaryTypes.String, true, nil
 	}
 }
 
@@ -366,11 +463,11 @@ func initializeTypeMap() map[string]arrow.DataType {
 		"timestamp": arrow.FixedWidthTypes.Timestamp_us,
 		"interval":  arrow.FixedWidthTypes.MonthDayNanoInterval,
 
-		// UUID type
-		"uuid": arrow.BinaryTypes.String, // UUID as string for compatibility
+		// UUID type, 16 bytes as the driver returns them
+		"uuid": extensions.NewUUIDType(),
 
-		// JSON type
-		"json": arrow.BinaryTypes.String, // JSON as string
+		// JSON type, serialized text of the values the driver decodes
+		"json": jsonType,
 	}
 }
 
@@ -407,6 +504,7 @@ func initializeReverseMap() map[arrow.Type]string {
 		arrow.TIME64:                  "TIME",
 		arrow.TIMESTAMP:               "TIMESTAMP",
 		arrow.INTERVAL_MONTH_DAY_NANO: "INTERVAL",
+		arrow.INTERVAL_DAY_TIME:       "INTERVAL",
 	}
 }
 
@@ -429,6 +527,8 @@ func initializeSQLMap() map[string]int32 {
 		"date":      int32(java_sql_Types_DATE),
 		"time":      int32(java_sql_Types_TIME),
 		"timestamp": int32(java_sql_Types_TIMESTAMP),
+
+		"timestamptz": int32(java_sql_Types_TIMESTAMP_WITH_TIMEZONE),
 	}
 }
 
@@ -477,9 +577,47 @@ const (
 
 // DuckDBToArrowType converts a DuckDB type string to an Apache Arrow DataType.
 func (tc *typeConverter) DuckDBToArrowType(duckdbType string) (arrow.DataType, error) {
-	duckdbType = strings.ToLower(strings.TrimSpace(duckdbType))
-	if arrowType, ok := tc.typeMap[duckdbType]; ok {
+	// Nested types are parsed from the original string so that struct
+	// field names keep their case.
+	duckdbType = strings.TrimSpace(duckdbType)
+	lowerType := strings.ToLower(duckdbType)
+	if arrowType, ok := tc.typeMap[lowerType]; ok {
 		return arrowType, nil
 	}
-	return ConvertDuckDBTypeToArrow(duckdbType)
+
+	if lowerType == "uhugeint" {
+		if tc.uhugeint == UHugeIntString {
+			return arrow.BinaryTypes.String, nil
+		}
+		return &arrow.Decimal128Type{Precision: 38, Scale: 0}, nil
+	}
+
+	// Handle zoned timestamps, stored as UTC instants
+	if lowerType == "timestamptz" || lowerType == "timestamp with time zone" {
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: tc.sessionTimeZone}, nil
+	}
+
+	// Handle list types such as TIMESTAMP[] or INTEGER[][]. DuckDB cannot
+	// declare list elements NOT NULL, and the type name is all the driver
+	// reports, so elements are always nullable, whatever the nullability
+	// of the column itself.
+	if strings.HasSuffix(duckdbType, "[]") {
+		elemType, err := tc.DuckDBToArrowType(strings.TrimSuffix(duckdbType, "[]"))
+		if err != nil {
+			return nil, err
+		}
+		return arrow.ListOfField(arrow.Field{Name: "item", Type: elemType, Nullable: true}), nil
+	}
+
+	// Handle struct types such as STRUCT("a" INTEGER, "b" VARCHAR)
+	if strings.HasPrefix(lowerType, "struct(") && strings.HasSuffix(duckdbType, ")") {
+		return tc.parseStructType(duckdbType[len("struct(") : len(duckdbType)-1])
+	}
+
+	// Handle map types such as MAP(INTEGER, VARCHAR)
+	if strings.HasPrefix(lowerType, "map(") && strings.HasSuffix(duckdbType, ")") {
+		return tc.parseMapType(duckdbType[len("map(") : len(duckdbType)-1])
+	}
+
+	return ConvertDuckDBTypeToArrow(lowerType)
 }
@@ -1,10 +1,20 @@
 package converter
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -66,6 +76,53 @@ func TestTypeConverter(t *testing.T) {
 				duckType: "decimal(18,2)",
 				want:     &arrow.Decimal128Type{Precision: 18, Scale: 2},
 			},
+			{
+				name:     "timestamp list",
+				duckType: "TIMESTAMP[]",
+				want:     arrow.ListOf(arrow.FixedWidthTypes.Timestamp_us),
+			},
+			{
+				name:     "boolean list",
+				duckType: "BOOLEAN[]",
+				want:     arrow.ListOf(arrow.FixedWidthTypes.Boolean),
+			},
+			{
+				name:     "struct",
+				duckType: `STRUCT("Mixed Case" INTEGER, "tags" VARCHAR[])`,
+				want: arrow.StructOf(
+					arrow.Field{Name: "Mixed Case", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+					arrow.Field{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+				),
+			},
+			{
+				name:     "anonymous struct",
+				duckType: `STRUCT("" INTEGER, "" VARCHAR)`,
+				want: arrow.StructOf(
+					arrow.Field{Name: "f0", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+					arrow.Field{Name: "f1", Type: arrow.BinaryTypes.String, Nullable: true},
+				),
+			},
+			{
+				name:     "duplicate struct names",
+				duckType: `STRUCT("a" INTEGER, "a" VARCHAR)`,
+				want: arrow.StructOf(
+					arrow.Field{Name: "f0", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+					arrow.Field{Name: "f1", Type: arrow.BinaryTypes.String, Nullable: true},
+				),
+			},
+			{
+				name:     "partly anonymous struct",
+				duckType: `STRUCT("" INTEGER, "b" VARCHAR)`,
+				want: arrow.StructOf(
+					arrow.Field{Name: "f0", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+					arrow.Field{Name: "f1", Type: arrow.BinaryTypes.String, Nullable: true},
+				),
+			},
+			{
+				name:     "integer key map",
+				duckType: "MAP(INTEGER, VARCHAR[])",
+				want:     arrow.MapOf(arrow.PrimitiveTypes.Int32, arrow.ListOf(arrow.BinaryTypes.String)),
+			},
 			{
 				name:     "invalid type",
 				duckType: "invalid_type",
@@ -360,3 +417,296 @@ func TestTypeConverter(t *testing.T) {
 		}
 	})
 }
+
+// fakeResult is a result set served by fakeConnector, with the type names
+// and scan types its driver reports for each column.
+type fakeResult struct {
+	columns   []string
+	typeNames []string
+	scanTypes []reflect.Type
+	rows      [][]driver.Value
+	// notNull marks the columns reported as NOT NULL; others are nullable.
+	notNull []bool
+	// following is the next result set of a multi-statement query, if any.
+	following *fakeResult
+}
+
+type fakeConnector struct{ result *fakeResult }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ result *fakeResult }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (fakeConn) Close() error                          { return nil }
+func (fakeConn) Begin() (driver.Tx, error)             { return nil, fmt.Errorf("not supported") }
+
+type fakeStmt struct{ result *fakeResult }
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, fmt.Errorf("not supported") }
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.result}, nil
+}
+
+type fakeRows struct {
+	result *fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string                       { return r.result.columns }
+func (r *fakeRows) Close() error                            { return nil }
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string { return r.result.typeNames[i] }
+func (r *fakeRows) ColumnTypeScanType(i int) reflect.Type   { return r.result.scanTypes[i] }
+func (r *fakeRows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	return i >= len(r.result.notNull) || !r.result.notNull[i], true
+}
+func (r *fakeRows) ColumnTypeLength(int) (length int64, ok bool)       { return 0, false }
+func (r *fakeRows) ColumnTypePrecisionScale(int) (p, s int64, ok bool) { return 0, 0, false }
+func (r *fakeRows) HasNextResultSet() bool                             { return r.result.following != nil }
+func (r *fakeRows) NextResultSet() error {
+	if r.result.following == nil {
+		return io.EOF
+	}
+	r.result, r.next = r.result.following, 0
+	return nil
+}
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestBatchReaderUnknownTypeName(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	result := &fakeResult{
+		columns:   []string{"g", "n", "s"},
+		typeNames: []string{"GEOGRAPHY", "HUGEFLOAT", ""},
+		scanTypes: []reflect.Type{
+			reflect.TypeOf((*interface{})(nil)).Elem(),
+			reflect.TypeOf(int64(0)),
+			reflect.TypeOf(""),
+		},
+		rows: [][]driver.Value{
+			{map[string]interface{}{"lat": 1.5}, int64(7), "a"},
+			{nil, nil, nil},
+		},
+	}
+	query := func(t *testing.T) *sql.Rows {
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT")
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("string fallback", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), query(t), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+
+		schema := reader.Schema()
+		assert.Equal(t, arrow.BinaryTypes.String, schema.Field(0).Type)
+		assert.Equal(t, arrow.PrimitiveTypes.Int64, schema.Field(1).Type)
+		assert.Equal(t, arrow.BinaryTypes.String, schema.Field(2).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, `["map[lat:1.5]" (null)]`, rec.Column(0).String())
+		assert.Equal(t, `[7 (null)]`, rec.Column(1).String())
+		assert.Equal(t, `["a" (null)]`, rec.Column(2).String())
+	})
+
+	t.Run("error mode", func(t *testing.T) {
+		_, err := NewBatchReader(memory.NewGoAllocator(), query(t), logger, WithUnknownTypeMode(UnknownTypeError))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported DuckDB type: geography")
+	})
+}
+
+func TestBatchReaderUHugeInt(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	maxUHugeInt, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	maxDecimal, _ := new(big.Int).SetString("99999999999999999999999999999999999999", 10)
+
+	// The DuckDB driver cannot scan UHUGEINT yet, so the values come from a
+	// fake driver returning them as *big.Int like HUGEINT.
+	open := func(values ...*big.Int) *sql.Rows {
+		result := &fakeResult{
+			columns:   []string{"u"},
+			typeNames: []string{"UHUGEINT"},
+			scanTypes: []reflect.Type{reflect.TypeOf((*big.Int)(nil))},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v})
+		}
+		result.rows = append(result.rows, []driver.Value{nil})
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT u")
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("decimal", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(big.NewInt(42), maxDecimal), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, &arrow.Decimal128Type{Precision: 38, Scale: 0}, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.Decimal128)
+		assert.Equal(t, "42", col.Value(0).ToString(0))
+		assert.Equal(t, maxDecimal, col.Value(1).BigInt())
+		assert.True(t, col.IsNull(2))
+	})
+
+	t.Run("decimal overflow", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(maxUHugeInt), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), "overflows decimal(38, 0)")
+	})
+
+	t.Run("string", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open(maxUHugeInt), logger, WithUHugeIntMode(UHugeIntString))
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.BinaryTypes.String, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		col := rec.Column(0).(*array.String)
+		assert.Equal(t, maxUHugeInt.String(), col.Value(0))
+		assert.True(t, col.IsNull(1))
+	})
+}
+
+func TestListElementNullability(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	anyType := reflect.TypeOf((*interface{})(nil)).Elem()
+
+	result := &fakeResult{
+		columns:   []string{"required", "optional", "nested"},
+		typeNames: []string{"INTEGER[]", "VARCHAR[]", "INTEGER[][]"},
+		scanTypes: []reflect.Type{anyType, anyType, anyType},
+		notNull:   []bool{true, false, true},
+		rows: [][]driver.Value{
+			{[]interface{}{int32(1), nil}, nil, []interface{}{[]interface{}{nil}}},
+		},
+	}
+	db := sql.OpenDB(fakeConnector{result})
+	defer db.Close()
+	rows, err := db.Query("SELECT required, optional, nested")
+	require.NoError(t, err)
+
+	reader, err := NewBatchReader(memory.NewGoAllocator(), rows, logger)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	schema := reader.Schema()
+	assert.False(t, schema.Field(0).Nullable)
+	assert.True(t, schema.Field(1).Nullable)
+	for i, f := range schema.Fields() {
+		elem := f.Type.(*arrow.ListType).ElemField()
+		assert.True(t, elem.Nullable, "elements of %s", f.Name)
+		assert.Equal(t, "item", elem.Name)
+		if i == 2 {
+			assert.True(t, elem.Type.(*arrow.ListType).ElemField().Nullable)
+		}
+	}
+	assert.Equal(t, arrow.ListOf(arrow.PrimitiveTypes.Int32), schema.Field(0).Type)
+
+	require.True(t, reader.Next(), reader.Err())
+	rec := reader.Record()
+	defer rec.Release()
+	// A NOT NULL list still holds null elements
+	assert.True(t, rec.Column(0).(*array.List).ListValues().IsNull(1))
+}
+
+func TestBatchReaderStringBooleans(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	// openColumn opens a BOOLEAN column of values, reported NOT NULL if
+	// notNull is set.
+	openColumn := func(notNull bool, values ...driver.Value) *sql.Rows {
+		result := &fakeResult{
+			columns:   []string{"b"},
+			typeNames: []string{"BOOLEAN"},
+			scanTypes: []reflect.Type{reflect.TypeOf("")},
+			notNull:   []bool{notNull},
+		}
+		for _, v := range values {
+			result.rows = append(result.rows, []driver.Value{v})
+		}
+		db := sql.OpenDB(fakeConnector{result})
+		t.Cleanup(func() { db.Close() })
+		rows, err := db.Query("SELECT b")
+		require.NoError(t, err)
+		return rows
+	}
+	open := func(values ...driver.Value) *sql.Rows { return openColumn(false, values...) }
+
+	t.Run("spellings", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(),
+			open("t", "f", "TRUE", "false", " yes ", "No", "on", "OFF", "1", "0", nil, "maybe"), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.Equal(t, arrow.FixedWidthTypes.Boolean, reader.Schema().Field(0).Type)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		defer rec.Release()
+		assert.Equal(t, "[true false true false true false true false true false (null) (null)]", rec.Column(0).String())
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open("t", "maybe"), logger, WithStrictBooleans())
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		assert.Equal(t, "[true]", rec.Column(0).String())
+		rec.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), `cannot parse "maybe" as a boolean`)
+	})
+
+	t.Run("not null", func(t *testing.T) {
+		// A null cannot stand in for the value of a NOT NULL column
+		reader, err := NewBatchReader(memory.NewGoAllocator(), openColumn(true, "t", "maybe"), logger)
+		require.NoError(t, err)
+		defer reader.Release()
+		assert.False(t, reader.Schema().Field(0).Nullable)
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		assert.Equal(t, "[true]", rec.Column(0).String())
+		rec.Release()
+		assert.False(t, reader.Next())
+		assert.ErrorContains(t, reader.Err(), `non-nullable column "b"`)
+	})
+
+	t.Run("logged once", func(t *testing.T) {
+		var logs bytes.Buffer
+		reader, err := NewBatchReader(memory.NewGoAllocator(), open("maybe", "t", "perhaps", "nope"), zerolog.New(&logs))
+		require.NoError(t, err)
+		defer reader.Release()
+
+		require.True(t, reader.Next(), reader.Err())
+		rec := reader.Record()
+		assert.Equal(t, "[(null) true (null) (null)]", rec.Column(0).String())
+		rec.Release()
+		assert.Equal(t, 1, strings.Count(logs.String(), "unrecognized booleans"))
+	})
+}
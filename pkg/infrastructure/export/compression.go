@@ -0,0 +1,29 @@
+package export
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// StreamCompression selects the compression applied to the whole output
+// of the text exporters.
+type StreamCompression int
+
+// Supported stream compression codecs.
+const (
+	StreamUncompressed StreamCompression = iota
+	StreamGzip
+)
+
+// compressWriter wraps w in the writer for c. The returned function must
+// be called once writing is done to flush and close the compressed stream;
+// it leaves w open.
+func compressWriter(w io.Writer, c StreamCompression) (io.Writer, func() error) {
+	switch c {
+	case StreamGzip:
+		gw := gzip.NewWriter(w)
+		return gw, gw.Close
+	default:
+		return w, func() error { return nil }
+	}
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	arrowcsv "github.com/apache/arrow-go/v18/arrow/csv"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// CSVOptions configures CSV export.
+type CSVOptions struct {
+	// Delimiter separates fields; defaults to a comma.
+	Delimiter rune
+	// NullValue is written for null values; defaults to an empty field.
+	NullValue string
+	// Compression applied to the whole file; defaults to uncompressed.
+	Compression StreamCompression
+}
+
+// ExportCSV writes every record from reader to w as CSV and returns the
+// number of rows written. The header row of column names is always written,
+// so a result without rows still produces a file describing its columns.
+// Struct and map columns are not supported. With compression, the
+// compressed stream is completed before returning, but w is not closed.
+func ExportCSV(ctx context.Context, reader RecordSource, w io.Writer, opts CSVOptions) (int64, error) {
+	out, finish := compressWriter(w, opts.Compression)
+	rows, err := writeCSV(ctx, reader, out, opts)
+	if cerr := finish(); cerr != nil && err == nil {
+		err = errors.Wrap(cerr, errors.CodeInternal, "failed to finish compressed csv")
+	}
+	return rows, err
+}
+
+// writeCSV writes the CSV of ExportCSV to w.
+func writeCSV(ctx context.Context, reader RecordSource, w io.Writer, opts CSVOptions) (int64, error) {
+	delim := opts.Delimiter
+	if delim == 0 {
+		delim = ','
+	}
+	schema := reader.Schema()
+
+	cw, err := newCSVWriter(w, schema, delim, opts.NullValue)
+	if err != nil {
+		return 0, err
+	}
+
+	// The header is written up front rather than with the first record so
+	// that it is present even when the reader yields nothing.
+	hw := csv.NewWriter(w)
+	hw.Comma = delim
+	header := make([]string, schema.NumFields())
+	for i, f := range schema.Fields() {
+		header[i] = f.Name
+	}
+	hw.Write(header)
+	hw.Flush()
+	if err := hw.Error(); err != nil {
+		return 0, errors.Wrap(err, errors.CodeInternal, "failed to write csv header")
+	}
+
+	var rows int64
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			return rows, errors.Wrap(err, errors.CodeCanceled, "csv export canceled")
+		}
+
+		rec, err := reader.TakeRecord()
+		if err != nil {
+			return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+		}
+		n, err := rec.NumRows(), cw.Write(rec)
+		rec.Release()
+		if err != nil {
+			return rows, errors.Wrap(err, errors.CodeInternal, "failed to write csv rows")
+		}
+		rows += n
+	}
+	if err := reader.Err(); err != nil {
+		return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+	}
+
+	if err := cw.Flush(); err != nil {
+		return rows, errors.Wrap(err, errors.CodeInternal, "failed to flush csv writer")
+	}
+	return rows, nil
+}
+
+// newCSVWriter creates the Arrow CSV writer, reporting the schemas it
+// rejects as errors instead of panics.
+func newCSVWriter(w io.Writer, schema *arrow.Schema, delim rune, null string) (cw *arrowcsv.Writer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(errors.CodeInvalidRequest, fmt.Sprintf("unsupported schema for csv export: %v", r))
+		}
+	}()
+	return arrowcsv.NewWriter(w, schema, arrowcsv.WithComma(delim), arrowcsv.WithNullWriter(null)), nil
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/infrastructure/converter"
+)
+
+func TestExportCSV(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc, []int64{1, 2}, []int64{3})
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	n, err := ExportCSV(context.Background(), reader, &buf, CSVOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "id\n1\n2\n3\n", buf.String())
+}
+
+func TestExportCSVEmpty(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	reader := converter.NewReplayReader(schema, nil)
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	n, err := ExportCSV(context.Background(), reader, &buf, CSVOptions{Delimiter: ';'})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, "id;name\n", buf.String())
+}
+
+func TestExportCSVUnsupportedType(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "s", Type: arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int64})},
+	}, nil)
+	reader := converter.NewReplayReader(schema, nil)
+	defer reader.Release()
+
+	_, err := ExportCSV(context.Background(), reader, &bytes.Buffer{}, CSVOptions{})
+	assert.Error(t, err)
+}
+
+func TestExportCSVNulls(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	b := array.NewRecordBuilder(alloc, schema)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	reader := converter.NewReplayReader(schema, []arrow.Record{rec})
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	_, err := ExportCSV(context.Background(), reader, &buf, CSVOptions{NullValue: "NA"})
+	require.NoError(t, err)
+	assert.Equal(t, "name\na\nNA\n", buf.String())
+}
+
+func TestExportCSVGzip(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc, []int64{1, 2}, []int64{3})
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	n, err := ExportCSV(context.Background(), reader, &buf, CSVOptions{Compression: StreamGzip})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	zr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.NoError(t, zr.Close())
+	assert.Equal(t, "id\n1\n2\n3\n", string(data))
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// FeatherCompression selects the buffer compression used in Feather files.
+type FeatherCompression int
+
+// Supported Feather compression codecs.
+const (
+	FeatherUncompressed FeatherCompression = iota
+	FeatherLZ4
+	FeatherZstd
+)
+
+// FeatherOptions configures Feather (Arrow IPC file) export.
+type FeatherOptions struct {
+	// Allocator used while encoding; defaults to the Go allocator.
+	Allocator memory.Allocator
+	// Compression codec for record batch buffers; defaults to uncompressed.
+	Compression FeatherCompression
+}
+
+// ExportFeather writes every record from reader to w in the Arrow IPC file
+// (Feather v2) format, including the footer needed for random access, and
+// returns the number of rows written.
+func ExportFeather(ctx context.Context, reader RecordSource, w io.Writer, opts FeatherOptions) (int64, error) {
+	alloc := opts.Allocator
+	if alloc == nil {
+		alloc = memory.NewGoAllocator()
+	}
+
+	ipcOpts := []ipc.Option{
+		ipc.WithSchema(reader.Schema()),
+		ipc.WithAllocator(alloc),
+	}
+	switch opts.Compression {
+	case FeatherUncompressed:
+	case FeatherLZ4:
+		ipcOpts = append(ipcOpts, ipc.WithLZ4())
+	case FeatherZstd:
+		ipcOpts = append(ipcOpts, ipc.WithZstd())
+	default:
+		return 0, errors.New(errors.CodeInvalidRequest, "unsupported feather compression")
+	}
+
+	fw, err := ipc.NewFileWriter(w, ipcOpts...)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.CodeInternal, "failed to create feather writer")
+	}
+
+	var rows int64
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			fw.Close()
+			return rows, errors.Wrap(err, errors.CodeCanceled, "feather export canceled")
+		}
+
+		rec, err := reader.TakeRecord()
+		if err != nil {
+			fw.Close()
+			return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+		}
+		n, err := rec.NumRows(), fw.Write(rec)
+		rec.Release()
+		if err != nil {
+			fw.Close()
+			return rows, errors.Wrap(err, errors.CodeInternal, "failed to write feather record batch")
+		}
+		rows += n
+	}
+	if err := reader.Err(); err != nil {
+		fw.Close()
+		return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+	}
+
+	if err := fw.Close(); err != nil {
+		return rows, errors.Wrap(err, errors.CodeInternal, "failed to close feather writer")
+	}
+	return rows, nil
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/infrastructure/converter"
+)
+
+// newIntReader builds a reader with one int64 batch per entry in batches,
+// allocating the batches from alloc.
+func newIntReader(t *testing.T, alloc memory.Allocator, batches ...[]int64) *converter.BatchReader {
+	t.Helper()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+
+	b := array.NewRecordBuilder(alloc, schema)
+	defer b.Release()
+
+	recs := make([]arrow.Record, 0, len(batches))
+	for _, values := range batches {
+		b.Field(0).(*array.Int64Builder).AppendValues(values, nil)
+		recs = append(recs, b.NewRecord())
+	}
+
+	reader := converter.NewReplayReader(schema, recs)
+	for _, rec := range recs {
+		rec.Release()
+	}
+	return reader
+}
+
+func TestExportFeather(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression FeatherCompression
+	}{
+		{name: "uncompressed", compression: FeatherUncompressed},
+		{name: "lz4", compression: FeatherLZ4},
+		{name: "zstd", compression: FeatherZstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+			reader := newIntReader(t, alloc, []int64{1, 2, 3}, []int64{4, 5})
+			defer reader.Release()
+
+			var buf bytes.Buffer
+			n, err := ExportFeather(context.Background(), reader, &buf, FeatherOptions{Compression: tt.compression})
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), n)
+
+			fr, err := ipc.NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			defer fr.Close()
+
+			assert.True(t, reader.Schema().Equal(fr.Schema()))
+			require.Equal(t, 2, fr.NumRecords())
+
+			var rows int64
+			for i := 0; i < fr.NumRecords(); i++ {
+				rec, err := fr.Record(i)
+				require.NoError(t, err)
+				rows += rec.NumRows()
+			}
+			assert.Equal(t, n, rows)
+		})
+	}
+}
+
+func TestExportFeatherEmpty(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc)
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	n, err := ExportFeather(context.Background(), reader, &buf, FeatherOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	fr, err := ipc.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer fr.Close()
+	assert.True(t, reader.Schema().Equal(fr.Schema()))
+	assert.Zero(t, fr.NumRecords())
+}
+
+func TestExportFeatherCanceled(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc, []int64{1})
+	defer reader.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	_, err := ExportFeather(ctx, reader, &buf, FeatherOptions{})
+	assert.Error(t, err)
+}
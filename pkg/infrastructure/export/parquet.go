@@ -0,0 +1,191 @@
+// Package export writes Arrow record streams to file formats.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// ParquetOptions configures Parquet export.
+type ParquetOptions struct {
+	// Allocator used while encoding; defaults to the Go allocator.
+	Allocator memory.Allocator
+	// Compression codec for all columns; defaults to uncompressed.
+	Compression compress.Compression
+	// UseInt96Timestamps writes top-level timestamp columns as legacy INT96
+	// values for readers that predate the Parquet TIMESTAMP logical type.
+	// Values are widened to nanoseconds while writing, so they must fall
+	// within the years 1677-2262; values outside that range fail the export.
+	UseInt96Timestamps bool
+}
+
+// ExportParquet writes every record from reader to w as a single Parquet
+// file and returns the number of rows written.
+func ExportParquet(ctx context.Context, reader RecordSource, w io.Writer, opts ParquetOptions) (int64, error) {
+	pw, err := newParquetWriter(w, reader.Schema(), opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			pw.close()
+			return rows, errors.Wrap(err, errors.CodeCanceled, "parquet export canceled")
+		}
+
+		rec, err := reader.TakeRecord()
+		if err != nil {
+			pw.close()
+			return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+		}
+		n, err := rec.NumRows(), pw.write(rec)
+		rec.Release()
+		if err != nil {
+			pw.close()
+			return rows, err
+		}
+		rows += n
+	}
+	if err := reader.Err(); err != nil {
+		pw.close()
+		return rows, errors.Wrap(err, errors.CodeInternal, "failed to read records")
+	}
+
+	if err := pw.close(); err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
+
+// parquetWriter writes records to a single Parquet file, applying the
+// conversions requested in ParquetOptions.
+type parquetWriter struct {
+	fw     *pqarrow.FileWriter
+	alloc  memory.Allocator
+	schema *arrow.Schema
+	int96  bool
+}
+
+// newParquetWriter starts a Parquet file with the given schema on w.
+func newParquetWriter(w io.Writer, schema *arrow.Schema, opts ParquetOptions) (*parquetWriter, error) {
+	alloc := opts.Allocator
+	if alloc == nil {
+		alloc = memory.NewGoAllocator()
+	}
+
+	if opts.UseInt96Timestamps {
+		schema = nanoTimestampSchema(schema)
+	}
+
+	props := parquet.NewWriterProperties(
+		parquet.WithAllocator(alloc),
+		parquet.WithCompression(opts.Compression),
+	)
+	arrowProps := pqarrow.NewArrowWriterProperties(
+		pqarrow.WithAllocator(alloc),
+		pqarrow.WithStoreSchema(),
+		pqarrow.WithDeprecatedInt96Timestamps(opts.UseInt96Timestamps),
+	)
+
+	fw, err := pqarrow.NewFileWriter(schema, w, props, arrowProps)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to create parquet writer")
+	}
+	return &parquetWriter{fw: fw, alloc: alloc, schema: schema, int96: opts.UseInt96Timestamps}, nil
+}
+
+// write writes rec as a row group.
+func (pw *parquetWriter) write(rec arrow.Record) error {
+	var err error
+	if pw.int96 {
+		converted, cerr := toNanoTimestamps(pw.alloc, pw.schema, rec)
+		if cerr != nil {
+			return cerr
+		}
+		err = pw.fw.Write(converted)
+		converted.Release()
+	} else {
+		err = pw.fw.Write(rec)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "failed to write parquet row group")
+	}
+	return nil
+}
+
+// close writes the file footer.
+func (pw *parquetWriter) close() error {
+	if err := pw.fw.Close(); err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "failed to close parquet writer")
+	}
+	return nil
+}
+
+// nanoTimestampSchema returns schema with top-level timestamp fields widened
+// to nanoseconds, which is the only unit the writer emits as INT96.
+func nanoTimestampSchema(schema *arrow.Schema) *arrow.Schema {
+	fields := make([]arrow.Field, schema.NumFields())
+	for i, f := range schema.Fields() {
+		if ts, ok := f.Type.(*arrow.TimestampType); ok {
+			f.Type = &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: ts.TimeZone}
+		}
+		fields[i] = f
+	}
+	md := schema.Metadata()
+	return arrow.NewSchema(fields, &md)
+}
+
+// toNanoTimestamps returns a copy of rec whose timestamp columns are converted
+// to nanoseconds. Values outside the nanosecond range, roughly the years 1677
+// to 2262, are reported as errors. The caller must release the returned
+// record.
+func toNanoTimestamps(mem memory.Allocator, schema *arrow.Schema, rec arrow.Record) (arrow.Record, error) {
+	cols := make([]arrow.Array, 0, rec.NumCols())
+	defer func() {
+		for _, c := range cols {
+			c.Release()
+		}
+	}()
+	for i, col := range rec.Columns() {
+		ts, ok := col.(*array.Timestamp)
+		if !ok {
+			col.Retain()
+			cols = append(cols, col)
+			continue
+		}
+
+		// Multiplier is the number of nanoseconds per unit.
+		factor := arrow.Timestamp(ts.DataType().(*arrow.TimestampType).Unit.Multiplier())
+		b := array.NewTimestampBuilder(mem, schema.Field(i).Type.(*arrow.TimestampType))
+		b.Reserve(ts.Len())
+		for j := 0; j < ts.Len(); j++ {
+			if ts.IsNull(j) {
+				b.AppendNull()
+				continue
+			}
+			v := ts.Value(j)
+			if v > math.MaxInt64/factor || v < math.MinInt64/factor {
+				b.Release()
+				return nil, errors.New(errors.CodeInvalidRequest,
+					fmt.Sprintf("timestamp %d in column %q is out of range for INT96", v, schema.Field(i).Name))
+			}
+			b.Append(v * factor)
+		}
+		cols = append(cols, b.NewArray())
+		b.Release()
+	}
+
+	return array.NewRecord(schema, cols, rec.NumRows()), nil
+}
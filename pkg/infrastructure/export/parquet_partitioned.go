@@ -0,0 +1,131 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/TFMV/porter/pkg/errors"
+)
+
+// ExportParquetPartitioned writes the records from reader to a series of
+// Parquet files in dir named part-0000.parquet, part-0001.parquet, and so
+// on. A new file is started once the current one has grown to at least
+// maxBytesPerFile; since record batches are never split, files can exceed
+// the limit by up to one row group. It returns the paths of the files
+// written, which include at least one file even for an empty stream.
+func ExportParquetPartitioned(ctx context.Context, reader RecordSource, dir string, maxBytesPerFile int64, opts ParquetOptions) ([]string, error) {
+	if maxBytesPerFile <= 0 {
+		return nil, errors.New(errors.CodeInvalidRequest, "maxBytesPerFile must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to create export directory")
+	}
+
+	var (
+		files []string
+		part  *parquetPart
+	)
+	// fail closes the open part and returns err with the files so far.
+	fail := func(err error) ([]string, error) {
+		if part != nil {
+			part.close()
+		}
+		return files, err
+	}
+
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			return fail(errors.Wrap(err, errors.CodeCanceled, "parquet export canceled"))
+		}
+
+		if part == nil {
+			var err error
+			if part, err = createParquetPart(dir, len(files), reader, opts); err != nil {
+				return fail(err)
+			}
+			files = append(files, part.path)
+		}
+		rec, err := reader.TakeRecord()
+		if err != nil {
+			return fail(errors.Wrap(err, errors.CodeInternal, "failed to read records"))
+		}
+		err = part.pw.write(rec)
+		rec.Release()
+		if err != nil {
+			return fail(err)
+		}
+		if part.size.n >= maxBytesPerFile {
+			if err := part.close(); err != nil {
+				part = nil
+				return fail(err)
+			}
+			part = nil
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return fail(errors.Wrap(err, errors.CodeInternal, "failed to read records"))
+	}
+
+	// Always leave a file behind so that the schema is exported.
+	if len(files) == 0 {
+		var err error
+		if part, err = createParquetPart(dir, 0, reader, opts); err != nil {
+			return fail(err)
+		}
+		files = append(files, part.path)
+	}
+	if part != nil {
+		if err := part.close(); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// parquetPart is one file of a partitioned export.
+type parquetPart struct {
+	path string
+	file *os.File
+	size *countingWriter
+	pw   *parquetWriter
+}
+
+// createParquetPart creates the index'th part file in dir.
+func createParquetPart(dir string, index int, reader RecordSource, opts ParquetOptions) (*parquetPart, error) {
+	path := filepath.Join(dir, fmt.Sprintf("part-%04d.parquet", index))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "failed to create parquet part")
+	}
+	size := &countingWriter{w: f}
+	pw, err := newParquetWriter(size, reader.Schema(), opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &parquetPart{path: path, file: f, size: size, pw: pw}, nil
+}
+
+// close finishes the Parquet file and closes it.
+func (p *parquetPart) close() error {
+	err := p.pw.close()
+	if cerr := p.file.Close(); cerr != nil && err == nil {
+		err = errors.Wrap(cerr, errors.CodeInternal, "failed to close parquet part")
+	}
+	return err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
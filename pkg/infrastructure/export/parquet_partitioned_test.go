@@ -0,0 +1,95 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/infrastructure/converter"
+)
+
+// readParquetIDs reads the id column of a Parquet file.
+func readParquetIDs(t *testing.T, path string) []int64 {
+	t.Helper()
+
+	pf, err := file.OpenParquetFile(path, false)
+	require.NoError(t, err)
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	require.NoError(t, err)
+	tbl, err := fr.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer tbl.Release()
+
+	var ids []int64
+	for _, chunk := range tbl.Column(0).Data().Chunks() {
+		ids = append(ids, chunk.(*array.Int64).Int64Values()...)
+	}
+	return ids
+}
+
+func TestExportParquetPartitioned(t *testing.T) {
+	const batches, batchLen = 10, 1000
+	values := make([][]int64, batches)
+	var want []int64
+	for i := range values {
+		values[i] = make([]int64, batchLen)
+		for j := range values[i] {
+			values[i][j] = int64(i*batchLen + j)
+		}
+		want = append(want, values[i]...)
+	}
+
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc, values...)
+	defer reader.Release()
+
+	const maxBytes = 12 << 10
+	dir := filepath.Join(t.TempDir(), "out")
+	files, err := ExportParquetPartitioned(context.Background(), reader, dir, maxBytes, ParquetOptions{})
+	require.NoError(t, err)
+	require.Greater(t, len(files), 1)
+
+	var got []int64
+	for i, path := range files {
+		assert.Equal(t, filepath.Join(dir, fmt.Sprintf("part-%04d.parquet", i)), path)
+		if i < len(files)-1 {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, info.Size(), int64(maxBytes))
+		}
+		got = append(got, readParquetIDs(t, path)...)
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestExportParquetPartitionedEmpty(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc)
+	defer reader.Release()
+
+	files, err := ExportParquetPartitioned(context.Background(), reader, t.TempDir(), 1<<20, ParquetOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Empty(t, readParquetIDs(t, files[0]))
+}
+
+func TestExportParquetPartitionedInvalid(t *testing.T) {
+	reader := converter.NewReplayReader(arrow.NewSchema(nil, nil), nil)
+	defer reader.Release()
+
+	_, err := ExportParquetPartitioned(context.Background(), reader, t.TempDir(), 0, ParquetOptions{})
+	assert.Error(t, err)
+}
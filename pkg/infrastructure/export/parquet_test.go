@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/porter/pkg/infrastructure/converter"
+)
+
+// newTimestampReader builds a reader over a single timestamp column,
+// allocating the batch from alloc.
+func newTimestampReader(t *testing.T, alloc memory.Allocator, values []time.Time) *converter.BatchReader {
+	t.Helper()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(alloc, schema)
+	defer b.Release()
+	for _, v := range values {
+		b.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(v.UnixMicro()))
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	return converter.NewReplayReader(schema, []arrow.Record{rec})
+}
+
+func TestExportParquet(t *testing.T) {
+	values := []time.Time{
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, 12, 31, 23, 59, 59, 123456000, time.UTC),
+	}
+
+	tests := []struct {
+		name         string
+		useInt96     bool
+		wantPhysical parquet.Type
+	}{
+		{name: "int64 timestamps", useInt96: false, wantPhysical: parquet.Types.Int64},
+		{name: "int96 timestamps", useInt96: true, wantPhysical: parquet.Types.Int96},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer alloc.AssertSize(t, 0)
+			reader := newTimestampReader(t, alloc, values)
+			defer reader.Release()
+
+			var buf bytes.Buffer
+			n, err := ExportParquet(context.Background(), reader, &buf, ParquetOptions{UseInt96Timestamps: tt.useInt96})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(values)), n)
+
+			pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			defer pf.Close()
+			assert.Equal(t, tt.wantPhysical, pf.MetaData().Schema.Column(0).PhysicalType())
+
+			fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+			require.NoError(t, err)
+			tbl, err := fr.ReadTable(context.Background())
+			require.NoError(t, err)
+			defer tbl.Release()
+
+			col := tbl.Column(0).Data().Chunk(0).(*array.Timestamp)
+			unit := col.DataType().(*arrow.TimestampType).Unit
+			for i, want := range values {
+				assert.True(t, want.Equal(col.Value(i).ToTime(unit)))
+			}
+		})
+	}
+}
+
+func TestExportParquetEmpty(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newTimestampReader(t, alloc, nil)
+	defer reader.Release()
+
+	var buf bytes.Buffer
+	n, err := ExportParquet(context.Background(), reader, &buf, ParquetOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer pf.Close()
+	assert.Zero(t, pf.NumRows())
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	require.NoError(t, err)
+	schema, err := fr.Schema()
+	require.NoError(t, err)
+	require.Equal(t, 1, schema.NumFields())
+	assert.Equal(t, "ts", schema.Field(0).Name)
+	assert.True(t, arrow.TypeEqual(reader.Schema().Field(0).Type, schema.Field(0).Type))
+}
+
+func TestExportParquetInt96OutOfRange(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	// Representable in microseconds, but not in INT96 nanoseconds
+	reader := newTimestampReader(t, alloc, []time.Time{
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	defer reader.Release()
+
+	_, err := ExportParquet(context.Background(), reader, &bytes.Buffer{}, ParquetOptions{Allocator: alloc, UseInt96Timestamps: true})
+	assert.ErrorContains(t, err, "out of range")
+}
+
+func TestExportParquetCanceled(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer alloc.AssertSize(t, 0)
+	reader := newIntReader(t, alloc, []int64{1})
+	defer reader.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ExportParquet(ctx, reader, &bytes.Buffer{}, ParquetOptions{Allocator: alloc})
+	assert.Error(t, err)
+}
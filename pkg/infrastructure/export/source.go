@@ -0,0 +1,19 @@
+package export
+
+import "github.com/apache/arrow-go/v18/arrow"
+
+// RecordSource is a stream of record batches whose ownership passes to the
+// exporter. Unlike array.RecordReader, which only lends its current record,
+// each record returned by TakeRecord belongs to the caller, and the
+// exporters release it once written. *converter.BatchReader implements it.
+type RecordSource interface {
+	// Schema returns the schema shared by every record.
+	Schema() *arrow.Schema
+	// Next advances to the next record, reporting false at the end of the
+	// stream or on error.
+	Next() bool
+	// TakeRecord transfers ownership of the current record to the caller.
+	TakeRecord() (arrow.Record, error)
+	// Err returns the error that stopped Next, if any.
+	Err() error
+}
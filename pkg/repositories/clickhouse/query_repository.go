@@ -59,7 +59,7 @@ func (r *queryRepository) ExecuteQuery(
 			return nil, errors.Wrap(err, errors.CodeQueryFailed, "query")
 		}
 
-		reader, err := converter.NewBatchReader(r.alloc, rows, r.log)
+		reader, err := converter.NewBatchReaderContext(ctx, r.alloc, rows, r.log)
 		if err != nil {
 			return nil, errors.Wrap(err, errors.CodeInternal, "batch reader")
 		}
@@ -193,7 +193,6 @@ func streamQueryRecords(ctx context.Context, br *converter.BatchReader, out chan
 		if rec == nil {
 			continue
 		}
-		rec.Retain()
 		rows += rec.NumRows()
 
 		select {
@@ -205,7 +204,7 @@ func streamQueryRecords(ctx context.Context, br *converter.BatchReader, out chan
 		}
 	}
 	if err := br.Err(); err != nil {
-		log.Error().Err(err).Msg("batch reader")
+		errors.LogFields(log.Error(), err).Msg("batch reader")
 	}
 	log.Debug().Int64("rows", rows).Msg("clickhouse stream complete")
 }
@@ -91,7 +91,7 @@ func (r *preparedStatementRepository) ExecuteQuery(ctx context.Context, h string
 		return nil, err
 	}
 
-	reader, err := converter.NewBatchReader(r.alloc, rows, r.log)
+	reader, err := converter.NewBatchReaderContext(ctx, r.alloc, rows, r.log)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.CodeInternal, "new batch reader")
 	}
@@ -202,7 +202,6 @@ func streamRecords(ctx context.Context, br *converter.BatchReader, out chan arro
 		if rec == nil {
 			continue
 		}
-		rec.Retain()
 		total += rec.NumRows()
 
 		select {
@@ -214,7 +213,7 @@ func streamRecords(ctx context.Context, br *converter.BatchReader, out chan arro
 		}
 	}
 	if err := br.Err(); err != nil {
-		log.Error().Err(err).Msg("batch reader")
+		errors.LogFields(log.Error(), err).Msg("batch reader")
 	}
 	log.Debug().Int64("rows", total).Msg("stream complete")
 }